@@ -26,6 +26,8 @@ func AddSubCommands(cmdName, version, commit string, rootCmd *cobra.Command) *co
 	rootCmd.AddCommand(Top())
 	rootCmd.AddCommand(GetServices())
 	rootCmd.AddCommand(GetConfig())
+	rootCmd.AddCommand(MTLS())
+	rootCmd.AddCommand(Federation())
 	rootCmd.AddCommand(Inject())
 	rootCmd.AddCommand(Deploy())
 	rootCmd.AddCommand(Upgrade(version))
@@ -252,6 +252,13 @@ func Deploy() *cobra.Command {
 		`default access control mode for service-to-service communication
 		Valid values: `+formatValues(mesh.AccessControlModes),
 	)
+	cmd.Flags().StringVar(
+		&values.EgressMode,
+		"egress-mode",
+		defaultValues.EgressMode,
+		`egress mode for traffic to destinations outside of the mesh
+		Valid values: `+formatValues(mesh.EgressModes),
+	)
 	cmd.Flags().StringVar(
 		&values.MTLS.Mode,
 		"mtls-mode",
@@ -368,6 +375,20 @@ func Deploy() *cobra.Command {
 		`NGINX log format
 		Valid values: `+formatValues(mesh.NGINXLogFormats),
 	)
+	cmd.Flags().StringVar(
+		&values.Locality.Mode,
+		"locality-mode",
+		defaultValues.Locality.Mode,
+		`topology level that traffic prefers to stay within
+		Valid values: `+formatValues(mesh.LocalityModes),
+	)
+	cmd.Flags().IntVar(
+		&values.Locality.FailoverThreshold,
+		"locality-failover-threshold",
+		defaultValues.Locality.FailoverThreshold,
+		`percentage of healthy upstream servers in the local zone or region below which
+		traffic is spread across all localities. Integer between 0 and 100`,
+	)
 	cmd.Flags().StringVar(
 		&values.ClientMaxBodySize,
 		"client-max-body-size",
@@ -66,6 +66,8 @@ var _ = Describe("Deploy", func() {
 		// verify that some values are set
 		Expect(values.Environment).To(Equal(string(mesh.Kubernetes)))
 		Expect(values.NGINXLBMethod).To(Equal(mesh.LeastTime))
+		Expect(values.Locality.Mode).To(Equal(mesh.LocalityModeNone))
+		Expect(values.EgressMode).To(Equal(mesh.EgressModeAllowAll))
 		Expect(values.MTLS.Mode).To(Equal(mesh.MtlsModePermissive))
 		Expect(values.MTLS.CAKeyType).To(Equal("ec-p256"))
 
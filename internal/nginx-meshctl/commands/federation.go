@@ -0,0 +1,414 @@
+// Package commands contains all of the cli commands
+package commands // import "github.com/nginxinc/nginx-service-mesh/internal/nginx-meshctl/commands"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
+	meshv1alpha2 "github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh/v1alpha2"
+	"github.com/nginxinc/nginx-service-mesh/pkg/k8s"
+)
+
+const longFederationJoin = `Federate this NGINX Service Mesh with the NGINX Service Mesh of another cluster.
+- Reads the trust domain and trust bundle of both meshes.
+- Creates a MeshFederation in each mesh that describes the other mesh.
+- Registers the other mesh with the SPIRE server of each mesh, bootstrapped with the current trust bundle of the other mesh.
+  The SPIRE server then keeps the trust bundle up to date from the bundle endpoint of the other mesh.
+- The trust domains of the meshes must be different.
+- The bundle endpoints, port 8443 of the spire-server Service, must be reachable from the other cluster.
+- Workloads receive the trust bundle of the other mesh only if their pods have the spiffe.io/federatesWith annotation
+  with its trust domain. 'nginx-meshctl inject' adds the annotation for all MeshFederations; re-inject and restart
+  the workloads after joining a federation.
+`
+
+const longFederationStatus = `Display the meshes that NGINX Service Mesh federates with.
+- Outputs the trust domain and bundle endpoint of each MeshFederation.
+- Outputs the number of certificates in the trust bundle that the federation was bootstrapped with, and when the first one expires.
+`
+
+const (
+	// spireServerPath is the path of the SPIFFE ID of a SPIRE server in its trust domain.
+	spireServerPath = "/spire/server"
+	// spireServerContainer is the name of the SPIRE server container.
+	spireServerContainer = "spire-server"
+	// spireServerBin is the path of the SPIRE server CLI in the SPIRE server container.
+	spireServerBin = "/opt/spire/bin/spire-server"
+	// spireServerSocket is the path of the API socket of the SPIRE server.
+	spireServerSocket = "/run/spire/sockets/spire-registration.sock"
+)
+
+// spireServerExec runs a command of the SPIRE server CLI in the SPIRE server of a mesh.
+// The stdin is passed to the command if not nil.
+type spireServerExec func(ctx context.Context, k8sClient k8s.Client, stdin io.Reader, args ...string) error
+
+var (
+	errSameTrustDomain = errors.New("meshes must have different trust domains to federate")
+	errNoSpireServer   = errors.New("no running SPIRE server pod found")
+)
+
+// federationPeer is a mesh that takes part in a federation.
+type federationPeer struct {
+	client         k8s.Client
+	trustDomain    string
+	trustBundle    string
+	bundleEndpoint string
+}
+
+// Federation groups the federation commands.
+func Federation() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "federation",
+		Short: "Manage the federation of NGINX Service Mesh with other meshes",
+		Long:  `Manage the federation of NGINX Service Mesh with other meshes.`,
+	}
+	cmd.AddCommand(FederationJoin())
+	cmd.AddCommand(FederationStatus())
+
+	return cmd
+}
+
+// FederationJoin federates the mesh with the mesh of another cluster.
+func FederationJoin() *cobra.Command {
+	var remoteKubeconfig, remoteNamespace, bundleEndpoint, remoteBundleEndpoint string
+	cmd := &cobra.Command{
+		Use:   "join",
+		Short: "Federate with the NGINX Service Mesh of another cluster",
+		Long:  longFederationJoin,
+	}
+
+	cmd.PersistentPreRunE = defaultPreRunFunc()
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		remoteClient, err := k8s.NewK8SClient(remoteKubeconfig, remoteNamespace)
+		if err != nil {
+			return fmt.Errorf("unable to connect to the remote Kubernetes cluster, please validate the remote kubeconfig: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), meshTimeout)
+		defer cancel()
+
+		local, err := getFederationPeer(ctx, initK8sClient, bundleEndpoint)
+		if err != nil {
+			return fmt.Errorf("unable to read the local mesh: %w", err)
+		}
+		remote, err := getFederationPeer(ctx, remoteClient, remoteBundleEndpoint)
+		if err != nil {
+			return fmt.Errorf("unable to read the remote mesh: %w", err)
+		}
+		if local.trustDomain == remote.trustDomain {
+			return fmt.Errorf("%w: both meshes use trust domain '%s'", errSameTrustDomain, local.trustDomain)
+		}
+
+		if err := joinFederation(ctx, local, remote, execSpireServer); err != nil {
+			return err
+		}
+		fmt.Printf("Federated trust domain '%s' with trust domain '%s'.\n", local.trustDomain, remote.trustDomain)
+		fmt.Printf("Re-inject and restart the workloads of both meshes, or add the annotation '%s' to their pods, "+
+			"so that they receive the trust bundle of the other mesh.\n", mesh.FederatesWithAnnotation)
+
+		return nil
+	}
+	cmd.Flags().StringVar(
+		&remoteKubeconfig,
+		"remote-kubeconfig",
+		"",
+		"path to the kubectl config file of the remote cluster",
+	)
+	cmd.Flags().StringVar(
+		&remoteNamespace,
+		"remote-namespace",
+		meshNamespace,
+		"namespace where NGINX Service Mesh is installed in the remote cluster",
+	)
+	cmd.Flags().StringVar(
+		&bundleEndpoint,
+		"bundle-endpoint",
+		"",
+		"https URL where the remote cluster reaches the bundle endpoint of this mesh",
+	)
+	cmd.Flags().StringVar(
+		&remoteBundleEndpoint,
+		"remote-bundle-endpoint",
+		"",
+		"https URL where this cluster reaches the bundle endpoint of the remote mesh",
+	)
+	for _, flag := range []string{"remote-kubeconfig", "bundle-endpoint", "remote-bundle-endpoint"} {
+		if err := cmd.MarkFlagRequired(flag); err != nil {
+			fmt.Println("error marking flag as required: ", err)
+		}
+	}
+
+	return cmd
+}
+
+// FederationStatus prints the meshes that the mesh federates with.
+func FederationStatus() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Display the meshes that NGINX Service Mesh federates with",
+		Long:  longFederationStatus,
+	}
+
+	cmd.PersistentPreRunE = defaultPreRunFunc()
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), meshTimeout)
+		defer cancel()
+
+		federations, err := getMeshFederations(ctx, initK8sClient.DynamicClientSet(), initK8sClient.Namespace())
+		if err != nil {
+			return err
+		}
+
+		tabWriter := TabWriterWithOpts()
+		writeFederationStatus(tabWriter, federations)
+
+		return tabWriter.Flush()
+	}
+
+	return cmd
+}
+
+// getFederationPeer reads the trust domain and trust bundle of a mesh.
+func getFederationPeer(ctx context.Context, k8sClient k8s.Client, bundleEndpoint string) (*federationPeer, error) {
+	meshConfig, err := mesh.GetMeshConfig(ctx, k8sClient.Client(), k8sClient.Namespace())
+	if err != nil {
+		return nil, fmt.Errorf("unable to get mesh config: %w", err)
+	}
+	cm, err := k8sClient.ClientSet().CoreV1().ConfigMaps(k8sClient.Namespace()).Get(ctx, mesh.SpireBundleConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get trust bundle: %w", err)
+	}
+
+	return &federationPeer{
+		client:         k8sClient,
+		trustDomain:    meshConfig.Mtls.TrustDomain,
+		trustBundle:    cm.Data[mesh.SpireBundleKey],
+		bundleEndpoint: bundleEndpoint,
+	}, nil
+}
+
+// joinFederation creates a MeshFederation in each mesh that describes the other mesh,
+// and registers the federation with the SPIRE server of each mesh.
+func joinFederation(ctx context.Context, local, remote *federationPeer, exec spireServerExec) error {
+	for _, peers := range [][2]*federationPeer{{local, remote}, {remote, local}} {
+		self, other := peers[0], peers[1]
+		federation, err := newMeshFederation(other)
+		if err != nil {
+			return err
+		}
+		if err := applyMeshFederation(ctx, self.client.DynamicClientSet(), self.client.Namespace(), federation); err != nil {
+			return fmt.Errorf("unable to federate trust domain '%s' with trust domain '%s': %w", self.trustDomain, other.trustDomain, err)
+		}
+		if err := registerFederation(ctx, self.client, federation, exec); err != nil {
+			return fmt.Errorf("unable to federate trust domain '%s' with trust domain '%s': %w", self.trustDomain, other.trustDomain, err)
+		}
+	}
+
+	return nil
+}
+
+// registerFederation creates the federation relationship of a MeshFederation in the SPIRE server, or updates it
+// if it exists. The SPIRE server is bootstrapped with the trust bundle of the MeshFederation, and refreshes it
+// from the bundle endpoint from then on.
+func registerFederation(ctx context.Context, k8sClient k8s.Client, federation *meshv1alpha2.MeshFederation, exec spireServerExec) error {
+	spec := federation.Spec
+	action := "update"
+	if err := exec(ctx, k8sClient, nil, "federation", "show", "-socketPath", spireServerSocket, "-trustDomain", spec.TrustDomain); err != nil {
+		action = "create"
+	}
+
+	args := []string{
+		"federation", action,
+		"-socketPath", spireServerSocket,
+		"-trustDomain", spec.TrustDomain,
+		"-bundleEndpointURL", spec.BundleEndpoint.URL,
+		"-bundleEndpointProfile", spec.BundleEndpoint.Profile,
+	}
+	if spec.BundleEndpoint.Profile == meshv1alpha2.BundleEndpointProfileHTTPSSPIFFE {
+		args = append(args, "-endpointSpiffeID", spec.BundleEndpoint.SPIFFEID)
+	}
+	var stdin io.Reader
+	if spec.TrustBundle != "" {
+		args = append(args, "-trustDomainBundlePath", "/dev/stdin", "-trustDomainBundleFormat", "pem")
+		stdin = strings.NewReader(spec.TrustBundle)
+	}
+	if err := exec(ctx, k8sClient, stdin, args...); err != nil {
+		return fmt.Errorf("error registering federation with the SPIRE server: %w", err)
+	}
+
+	return nil
+}
+
+// execSpireServer runs a command of the SPIRE server CLI in the SPIRE server pod of a mesh.
+func execSpireServer(ctx context.Context, k8sClient k8s.Client, stdin io.Reader, args ...string) error {
+	pods, err := k8sClient.ClientSet().CoreV1().Pods(k8sClient.Namespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/name=spire-server",
+		FieldSelector: "status.phase=Running",
+	})
+	if err != nil {
+		return fmt.Errorf("unable to get the SPIRE server pod: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return errNoSpireServer
+	}
+
+	req := k8sClient.ClientSet().CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(k8sClient.Namespace()).
+		Name(pods.Items[0].Name).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: spireServerContainer,
+			Command:   append([]string{spireServerBin}, args...),
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(k8sClient.Config(), "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("unable to run the SPIRE server CLI: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: io.Discard,
+		Stderr: &stderr,
+	}); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// newMeshFederation builds the MeshFederation that describes a mesh.
+// The MeshFederation is named after the trust domain of the mesh.
+func newMeshFederation(peer *federationPeer) (*meshv1alpha2.MeshFederation, error) {
+	td, err := spiffeid.TrustDomainFromString(peer.trustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust domain '%s': %w", peer.trustDomain, err)
+	}
+	if errs := validation.IsDNS1123Subdomain(td.String()); len(errs) > 0 {
+		return nil, fmt.Errorf("trust domain '%s' is not a valid resource name: %s", td, strings.Join(errs, ", "))
+	}
+	serverID, err := spiffeid.FromPath(td, spireServerPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIRE server ID: %w", err)
+	}
+
+	federation := &meshv1alpha2.MeshFederation{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: meshv1alpha2.SchemeGroupVersion.String(),
+			Kind:       "MeshFederation",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: td.String()},
+		Spec: meshv1alpha2.MeshFederationSpec{
+			TrustDomain: td.String(),
+			BundleEndpoint: meshv1alpha2.BundleEndpoint{
+				URL:      peer.bundleEndpoint,
+				Profile:  meshv1alpha2.BundleEndpointProfileHTTPSSPIFFE,
+				SPIFFEID: serverID.String(),
+			},
+			TrustBundle: peer.trustBundle,
+		},
+	}
+	if err := federation.Spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid MeshFederation for trust domain '%s': %w", td, err)
+	}
+
+	return federation, nil
+}
+
+// applyMeshFederation creates a MeshFederation, or updates its spec if it already exists.
+func applyMeshFederation(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	namespace string,
+	federation *meshv1alpha2.MeshFederation,
+) error {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(federation)
+	if err != nil {
+		return fmt.Errorf("error converting MeshFederation '%s': %w", federation.Name, err)
+	}
+	client := dynamicClient.Resource(meshv1alpha2.SchemeGroupVersion.WithResource("meshfederations")).Namespace(namespace)
+	_, err = client.Create(ctx, &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{})
+	if err == nil || !k8sErrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing, err := client.Get(ctx, federation.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting MeshFederation '%s': %w", federation.Name, err)
+	}
+	existing.Object["spec"] = obj["spec"]
+	if _, err := client.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating MeshFederation '%s': %w", federation.Name, err)
+	}
+
+	return nil
+}
+
+// getMeshFederations lists the MeshFederations in a namespace.
+func getMeshFederations(ctx context.Context, dynamicClient dynamic.Interface, namespace string) ([]meshv1alpha2.MeshFederation, error) {
+	gvr := meshv1alpha2.SchemeGroupVersion.WithResource("meshfederations")
+	list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting list of MeshFederations: %w", err)
+	}
+
+	federations := make([]meshv1alpha2.MeshFederation, 0, len(list.Items))
+	for _, item := range list.Items {
+		var federation meshv1alpha2.MeshFederation
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &federation); err != nil {
+			return nil, fmt.Errorf("error converting MeshFederation '%s': %w", item.GetName(), err)
+		}
+		federations = append(federations, federation)
+	}
+
+	return federations, nil
+}
+
+// writeFederationStatus writes the trust domain, bundle endpoint, and trust bundle of each MeshFederation.
+func writeFederationStatus(w io.Writer, federations []meshv1alpha2.MeshFederation) {
+	fmt.Fprintln(w, "Trust Domain\tBundle Endpoint\tProfile\tCertificates\tExpires")
+	for _, federation := range federations {
+		spec := federation.Spec
+		certs, expires := 0, "-"
+		if td, err := spiffeid.TrustDomainFromString(spec.TrustDomain); err == nil && spec.TrustBundle != "" {
+			if bundle, err := x509bundle.Parse(td, []byte(spec.TrustBundle)); err == nil {
+				authorities := bundle.X509Authorities()
+				certs = len(authorities)
+				var first time.Time
+				for _, cert := range authorities {
+					if first.IsZero() || cert.NotAfter.Before(first) {
+						first = cert.NotAfter
+					}
+				}
+				if !first.IsZero() {
+					expires = first.UTC().Format(time.RFC3339)
+				}
+			} else {
+				expires = "invalid bundle"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", spec.TrustDomain, spec.BundleEndpoint.URL, spec.BundleEndpoint.Profile, certs, expires)
+	}
+}
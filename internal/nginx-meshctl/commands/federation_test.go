@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	meshv1alpha2 "github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh/v1alpha2"
+	"github.com/nginxinc/nginx-service-mesh/pkg/k8s"
+	"github.com/nginxinc/nginx-service-mesh/pkg/k8s/fake"
+)
+
+// newTestCAPEM returns a PEM encoded self-signed CA certificate that expires at notAfter.
+func newTestCAPEM(notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             notAfter.Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+var _ = Describe("Federation", func() {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	peer := &federationPeer{
+		trustDomain:    "east.example.com",
+		trustBundle:    newTestCAPEM(expires),
+		bundleEndpoint: "https://spire.east.example.com:8443",
+	}
+
+	It("builds the MeshFederation of a mesh", func() {
+		federation, err := newMeshFederation(peer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(federation.Name).To(Equal("east.example.com"))
+		Expect(federation.Spec).To(Equal(meshv1alpha2.MeshFederationSpec{
+			TrustDomain: "east.example.com",
+			BundleEndpoint: meshv1alpha2.BundleEndpoint{
+				URL:      "https://spire.east.example.com:8443",
+				Profile:  meshv1alpha2.BundleEndpointProfileHTTPSSPIFFE,
+				SPIFFEID: "spiffe://east.example.com/spire/server",
+			},
+			TrustBundle: peer.trustBundle,
+		}))
+	})
+
+	It("returns an error if the mesh cannot be federated", func() {
+		invalid := []*federationPeer{
+			{trustDomain: "east_example", trustBundle: peer.trustBundle, bundleEndpoint: peer.bundleEndpoint},
+			{trustDomain: peer.trustDomain, trustBundle: peer.trustBundle, bundleEndpoint: "http://spire.east.example.com"},
+			{trustDomain: peer.trustDomain, trustBundle: "not a bundle", bundleEndpoint: peer.bundleEndpoint},
+		}
+		for _, p := range invalid {
+			_, err := newMeshFederation(p)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("creates or updates the MeshFederation", func() {
+		dynamicClient := fake.NewFakeK8s("nginx-mesh", true).DynamicClientSet()
+		federation, err := newMeshFederation(peer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applyMeshFederation(context.TODO(), dynamicClient, "nginx-mesh", federation)).To(Succeed())
+
+		federation.Spec.BundleEndpoint.URL = "https://10.0.0.1:8443"
+		Expect(applyMeshFederation(context.TODO(), dynamicClient, "nginx-mesh", federation)).To(Succeed())
+
+		federations, err := getMeshFederations(context.TODO(), dynamicClient, "nginx-mesh")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(federations).To(HaveLen(1))
+		Expect(federations[0].Spec.BundleEndpoint.URL).To(Equal("https://10.0.0.1:8443"))
+	})
+
+	Context("registers the federation with the SPIRE server", func() {
+		type call struct {
+			stdin string
+			args  []string
+		}
+		var calls []call
+
+		// fakeExec records the commands, and fails the show command unless the federation exists.
+		fakeExec := func(exists bool) spireServerExec {
+			return func(_ context.Context, _ k8s.Client, stdin io.Reader, args ...string) error {
+				c := call{args: args}
+				if stdin != nil {
+					b, err := io.ReadAll(stdin)
+					Expect(err).ToNot(HaveOccurred())
+					c.stdin = string(b)
+				}
+				calls = append(calls, c)
+				if args[1] == "show" && !exists {
+					return errors.New("not found")
+				}
+
+				return nil
+			}
+		}
+
+		BeforeEach(func() {
+			calls = nil
+		})
+
+		It("creates the federation with the bootstrap trust bundle", func() {
+			federation, err := newMeshFederation(peer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(registerFederation(context.TODO(), nil, federation, fakeExec(false))).To(Succeed())
+
+			Expect(calls).To(HaveLen(2))
+			Expect(calls[1].args).To(Equal([]string{
+				"federation", "create",
+				"-socketPath", spireServerSocket,
+				"-trustDomain", "east.example.com",
+				"-bundleEndpointURL", "https://spire.east.example.com:8443",
+				"-bundleEndpointProfile", "https_spiffe",
+				"-endpointSpiffeID", "spiffe://east.example.com/spire/server",
+				"-trustDomainBundlePath", "/dev/stdin",
+				"-trustDomainBundleFormat", "pem",
+			}))
+			Expect(calls[1].stdin).To(Equal(peer.trustBundle))
+		})
+
+		It("updates an existing federation", func() {
+			federation := &meshv1alpha2.MeshFederation{
+				Spec: meshv1alpha2.MeshFederationSpec{
+					TrustDomain: "west.example.com",
+					BundleEndpoint: meshv1alpha2.BundleEndpoint{
+						URL:     "https://spire.west.example.com",
+						Profile: meshv1alpha2.BundleEndpointProfileHTTPSWeb,
+					},
+				},
+			}
+			Expect(registerFederation(context.TODO(), nil, federation, fakeExec(true))).To(Succeed())
+
+			Expect(calls).To(HaveLen(2))
+			Expect(calls[1].args).To(Equal([]string{
+				"federation", "update",
+				"-socketPath", spireServerSocket,
+				"-trustDomain", "west.example.com",
+				"-bundleEndpointURL", "https://spire.west.example.com",
+				"-bundleEndpointProfile", "https_web",
+			}))
+			Expect(calls[1].stdin).To(BeEmpty())
+		})
+	})
+
+	It("writes the status of each MeshFederation", func() {
+		federation, err := newMeshFederation(peer)
+		Expect(err).ToNot(HaveOccurred())
+		bootstrap := meshv1alpha2.MeshFederation{
+			Spec: meshv1alpha2.MeshFederationSpec{
+				TrustDomain: "west.example.com",
+				BundleEndpoint: meshv1alpha2.BundleEndpoint{
+					URL:     "https://spire.west.example.com",
+					Profile: meshv1alpha2.BundleEndpointProfileHTTPSWeb,
+				},
+			},
+		}
+
+		var buf bytes.Buffer
+		writeFederationStatus(&buf, []meshv1alpha2.MeshFederation{*federation, bootstrap})
+		Expect(buf.String()).To(Equal(
+			"Trust Domain\tBundle Endpoint\tProfile\tCertificates\tExpires\n" +
+				"east.example.com\thttps://spire.east.example.com:8443\thttps_spiffe\t1\t2030-01-02T03:04:05Z\n" +
+				"west.example.com\thttps://spire.west.example.com\thttps_web\t0\t-\n",
+		))
+	})
+})
@@ -21,7 +21,8 @@ import (
 const (
 	longInject = `Inject the NGINX Service Mesh sidecar into Kubernetes resources.
 - Accepts JSON and YAML formats.
-- Outputs JSON or YAML resources with injected sidecars to stdout.`
+- Outputs JSON or YAML resources with injected sidecars to stdout.
+- Federates the pods with the trust domains of the MeshFederations of the mesh.`
 
 	exampleInject = `
   - Inject the resources in my-app.yaml and create in Kubernetes:
@@ -121,6 +122,14 @@ func Inject() *cobra.Command {
 			return fmt.Errorf("unable to get mesh config: %w", err)
 		}
 
+		federations, err := getMeshFederations(ctx, initK8sClient.DynamicClientSet(), initK8sClient.Namespace())
+		if err != nil {
+			return err
+		}
+		for _, federation := range federations {
+			injectConfig.FederatesWith = append(injectConfig.FederatesWith, federation.Spec.TrustDomain)
+		}
+
 		res, err := inject.IntoFile(injectConfig, *meshConfig)
 		if err != nil {
 			return fmt.Errorf("error injecting sidecar: %w", err)
@@ -0,0 +1,123 @@
+// Package commands contains all of the cli commands
+package commands // import "github.com/nginxinc/nginx-service-mesh/internal/nginx-meshctl/commands"
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+	"github.com/nginxinc/nginx-service-mesh/pkg/pod"
+)
+
+const longMTLSStatus = `Display the effective mTLS mode of each workload in NGINX Service Mesh.
+- Outputs the mode of each injected Pod and where the mode was set: the global config, the Pod annotation, or an MTLSPolicy.
+- Container ports with a different mode than their Pod are listed separately.
+`
+
+// MTLS groups the mTLS commands.
+func MTLS() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mtls",
+		Short: "Inspect the mTLS configuration of NGINX Service Mesh",
+		Long:  `Inspect the mTLS configuration of NGINX Service Mesh.`,
+	}
+	cmd.AddCommand(MTLSStatus())
+
+	return cmd
+}
+
+// MTLSStatus prints the effective mTLS mode of each workload.
+func MTLSStatus() *cobra.Command {
+	var workloadNamespace string
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Display the effective mTLS mode of each workload",
+		Long:  longMTLSStatus,
+	}
+
+	cmd.PersistentPreRunE = defaultPreRunFunc()
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), meshTimeout)
+		defer cancel()
+
+		meshConfig, err := mesh.GetMeshConfig(ctx, initK8sClient.Client(), initK8sClient.Namespace())
+		if err != nil {
+			return fmt.Errorf("unable to get mesh config: %w", err)
+		}
+
+		policies, err := getMTLSPolicies(ctx, initK8sClient.DynamicClientSet(), workloadNamespace)
+		if err != nil {
+			return err
+		}
+
+		pods := &v1.PodList{}
+		if err := initK8sClient.Client().List(ctx, pods, client.InNamespace(workloadNamespace)); err != nil {
+			return fmt.Errorf("error getting list of pods: %w", err)
+		}
+
+		tabWriter := TabWriterWithOpts()
+		writeMTLSStatus(tabWriter, meshConfig.Mtls.Mode, pods.Items, policies)
+
+		return tabWriter.Flush()
+	}
+	cmd.Flags().StringVar(
+		&workloadNamespace,
+		"workload-namespace",
+		"",
+		"only display the workloads in this namespace",
+	)
+
+	return cmd
+}
+
+// getMTLSPolicies lists the MTLSPolicies in a namespace, or in all namespaces if the namespace is empty.
+func getMTLSPolicies(ctx context.Context, dynamicClient dynamic.Interface, namespace string) ([]specs.MTLSPolicy, error) {
+	gvr := specs.SchemeGroupVersion.WithResource("mtlspolicies")
+	list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting list of MTLSPolicies: %w", err)
+	}
+
+	policies := make([]specs.MTLSPolicy, 0, len(list.Items))
+	for _, item := range list.Items {
+		var policy specs.MTLSPolicy
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &policy); err != nil {
+			return nil, fmt.Errorf("error converting MTLSPolicy '%s': %w", item.GetName(), err)
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// writeMTLSStatus writes the effective mTLS mode of each injected Pod, and of each of its
+// container ports that has a different mode than the Pod.
+func writeMTLSStatus(w io.Writer, globalMode string, pods []v1.Pod, policies []specs.MTLSPolicy) {
+	fmt.Fprintln(w, "Namespace\tPod\tPort\tMode\tSource")
+	for i := range pods {
+		p := &pods[i]
+		if !pod.IsInjected(p) {
+			continue
+		}
+		podMode := mesh.ResolveMTLSMode(globalMode, p, 0, policies)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Namespace, p.Name, "*", podMode.Mode, podMode.Source)
+
+		for _, container := range p.Spec.Containers {
+			for _, port := range container.Ports {
+				portMode := mesh.ResolveMTLSMode(globalMode, p, port.ContainerPort, policies)
+				if portMode != podMode {
+					fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", p.Namespace, p.Name, port.ContainerPort, portMode.Mode, portMode.Source)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+)
+
+var _ = Describe("MTLS", func() {
+	It("writes the effective mTLS mode of each injected pod and port", func() {
+		pods := []v1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "backend",
+					Namespace:   "default",
+					Labels:      map[string]string{"app": "backend"},
+					Annotations: map[string]string{mesh.InjectedAnnotation: mesh.Injected},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Ports: []v1.ContainerPort{{ContainerPort: 8080}, {ContainerPort: 9090}}},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "frontend",
+					Namespace:   "default",
+					Annotations: map[string]string{mesh.InjectedAnnotation: mesh.Injected, mesh.MTLSModeAnnotation: mesh.MtlsModeOff},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "uninjected", Namespace: "default"},
+			},
+		}
+		policies := []specs.MTLSPolicy{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"},
+				Spec: specs.MTLSPolicySpec{
+					Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "backend"}},
+					Mode:      mesh.MtlsModeStrict,
+					PortModes: []specs.PortMTLSMode{{Port: 9090, Mode: mesh.MtlsModePermissive}},
+				},
+			},
+		}
+
+		var buf bytes.Buffer
+		writeMTLSStatus(&buf, mesh.MtlsModePermissive, pods, policies)
+		Expect(buf.String()).To(Equal(
+			"Namespace\tPod\tPort\tMode\tSource\n" +
+				"default\tbackend\t*\tstrict\tMTLSPolicy default/backend\n" +
+				"default\tbackend\t9090\tpermissive\tMTLSPolicy default/backend port 9090\n" +
+				"default\tfrontend\t*\toff\tannotation\n",
+		))
+	})
+})
@@ -15,7 +15,10 @@ import (
 )
 
 const longServices = `List the Services registered with NGINX Service Mesh.
-- Outputs the Services and their upstream addresses and ports.
+- Outputs the Services and their upstream addresses, ports, and readiness.
+- The readiness is the condition of the upstream in all of the EndpointSlices of the Service.
+- The health of the upstreams is not reported: the results of HealthChecks are only known to each sidecar,
+  and can differ between sidecars.
 - The list contains only those Services whose Pods contain the NGINX Service Mesh sidecar.
 `
 
@@ -23,9 +26,21 @@ type serviceDetails struct {
 	name      string
 	namespace string
 	ports     []v1.ServicePort
-	addresses []string
+	upstreams []upstreamDetails
 }
 
+type upstreamDetails struct {
+	address   string
+	readiness string
+}
+
+// upstream readiness, from the EndpointSlice conditions.
+const (
+	upstreamReady       = "ready"
+	upstreamNotReady    = "not ready"
+	upstreamTerminating = "terminating"
+)
+
 // GetServices prints the list of services registered with NGINX Service Mesh.
 func GetServices() *cobra.Command {
 	cmd := &cobra.Command{
@@ -52,14 +67,14 @@ func GetServices() *cobra.Command {
 			if injectable, err := inject.IsNamespaceInjectable(
 				ctx, initK8sClient.Client(), serviceObj.Namespace); err == nil && injectable {
 				upstreams, epErr := getEndpoints(ctx, initK8sClient.Client(), serviceObj)
-				if err != nil {
+				if epErr != nil {
 					return epErr
 				}
 				meshServices = append(meshServices, serviceDetails{
 					name:      serviceObj.Name,
 					namespace: serviceObj.Namespace,
 					ports:     serviceObj.Spec.Ports,
-					addresses: upstreams,
+					upstreams: upstreams,
 				})
 			} else if err != nil {
 				return err
@@ -67,7 +82,7 @@ func GetServices() *cobra.Command {
 		}
 
 		tabWriter := TabWriterWithOpts()
-		fmt.Fprintln(tabWriter, "Service\tUpstream\tPort")
+		fmt.Fprintln(tabWriter, "Service\tUpstream\tPort\tReadiness")
 		for _, svc := range meshServices {
 			serviceLine := fmt.Sprintf("%s/%s\t", svc.namespace, svc.name)
 
@@ -80,17 +95,17 @@ func GetServices() *cobra.Command {
 				ports = append(ports, "<none>")
 			}
 
-			// create list of address and port combos
-			for _, addr := range svc.addresses {
-				serviceLine += fmt.Sprintf("%v\t%s\n\t", addr, strings.Join(ports, ","))
+			// create list of address, port, and status combos
+			for _, upstream := range svc.upstreams {
+				serviceLine += fmt.Sprintf("%v\t%s\t%s\n\t", upstream.address, strings.Join(ports, ","), upstream.readiness)
 			}
-			if len(svc.addresses) == 0 {
-				serviceLine += fmt.Sprintf("<none>\t%s\n\t", strings.Join(ports, ","))
+			if len(svc.upstreams) == 0 {
+				serviceLine += fmt.Sprintf("<none>\t%s\t\n\t", strings.Join(ports, ","))
 			}
 
 			// print everything out
 			fmt.Fprint(tabWriter, serviceLine)
-			fmt.Fprint(tabWriter, "\t\t\n")
+			fmt.Fprint(tabWriter, "\t\t\t\n")
 		}
 
 		return tabWriter.Flush()
@@ -99,24 +114,49 @@ func GetServices() *cobra.Command {
 	return cmd
 }
 
-// getEndpoints returns a slice of upstream addresses for a service.
-func getEndpoints(ctx context.Context, k8sClient client.Client, svc v1.Service) ([]string, error) {
+// getEndpoints returns a slice of upstream addresses and their readiness for a service,
+// from all of the EndpointSlices of the service.
+func getEndpoints(ctx context.Context, k8sClient client.Client, svc v1.Service) ([]upstreamDetails, error) {
 	endpointSlices := &discovery.EndpointSliceList{}
-	var upstreamAdresses []string
-	opt := client.MatchingLabels{"kubernetes.io/service-name": svc.Name}
-	if err := k8sClient.List(ctx, endpointSlices, opt); err != nil {
+	opts := []client.ListOption{
+		client.InNamespace(svc.Namespace),
+		client.MatchingLabels{discovery.LabelServiceName: svc.Name},
+	}
+	if err := k8sClient.List(ctx, endpointSlices, opts...); err != nil {
 		fmt.Printf("error getting list of endpoint slices for service: %v\n", err)
 		return nil, err
 	}
+	// in the case that a service has no upstreams yet but is in a namespace where injection would be enabled
+	// return nothing rather than an error since that service is still 'part' of the mesh.
+	var upstreams []upstreamDetails
+	// an endpoint can be in more than one EndpointSlice while it moves between them
+	seen := make(map[string]struct{})
 	for _, epSlice := range endpointSlices.Items {
-		if epSlice.Namespace == svc.Namespace {
-			for _, endpoint := range epSlice.Endpoints {
-				upstreamAdresses = append(upstreamAdresses, endpoint.Addresses...)
+		for _, endpoint := range epSlice.Endpoints {
+			readiness := endpointReadiness(endpoint.Conditions)
+			for _, addr := range endpoint.Addresses {
+				if _, ok := seen[addr]; ok {
+					continue
+				}
+				seen[addr] = struct{}{}
+				upstreams = append(upstreams, upstreamDetails{address: addr, readiness: readiness})
 			}
-			return upstreamAdresses, nil
 		}
 	}
-	// in the case that a service has no upstreams yet but is in a namespace where injection would be enabled
-	// return nothing rather than an error since that service is still 'part' of the mesh.
-	return nil, nil
+
+	return upstreams, nil
+}
+
+// endpointReadiness returns the readiness of an endpoint based on its EndpointSlice conditions.
+// A nil ready condition is treated as ready, as recommended by the EndpointSlice API.
+// The results of the active health checks of the sidecars are not known here.
+func endpointReadiness(conditions discovery.EndpointConditions) string {
+	if conditions.Terminating != nil && *conditions.Terminating {
+		return upstreamTerminating
+	}
+	if conditions.Ready != nil && !*conditions.Ready {
+		return upstreamNotReady
+	}
+
+	return upstreamReady
 }
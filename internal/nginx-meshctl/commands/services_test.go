@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Services", func() {
+	It("gets the upstreams of a service with their readiness", func() {
+		trueVal, falseVal := true, false
+		svc := v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"}}
+		epSlice := &discovery.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "svc-abcde",
+				Namespace: "default",
+				Labels:    map[string]string{"kubernetes.io/service-name": "svc"},
+			},
+			AddressType: discovery.AddressTypeIPv4,
+			Endpoints: []discovery.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discovery.EndpointConditions{Ready: &trueVal}},
+				{Addresses: []string{"10.0.0.2"}, Conditions: discovery.EndpointConditions{Ready: &falseVal}},
+				{
+					Addresses:  []string{"10.0.0.3"},
+					Conditions: discovery.EndpointConditions{Ready: &falseVal, Terminating: &trueVal},
+				},
+				{Addresses: []string{"10.0.0.4"}},
+			},
+		}
+		k8sClient := fakeClient.NewClientBuilder().WithObjects(epSlice).Build()
+
+		upstreams, err := getEndpoints(context.TODO(), k8sClient, svc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(upstreams).To(Equal([]upstreamDetails{
+			{address: "10.0.0.1", readiness: upstreamReady},
+			{address: "10.0.0.2", readiness: upstreamNotReady},
+			{address: "10.0.0.3", readiness: upstreamTerminating},
+			{address: "10.0.0.4", readiness: upstreamReady},
+		}))
+	})
+
+	It("gets the upstreams from all of the EndpointSlices of a service", func() {
+		svc := v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"}}
+		epSlice := func(name, namespace, service string, addressType discovery.AddressType, addresses ...string) *discovery.EndpointSlice {
+			endpoints := make([]discovery.Endpoint, 0, len(addresses))
+			for _, addr := range addresses {
+				endpoints = append(endpoints, discovery.Endpoint{Addresses: []string{addr}})
+			}
+
+			return &discovery.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{discovery.LabelServiceName: service},
+				},
+				AddressType: addressType,
+				Endpoints:   endpoints,
+			}
+		}
+		k8sClient := fakeClient.NewClientBuilder().WithObjects(
+			epSlice("svc-abcde", "default", "svc", discovery.AddressTypeIPv4, "10.0.0.1", "10.0.0.2"),
+			// an endpoint that is moving between slices is listed once
+			epSlice("svc-fghij", "default", "svc", discovery.AddressTypeIPv4, "10.0.0.2", "10.0.0.3"),
+			epSlice("svc-klmno", "default", "svc", discovery.AddressTypeIPv6, "fd00::1"),
+			epSlice("svc-abcde", "other", "svc", discovery.AddressTypeIPv4, "10.1.0.1"),
+			epSlice("other-abcde", "default", "other", discovery.AddressTypeIPv4, "10.0.1.1"),
+		).Build()
+
+		upstreams, err := getEndpoints(context.TODO(), k8sClient, svc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(upstreams).To(ConsistOf(
+			upstreamDetails{address: "10.0.0.1", readiness: upstreamReady},
+			upstreamDetails{address: "10.0.0.2", readiness: upstreamReady},
+			upstreamDetails{address: "10.0.0.3", readiness: upstreamReady},
+			upstreamDetails{address: "fd00::1", readiness: upstreamReady},
+		))
+	})
+
+	It("returns no upstreams for a service without endpoints", func() {
+		svc := v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"}}
+		upstreams, err := getEndpoints(context.TODO(), fakeClient.NewClientBuilder().Build(), svc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(upstreams).To(BeEmpty())
+	})
+})
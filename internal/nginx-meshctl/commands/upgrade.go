@@ -358,6 +358,16 @@ func (u *upgrader) savePreviousConfig(meshConfig previousMeshConfig) {
 	u.values.MTLS.SVIDTTL = *meshConfig.Mtls.SvidTTL
 	u.values.MTLS.Mode = *meshConfig.Mtls.Mode
 	u.values.ClientMaxBodySize = meshConfig.ClientMaxBodySize
+	// older meshes do not have the egress mode or locality in their config
+	if meshConfig.EgressMode != "" {
+		u.values.EgressMode = meshConfig.EgressMode
+	}
+	if meshConfig.Locality != nil {
+		u.values.Locality = helm.Locality{
+			Mode:              meshConfig.Locality.Mode,
+			FailoverThreshold: meshConfig.Locality.FailoverThreshold,
+		}
+	}
 
 	if meshConfig.Telemetry != (previousTelemetry{}) {
 		if u.values.Telemetry == nil {
@@ -379,6 +389,8 @@ func (u *upgrader) savePreviousConfig(meshConfig previousMeshConfig) {
 type previousMeshConfig struct {
 	Mtls                previousMtls      `json:"mtls"`
 	Telemetry           previousTelemetry `json:"telemetry"`
+	Locality            *previousLocality `json:"locality,omitempty"`
+	EgressMode          string            `json:"egressMode"`
 	AccessControlMode   string            `json:"accessControlMode"`
 	ClientMaxBodySize   string            `json:"clientMaxBodySize"`
 	LoadBalancingMethod string            `json:"loadBalancingMethod"`
@@ -394,6 +406,11 @@ type previousMtls struct {
 	SvidTTL   *string `json:"svidTTL,omitempty"`
 }
 
+type previousLocality struct {
+	Mode              string `json:"mode"`
+	FailoverThreshold int    `json:"failoverThreshold"`
+}
+
 type previousTelemetry struct {
 	Exporters    *exporters `json:"exporters,omitempty"`
 	SamplerRatio *float32   `json:"samplerRatio,omitempty"`
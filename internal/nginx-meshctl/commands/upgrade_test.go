@@ -14,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/nginxinc/nginx-service-mesh/pkg/helm"
 	"github.com/nginxinc/nginx-service-mesh/pkg/k8s"
 	"github.com/nginxinc/nginx-service-mesh/pkg/k8s/fake"
 )
@@ -50,7 +51,12 @@ func createMeshConfigMap(client kubernetes.Interface, namespace string) {
 	"accessControlMode": "deny",
 	"nginxErrorLogLevel": "warn",
 	"nginxLogFormat": "default",
-	"clientMaxBodySize": "1m"
+	"clientMaxBodySize": "1m",
+	"egressMode": "strict",
+	"locality": {
+		"mode": "zone",
+		"failoverThreshold": 70
+	}
 }`),
 		},
 	}
@@ -81,6 +87,8 @@ var _ = Describe("Upgrade", func() {
 			Expect(upg.values.AccessControlMode).To(Equal("deny"))
 			Expect(upg.values.Telemetry.SamplerRatio).To(Equal(float32(0.1)))
 			Expect(upg.values.Telemetry.Exporters.OTLP.Host).To(Equal("host"))
+			Expect(upg.values.EgressMode).To(Equal("strict"))
+			Expect(upg.values.Locality).To(Equal(helm.Locality{Mode: "zone", FailoverThreshold: 70}))
 		})
 	})
 
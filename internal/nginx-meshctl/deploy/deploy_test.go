@@ -1,6 +1,7 @@
 package deploy_test
 
 import (
+	"context"
 	"os"
 	"strings"
 
@@ -9,8 +10,11 @@ import (
 	"gopkg.in/yaml.v2"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	v1 "k8s.io/api/core/v1"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	sigsyaml "sigs.k8s.io/yaml"
 
 	"github.com/nginxinc/nginx-service-mesh/internal/nginx-meshctl/deploy"
+	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
 	"github.com/nginxinc/nginx-service-mesh/pkg/helm"
 	"github.com/nginxinc/nginx-service-mesh/pkg/k8s"
 	"github.com/nginxinc/nginx-service-mesh/pkg/k8s/fake"
@@ -79,6 +83,37 @@ var _ = Describe("Deploy", func() {
 		Expect(valid).To(BeFalse())
 	})
 
+	It("renders the locality into the mesh config", func() {
+		// mute stdout for this test since it will print the entire manifest
+		stdout := os.Stdout
+		defer func() { os.Stdout = stdout }()
+		os.Stdout = os.NewFile(0, os.DevNull)
+
+		var values helm.Values
+		Expect(yaml.Unmarshal(valuesYaml, &values)).To(Succeed())
+		deployer.Values = &values
+		values.Telemetry = nil
+		values.Locality = helm.Locality{Mode: mesh.LocalityModeZone, FailoverThreshold: 70}
+		manifest, err := deployer.Deploy()
+		Expect(err).ToNot(HaveOccurred())
+
+		var meshConfigMap *v1.ConfigMap
+		for _, doc := range strings.Split(manifest, "\n---\n") {
+			var cm v1.ConfigMap
+			if sigsyaml.Unmarshal([]byte(doc), &cm) == nil && cm.Kind == "ConfigMap" && cm.Name == mesh.MeshConfigMap {
+				meshConfigMap = &cm
+			}
+		}
+		Expect(meshConfigMap).ToNot(BeNil())
+		meshConfigMap.Namespace = v1.NamespaceDefault
+
+		k8sClient := ctrlfake.NewClientBuilder().WithObjects(meshConfigMap).Build()
+		cfg, err := mesh.GetMeshConfig(context.TODO(), k8sClient, v1.NamespaceDefault)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Locality).To(Equal(mesh.Locality{Mode: mesh.LocalityModeZone, FailoverThreshold: 70}))
+		Expect(cfg.EgressMode).To(Equal(values.EgressMode))
+	})
+
 	Context("input validation", func() {
 		It("validates mtls input", func() {
 			ttlValues := []string{"invalid", "00m", "01h", "2.3m", "4", "56", "7s", "1234567m", "h", "m", "mm", "hh"}
@@ -59,6 +59,15 @@ const (
 	tcpRoutesFile                       = "tcproutes.yaml"
 	rateLimitsFile                      = "ratelimits.yaml"
 	circuitBreakersFile                 = "circuitbreakers.yaml"
+	retryPoliciesFile                   = "retrypolicies.yaml"
+	timeoutPoliciesFile                 = "timeoutpolicies.yaml"
+	faultInjectionsFile                 = "faultinjections.yaml"
+	trafficMirrorsFile                  = "trafficmirrors.yaml"
+	httpRewritesFile                    = "httprewrites.yaml"
+	healthChecksFile                    = "healthchecks.yaml"
+	authorizationPoliciesFile           = "authorizationpolicies.yaml"
+	mtlsPoliciesFile                    = "mtlspolicies.yaml"
+	externalServicesFile                = "externalservices.yaml"
 )
 
 // DataFetcher gets all data for the support package and writes it to corresponding files.
@@ -699,6 +708,78 @@ var policies = []struct {
 			Resource: "circuitbreakers",
 		},
 	},
+	{
+		file: retryPoliciesFile,
+		resource: schema.GroupVersionResource{
+			Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+			Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+			Resource: "retrypolicies",
+		},
+	},
+	{
+		file: timeoutPoliciesFile,
+		resource: schema.GroupVersionResource{
+			Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+			Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+			Resource: "timeoutpolicies",
+		},
+	},
+	{
+		file: faultInjectionsFile,
+		resource: schema.GroupVersionResource{
+			Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+			Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+			Resource: "faultinjections",
+		},
+	},
+	{
+		file: trafficMirrorsFile,
+		resource: schema.GroupVersionResource{
+			Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+			Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+			Resource: "trafficmirrors",
+		},
+	},
+	{
+		file: httpRewritesFile,
+		resource: schema.GroupVersionResource{
+			Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+			Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+			Resource: "httprewrites",
+		},
+	},
+	{
+		file: healthChecksFile,
+		resource: schema.GroupVersionResource{
+			Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+			Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+			Resource: "healthchecks",
+		},
+	},
+	{
+		file: authorizationPoliciesFile,
+		resource: schema.GroupVersionResource{
+			Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+			Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+			Resource: "authorizationpolicies",
+		},
+	},
+	{
+		file: mtlsPoliciesFile,
+		resource: schema.GroupVersionResource{
+			Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+			Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+			Resource: "mtlspolicies",
+		},
+	},
+	{
+		file: externalServicesFile,
+		resource: schema.GroupVersionResource{
+			Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+			Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+			Resource: "externalservices",
+		},
+	},
 }
 
 // writeTrafficPolicies calls individual functions to write out TrafficSplits, TrafficTargets, etc.
@@ -816,17 +897,26 @@ Directory containing sidecar information.
 ## Configuration files
 
 - apiservices.yaml: All the NGINX Service Mesh APIService configurations.
+- authorizationpolicies.yaml: All the AuthorizationPolicy configurations.
 - circuitbreakers.yaml: All the CircuitBreaker configurations.
 - clusterrolebindings.yaml: All the NGINX Service Mesh ClusterRoleBinding configurations.
 - clusterroles.yaml: All the NGINX Service Mesh ClusterRole configurations.
 - crds.yaml: All the NGINX Service Mesh Custom Resource Definition (CRD) configurations.
 - deploy-config.json: Deploy-time configuration of NGINX Service Mesh.
+- externalservices.yaml: All the ExternalService configurations.
+- faultinjections.yaml: All the FaultInjection configurations.
+- healthchecks.yaml: All the HealthCheck configurations.
 - httproutegroups.yaml: All the HTTPRouteGroup configurations.
+- httprewrites.yaml: All the HTTPRewrite configurations.
 - mesh-config.json: Output of "nginx-meshctl config".
+- mtlspolicies.yaml: All the MTLSPolicy configurations.
 - mutatingwebhookconfigurations.yaml: All the NGINX Service Mesh MutatingWebhookConfiguration configurations.
 - ratelimits.yaml: All the RateLimit configurations.
+- retrypolicies.yaml: All the RetryPolicy configurations.
 - supportpkg-creation-logs.txt: Logs that occurred while the support package was being created.
 - tcproutes.yaml: All the TCPRoute configurations.
+- timeoutpolicies.yaml: All the TimeoutPolicy configurations.
+- trafficmirrors.yaml: All the TrafficMirror configurations.
 - trafficsplits.yaml: All the TrafficSplit configurations.
 - traffictargets.yaml: All the TrafficTarget configurations.
 - validatingwebhookconfigurations.yaml: All the NGINX Service Mesh ValidatingWebhookConfiguration configurations.
@@ -848,6 +848,94 @@ control2   0/1              0          <unknown>   <none>   <none>   <none>
 				TimeoutSeconds: 30,
 			},
 		}
+		retryPolicy := &nsmspecsv1alpha1.RetryPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "retry-policy",
+			},
+			Spec: nsmspecsv1alpha1.RetryPolicySpec{
+				Attempts:      3,
+				PerTryTimeout: "2s",
+			},
+		}
+		timeoutPolicy := &nsmspecsv1alpha1.TimeoutPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "timeout-policy",
+			},
+			Spec: nsmspecsv1alpha1.TimeoutPolicySpec{
+				ReadTimeout: "10s",
+			},
+		}
+		faultInjection := &nsmspecsv1alpha1.FaultInjection{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "fault-injection",
+			},
+			Spec: nsmspecsv1alpha1.FaultInjectionSpec{
+				Abort: &nsmspecsv1alpha1.FaultAbort{Percent: 10, HTTPStatus: 503},
+			},
+		}
+		trafficMirror := &nsmspecsv1alpha1.TrafficMirror{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "traffic-mirror",
+			},
+			Spec: nsmspecsv1alpha1.TrafficMirrorSpec{
+				Mirror: nsmspecsv1alpha1.MirrorBackend{Service: "canary", Port: 8080},
+			},
+		}
+		httpRewrite := &nsmspecsv1alpha1.HTTPRewrite{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "http-rewrite",
+			},
+			Spec: nsmspecsv1alpha1.HTTPRewriteSpec{
+				Path: &nsmspecsv1alpha1.PathRewrite{Prefix: "/api", ReplacePrefix: "/"},
+			},
+		}
+		healthCheck := &nsmspecsv1alpha1.HealthCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "health-check",
+			},
+			Spec: nsmspecsv1alpha1.HealthCheckSpec{
+				Path:  "/healthz",
+				Fails: 3,
+			},
+		}
+		authorizationPolicy := &nsmspecsv1alpha1.AuthorizationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "authorization-policy",
+			},
+			Spec: nsmspecsv1alpha1.AuthorizationPolicySpec{
+				Action: nsmspecsv1alpha1.AuthorizationActionDeny,
+				Rules: []nsmspecsv1alpha1.AuthorizationRule{
+					{To: &nsmspecsv1alpha1.AuthorizationOperation{Methods: []string{"DELETE"}}},
+				},
+			},
+		}
+		mtlsPolicy := &nsmspecsv1alpha1.MTLSPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "mtls-policy",
+			},
+			Spec: nsmspecsv1alpha1.MTLSPolicySpec{
+				Mode:      "permissive",
+				PortModes: []nsmspecsv1alpha1.PortMTLSMode{{Port: 9090, Mode: "off"}},
+			},
+		}
+		externalService := &nsmspecsv1alpha1.ExternalService{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "external-service",
+			},
+			Spec: nsmspecsv1alpha1.ExternalServiceSpec{
+				Hosts: []string{"api.example.com"},
+				Ports: []nsmspecsv1alpha1.ExternalServicePort{{Number: 443, Protocol: nsmspecsv1alpha1.ExternalServiceProtocolHTTPS}},
+			},
+		}
 
 		resources := []struct {
 			obj runtime.Object
@@ -901,6 +989,78 @@ control2   0/1              0          <unknown>   <none>   <none>   <none>
 				},
 				obj: rateLimit,
 			},
+			{
+				gvr: schema.GroupVersionResource{
+					Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+					Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+					Resource: "retrypolicies",
+				},
+				obj: retryPolicy,
+			},
+			{
+				gvr: schema.GroupVersionResource{
+					Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+					Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+					Resource: "timeoutpolicies",
+				},
+				obj: timeoutPolicy,
+			},
+			{
+				gvr: schema.GroupVersionResource{
+					Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+					Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+					Resource: "faultinjections",
+				},
+				obj: faultInjection,
+			},
+			{
+				gvr: schema.GroupVersionResource{
+					Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+					Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+					Resource: "trafficmirrors",
+				},
+				obj: trafficMirror,
+			},
+			{
+				gvr: schema.GroupVersionResource{
+					Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+					Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+					Resource: "httprewrites",
+				},
+				obj: httpRewrite,
+			},
+			{
+				gvr: schema.GroupVersionResource{
+					Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+					Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+					Resource: "healthchecks",
+				},
+				obj: healthCheck,
+			},
+			{
+				gvr: schema.GroupVersionResource{
+					Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+					Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+					Resource: "authorizationpolicies",
+				},
+				obj: authorizationPolicy,
+			},
+			{
+				gvr: schema.GroupVersionResource{
+					Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+					Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+					Resource: "mtlspolicies",
+				},
+				obj: mtlsPolicy,
+			},
+			{
+				gvr: schema.GroupVersionResource{
+					Group:    nsmspecsv1alpha1.SchemeGroupVersion.Group,
+					Version:  nsmspecsv1alpha1.SchemeGroupVersion.Version,
+					Resource: "externalservices",
+				},
+				obj: externalService,
+			},
 		}
 		k8sConfig := fakeK8s.NewFakeK8s(namespace, shouldSkipRelease)
 
@@ -932,6 +1092,24 @@ control2   0/1              0          <unknown>   <none>   <none>   <none>
 		Expect(err).ToNot(HaveOccurred())
 		circuitBreakerYaml, err := yaml.Marshal(circuitBreaker)
 		Expect(err).ToNot(HaveOccurred())
+		retryPolicyYaml, err := yaml.Marshal(retryPolicy)
+		Expect(err).ToNot(HaveOccurred())
+		timeoutPolicyYaml, err := yaml.Marshal(timeoutPolicy)
+		Expect(err).ToNot(HaveOccurred())
+		faultInjectionYaml, err := yaml.Marshal(faultInjection)
+		Expect(err).ToNot(HaveOccurred())
+		trafficMirrorYaml, err := yaml.Marshal(trafficMirror)
+		Expect(err).ToNot(HaveOccurred())
+		httpRewriteYaml, err := yaml.Marshal(httpRewrite)
+		Expect(err).ToNot(HaveOccurred())
+		healthCheckYaml, err := yaml.Marshal(healthCheck)
+		Expect(err).ToNot(HaveOccurred())
+		authorizationPolicyYaml, err := yaml.Marshal(authorizationPolicy)
+		Expect(err).ToNot(HaveOccurred())
+		mtlsPolicyYaml, err := yaml.Marshal(mtlsPolicy)
+		Expect(err).ToNot(HaveOccurred())
+		externalServiceYaml, err := yaml.Marshal(externalService)
+		Expect(err).ToNot(HaveOccurred())
 
 		// verify files exist and contain expected contents
 		files := []struct {
@@ -962,6 +1140,42 @@ control2   0/1              0          <unknown>   <none>   <none>   <none>
 				name:     filepath.Join(tmpDir, circuitBreakersFile),
 				expected: withHeader(circuitBreaker.Name, string(circuitBreakerYaml)),
 			},
+			{
+				name:     filepath.Join(tmpDir, retryPoliciesFile),
+				expected: withHeader(retryPolicy.Name, string(retryPolicyYaml)),
+			},
+			{
+				name:     filepath.Join(tmpDir, timeoutPoliciesFile),
+				expected: withHeader(timeoutPolicy.Name, string(timeoutPolicyYaml)),
+			},
+			{
+				name:     filepath.Join(tmpDir, faultInjectionsFile),
+				expected: withHeader(faultInjection.Name, string(faultInjectionYaml)),
+			},
+			{
+				name:     filepath.Join(tmpDir, trafficMirrorsFile),
+				expected: withHeader(trafficMirror.Name, string(trafficMirrorYaml)),
+			},
+			{
+				name:     filepath.Join(tmpDir, httpRewritesFile),
+				expected: withHeader(httpRewrite.Name, string(httpRewriteYaml)),
+			},
+			{
+				name:     filepath.Join(tmpDir, healthChecksFile),
+				expected: withHeader(healthCheck.Name, string(healthCheckYaml)),
+			},
+			{
+				name:     filepath.Join(tmpDir, authorizationPoliciesFile),
+				expected: withHeader(authorizationPolicy.Name, string(authorizationPolicyYaml)),
+			},
+			{
+				name:     filepath.Join(tmpDir, mtlsPoliciesFile),
+				expected: withHeader(mtlsPolicy.Name, string(mtlsPolicyYaml)),
+			},
+			{
+				name:     filepath.Join(tmpDir, externalServicesFile),
+				expected: withHeader(externalService.Name, string(externalServiceYaml)),
+			},
 		}
 
 		for _, file := range files {
@@ -11,12 +11,18 @@ type FullMeshConfig struct { //nolint:govet // fieldalignment not desired
 	// ClientMaxBodySize is NGINX client max body size.
 	ClientMaxBodySize string `yaml:"clientMaxBodySize" json:"clientMaxBodySize"`
 
+	// EgressMode for traffic to destinations outside of the mesh.
+	EgressMode string `yaml:"egressMode" json:"egressMode"`
+
 	// Environment to deploy the mesh into.
 	Environment string `yaml:"environment" json:"environment"`
 
 	// Namespace that the NGINX Service Mesh control plane belongs to.
 	Namespace string `yaml:"namespace" json:"namespace"`
 
+	// Locality is the configuration for locality-aware load balancing.
+	Locality Locality `yaml:"locality" json:"locality"`
+
 	// NGINXErrorLogLevel is the NGINX error log level.
 	NGINXErrorLogLevel string `yaml:"nginxErrorLogLevel" json:"nginxErrorLogLevel"`
 
@@ -60,6 +66,16 @@ type Mtls struct {
 	TrustDomain string `yaml:"trustDomain" json:"trustDomain"`
 }
 
+// Locality defines the locality-aware load balancing configuration.
+type Locality struct {
+	// Mode is the topology level that traffic prefers to stay within: none, zone, or region.
+	Mode string `yaml:"mode" json:"mode"`
+
+	// FailoverThreshold is the percentage of healthy upstream servers in the local zone or region
+	// below which traffic is spread across all localities.
+	FailoverThreshold int `yaml:"failoverThreshold" json:"failoverThreshold"`
+}
+
 // Telemetry defines the OpenTelemetry configuration.
 type Telemetry struct {
 	// Exporters is the exporters configuration for telemetry.
@@ -63,6 +63,30 @@ func (m *ConfigManager) GetMtlsMode() string {
 	return m.GetConfig().Mtls.Mode
 }
 
+// GetEgressMode returns the egress mode.
+// An unset mode allows all egress traffic.
+func (m *ConfigManager) GetEgressMode() string {
+	if mode := m.GetConfig().EgressMode; mode != "" {
+		return mode
+	}
+
+	return EgressModeAllowAll
+}
+
+// GetLocality returns the locality-aware load balancing config.
+// An unset config disables locality-aware load balancing.
+func (m *ConfigManager) GetLocality() Locality {
+	locality := m.GetConfig().Locality
+	if locality.Mode == "" {
+		return Locality{
+			Mode:              LocalityModeNone,
+			FailoverThreshold: DefaultLocalityFailoverThreshold,
+		}
+	}
+
+	return locality
+}
+
 // RecordAgentVersion adds an entry for a version tied to an agent.
 func (m *ConfigManager) RecordAgentVersion(agent, version string) {
 	m.Lock()
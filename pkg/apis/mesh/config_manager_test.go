@@ -41,6 +41,24 @@ var _ = Describe("Config Manager", func() {
 		Expect(mgr.GetAgentVersions()).To(HaveLen(1))
 	})
 
+	It("defaults the egress mode", func() {
+		Expect(mgr.GetEgressMode()).To(Equal(mesh.EgressModeAllowAll))
+
+		mgr.SetConfig(mesh.FullMeshConfig{EgressMode: mesh.EgressModeStrict})
+		Expect(mgr.GetEgressMode()).To(Equal(mesh.EgressModeStrict))
+	})
+
+	It("defaults the locality config", func() {
+		locality := mgr.GetLocality()
+		Expect(locality.Mode).To(Equal(mesh.LocalityModeNone))
+		Expect(locality.FailoverThreshold).To(Equal(mesh.DefaultLocalityFailoverThreshold))
+
+		mgr.SetConfig(mesh.FullMeshConfig{Locality: mesh.Locality{Mode: mesh.LocalityModeZone, FailoverThreshold: 0}})
+		locality = mgr.GetLocality()
+		Expect(locality.Mode).To(Equal(mesh.LocalityModeZone))
+		Expect(locality.FailoverThreshold).To(BeZero())
+	})
+
 	It("gets the mesh config", func() {
 		cm := &v1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
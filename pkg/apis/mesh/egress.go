@@ -0,0 +1,52 @@
+package mesh
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+)
+
+// IsExternalService returns whether or not a Service's annotations mark it as an external service.
+func IsExternalService(annotations map[string]string) bool {
+	external, err := strconv.ParseBool(annotations[ExternalServiceAnnotation])
+
+	return err == nil && external
+}
+
+// GetTLSOriginationAnnotations returns the TLS origination settings in an external Service's annotations, if applicable.
+// A nil TLSOrigination is returned if the sidecar does not originate TLS to the Service.
+func GetTLSOriginationAnnotations(annotations map[string]string) (*specs.TLSOrigination, error) {
+	if _, ok := annotations[TLSOriginationClientCertSecretAnnotation]; ok {
+		return nil, fmt.Errorf("'%s' is not supported: the sidecar does not present a client certificate to external services",
+			TLSOriginationClientCertSecretAnnotation)
+	}
+	origination := specs.TLSOrigination{
+		SNI:                 annotations[TLSOriginationSNIAnnotation],
+		CACertificateSecret: annotations[TLSOriginationCASecretAnnotation],
+	}
+
+	enabled := false
+	if val, ok := annotations[TLSOriginationAnnotation]; ok {
+		var err error
+		if enabled, err = strconv.ParseBool(val); err != nil {
+			return nil, fmt.Errorf("invalid TLS origination '%s': %w", val, err)
+		}
+	}
+	if !enabled {
+		if origination != (specs.TLSOrigination{}) {
+			return nil, fmt.Errorf("TLS origination settings require the '%s' annotation to be true", TLSOriginationAnnotation)
+		}
+
+		return nil, nil
+	}
+	if !IsExternalService(annotations) {
+		return nil, errors.New("TLS can only be originated to an external service")
+	}
+	if err := origination.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &origination, nil
+}
@@ -0,0 +1,111 @@
+package mesh
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+)
+
+// Sources of an effective mTLS mode.
+const (
+	MTLSSourceGlobal     = "global"
+	MTLSSourceAnnotation = "annotation"
+)
+
+// MTLSResolution is the effective mTLS mode of a workload or one of its ports, and where it came from.
+type MTLSResolution struct {
+	// Mode is the effective mTLS mode.
+	Mode string
+	// Source is the global config, the Pod annotation, or the MTLSPolicy that set the mode.
+	Source string
+}
+
+// ResolveMTLSMode returns the effective mTLS mode of a port of a Pod. A port of 0 resolves the mode
+// of the Pod as a whole. The first of the following that applies sets the mode:
+//
+//  1. The global mode, if it is strict. Strict mode cannot be weakened.
+//  2. An MTLSPolicy with a selector that matches the Pod. The port mode is used if it is set.
+//  3. The mTLS mode annotation of the Pod.
+//  4. An MTLSPolicy without a selector in the namespace of the Pod. The port mode is used if it is set.
+//  5. The global mode.
+//
+// When several policies of the same kind match, the oldest one is used, with ties broken by name.
+func ResolveMTLSMode(globalMode string, pod *v1.Pod, port int32, policies []specs.MTLSPolicy) MTLSResolution {
+	if globalMode == MtlsModeStrict {
+		return MTLSResolution{Mode: globalMode, Source: MTLSSourceGlobal}
+	}
+
+	var workload, namespace []specs.MTLSPolicy
+	for _, policy := range policies {
+		if policy.Namespace != pod.Namespace {
+			continue
+		}
+		if policy.Spec.Selector == nil {
+			namespace = append(namespace, policy)
+		} else if selectorMatches(policy.Spec.Selector, pod.Labels) {
+			workload = append(workload, policy)
+		}
+	}
+
+	if len(workload) > 0 {
+		return policyResolution(oldestPolicy(workload), port)
+	}
+	if val, ok := pod.Annotations[MTLSModeAnnotation]; ok {
+		if mode := strings.ToLower(val); isMTLSMode(mode) {
+			return MTLSResolution{Mode: mode, Source: MTLSSourceAnnotation}
+		}
+	}
+	if len(namespace) > 0 {
+		return policyResolution(oldestPolicy(namespace), port)
+	}
+
+	return MTLSResolution{Mode: globalMode, Source: MTLSSourceGlobal}
+}
+
+func policyResolution(policy specs.MTLSPolicy, port int32) MTLSResolution {
+	source := fmt.Sprintf("MTLSPolicy %s/%s", policy.Namespace, policy.Name)
+	if port != 0 {
+		for _, portMode := range policy.Spec.PortModes {
+			if portMode.Port == port {
+				return MTLSResolution{Mode: portMode.Mode, Source: fmt.Sprintf("%s port %d", source, port)}
+			}
+		}
+	}
+
+	return MTLSResolution{Mode: policy.Spec.Mode, Source: source}
+}
+
+// oldestPolicy returns the policy with the earliest creation time, breaking ties by name.
+func oldestPolicy(policies []specs.MTLSPolicy) specs.MTLSPolicy {
+	sort.Slice(policies, func(i, j int) bool {
+		ti, tj := policies[i].CreationTimestamp, policies[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+
+		return policies[i].Name < policies[j].Name
+	})
+
+	return policies[0]
+}
+
+func selectorMatches(selector *metav1.LabelSelector, podLabels map[string]string) bool {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+
+	return s.Matches(labels.Set(podLabels))
+}
+
+func isMTLSMode(mode string) bool {
+	_, ok := MtlsModes[mode]
+
+	return ok
+}
@@ -0,0 +1,104 @@
+package mesh_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+)
+
+var _ = Describe("mTLS mode resolution", func() {
+	created := metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	newPolicy := func(name string, selector map[string]string, mode string, age time.Duration) specs.MTLSPolicy {
+		policy := specs.MTLSPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         v1.NamespaceDefault,
+				CreationTimestamp: metav1.NewTime(created.Add(-age)),
+			},
+			Spec: specs.MTLSPolicySpec{Mode: mode},
+		}
+		if selector != nil {
+			policy.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+		}
+
+		return policy
+	}
+
+	var pod *v1.Pod
+	BeforeEach(func() {
+		pod = &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "backend",
+				Namespace: v1.NamespaceDefault,
+				Labels:    map[string]string{"app": "backend"},
+			},
+		}
+	})
+
+	It("uses the global mode when nothing else is set", func() {
+		res := mesh.ResolveMTLSMode(mesh.MtlsModePermissive, pod, 0, nil)
+		Expect(res).To(Equal(mesh.MTLSResolution{Mode: mesh.MtlsModePermissive, Source: mesh.MTLSSourceGlobal}))
+	})
+
+	It("does not weaken a strict global mode", func() {
+		pod.Annotations = map[string]string{mesh.MTLSModeAnnotation: mesh.MtlsModeOff}
+		policies := []specs.MTLSPolicy{newPolicy("workload", map[string]string{"app": "backend"}, mesh.MtlsModeOff, 0)}
+
+		res := mesh.ResolveMTLSMode(mesh.MtlsModeStrict, pod, 0, policies)
+		Expect(res).To(Equal(mesh.MTLSResolution{Mode: mesh.MtlsModeStrict, Source: mesh.MTLSSourceGlobal}))
+	})
+
+	It("applies policies and annotations in order of precedence", func() {
+		namespacePolicy := newPolicy("namespace", nil, mesh.MtlsModeOff, 0)
+		workloadPolicy := newPolicy("workload", map[string]string{"app": "backend"}, mesh.MtlsModeStrict, 0)
+		otherPolicy := newPolicy("other", map[string]string{"app": "frontend"}, mesh.MtlsModeOff, time.Hour)
+
+		res := mesh.ResolveMTLSMode(mesh.MtlsModePermissive, pod, 0, []specs.MTLSPolicy{namespacePolicy, otherPolicy})
+		Expect(res).To(Equal(mesh.MTLSResolution{Mode: mesh.MtlsModeOff, Source: "MTLSPolicy default/namespace"}))
+
+		pod.Annotations = map[string]string{mesh.MTLSModeAnnotation: "Strict"}
+		res = mesh.ResolveMTLSMode(mesh.MtlsModePermissive, pod, 0, []specs.MTLSPolicy{namespacePolicy})
+		Expect(res).To(Equal(mesh.MTLSResolution{Mode: mesh.MtlsModeStrict, Source: mesh.MTLSSourceAnnotation}))
+
+		pod.Annotations = map[string]string{mesh.MTLSModeAnnotation: mesh.MtlsModeOff}
+		res = mesh.ResolveMTLSMode(mesh.MtlsModePermissive, pod, 0, []specs.MTLSPolicy{namespacePolicy, workloadPolicy})
+		Expect(res).To(Equal(mesh.MTLSResolution{Mode: mesh.MtlsModeStrict, Source: "MTLSPolicy default/workload"}))
+	})
+
+	It("ignores policies in other namespaces", func() {
+		policy := newPolicy("namespace", nil, mesh.MtlsModeOff, 0)
+		policy.Namespace = "other"
+
+		res := mesh.ResolveMTLSMode(mesh.MtlsModePermissive, pod, 0, []specs.MTLSPolicy{policy})
+		Expect(res.Source).To(Equal(mesh.MTLSSourceGlobal))
+	})
+
+	It("uses the port mode of the policy", func() {
+		policy := newPolicy("workload", map[string]string{"app": "backend"}, mesh.MtlsModeStrict, 0)
+		policy.Spec.PortModes = []specs.PortMTLSMode{{Port: 9090, Mode: mesh.MtlsModePermissive}}
+		policies := []specs.MTLSPolicy{policy}
+
+		res := mesh.ResolveMTLSMode(mesh.MtlsModeOff, pod, 9090, policies)
+		Expect(res).To(Equal(mesh.MTLSResolution{Mode: mesh.MtlsModePermissive, Source: "MTLSPolicy default/workload port 9090"}))
+
+		res = mesh.ResolveMTLSMode(mesh.MtlsModeOff, pod, 8080, policies)
+		Expect(res).To(Equal(mesh.MTLSResolution{Mode: mesh.MtlsModeStrict, Source: "MTLSPolicy default/workload"}))
+	})
+
+	It("uses the oldest matching policy", func() {
+		policies := []specs.MTLSPolicy{
+			newPolicy("newer", map[string]string{"app": "backend"}, mesh.MtlsModeOff, 0),
+			newPolicy("b-older", map[string]string{"app": "backend"}, mesh.MtlsModeStrict, time.Hour),
+			newPolicy("a-older", map[string]string{"app": "backend"}, mesh.MtlsModePermissive, time.Hour),
+		}
+
+		res := mesh.ResolveMTLSMode(mesh.MtlsModeOff, pod, 0, policies)
+		Expect(res).To(Equal(mesh.MTLSResolution{Mode: mesh.MtlsModePermissive, Source: "MTLSPolicy default/a-older"}))
+	})
+})
@@ -0,0 +1,63 @@
+package mesh
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sessionAffinityKeyRegexp matches a valid cookie or header name.
+var sessionAffinityKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// SessionAffinity defines how requests from a client are pinned to the same upstream server.
+type SessionAffinity struct {
+	// Mode is the session affinity mode: none, cookie, header, or source-ip.
+	Mode string `json:"mode"`
+
+	// Key is the name of the cookie or header that identifies a session.
+	Key string `json:"key,omitempty"`
+}
+
+// Enabled returns whether or not session affinity is configured.
+func (a SessionAffinity) Enabled() bool {
+	return a.Mode != "" && a.Mode != SessionAffinityNone
+}
+
+// IsHash returns whether or not the session affinity replaces the load balancing method with a hash.
+func (a SessionAffinity) IsHash() bool {
+	return a.Mode == SessionAffinityHeader || a.Mode == SessionAffinitySourceIP
+}
+
+// Validate returns an error if the SessionAffinity is not valid.
+func (a SessionAffinity) Validate() error {
+	if _, ok := SessionAffinityModes[a.Mode]; !ok {
+		return fmt.Errorf("invalid session affinity mode '%s'", a.Mode)
+	}
+	if a.Mode == SessionAffinityHeader && a.Key == "" {
+		return errors.New("session affinity key must be set when mode is header")
+	}
+	if a.Key != "" && !sessionAffinityKeyRegexp.MatchString(a.Key) {
+		return fmt.Errorf("invalid session affinity key '%s'", a.Key)
+	}
+
+	return nil
+}
+
+// GetSessionAffinityAnnotations returns the session affinity in a Service's annotations, if applicable.
+func GetSessionAffinityAnnotations(annotations map[string]string) (SessionAffinity, error) {
+	mode, ok := annotations[SessionAffinityAnnotation]
+	if !ok {
+		return SessionAffinity{}, nil
+	}
+
+	affinity := SessionAffinity{
+		Mode: strings.ToLower(mode),
+		Key:  annotations[SessionAffinityKeyAnnotation],
+	}
+	if err := affinity.Validate(); err != nil {
+		return SessionAffinity{}, err
+	}
+
+	return affinity, nil
+}
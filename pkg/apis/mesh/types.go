@@ -33,6 +33,10 @@ const DeployLabel = "nsm.nginx.com/"
 // SpiffeIDLabel is the label to tell SPIRE to issue certs.
 const SpiffeIDLabel = "spiffe.io/spiffeid"
 
+// FederatesWithAnnotation lists the trust domains, separated by commas, that the SPIRE registration entry
+// of a pod federates with. SPIRE sends the trust bundles of these trust domains to the pod.
+const FederatesWithAnnotation = "spiffe.io/federatesWith"
+
 // proxy config annotations.
 const (
 	// IgnoreIncomingPortsAnnotation tells us which ports to ignore for incoming traffic.
@@ -43,10 +47,20 @@ const (
 	MTLSModeAnnotation = "config.nsm.nginx.com/mtls-mode"
 	// LoadBalancingAnnotation tells us the load balancing method for the service.
 	LoadBalancingAnnotation = "config.nsm.nginx.com/lb-method"
+	// SlowStartAnnotation tells us how long an upstream server of the service takes to ramp up to full weight.
+	SlowStartAnnotation = "config.nsm.nginx.com/slow-start"
+	// MaxConnsAnnotation tells us the maximum number of connections to each upstream server of the service.
+	MaxConnsAnnotation = "config.nsm.nginx.com/max-conns"
+	// SessionAffinityAnnotation tells us the session affinity mode for the service.
+	SessionAffinityAnnotation = "config.nsm.nginx.com/session-affinity"
+	// SessionAffinityKeyAnnotation tells us the cookie or header name used for session affinity.
+	SessionAffinityKeyAnnotation = "config.nsm.nginx.com/session-affinity-key"
 	// DefaultEgressRouteAllowedAnnotation tells us if a pod is allowed to send egress traffic to the egress endpoint.
 	DefaultEgressRouteAllowedAnnotation = "config.nsm.nginx.com/default-egress-allowed"
 	// ClientMaxBodySizeAnnotation tells us the client-max-body-size of the pod.
 	ClientMaxBodySizeAnnotation = "config.nsm.nginx.com/client-max-body-size"
+	// GlobalRateLimitAnnotation tells us if the pod synchronizes global rate limits with the other pods of its services.
+	GlobalRateLimitAnnotation = "config.nsm.nginx.com/global-rate-limit"
 )
 
 // NATS channel names.
@@ -68,6 +82,10 @@ const (
 	MeshConfigMap = "meshconfig"
 	// MeshConfigFileName is the name of the file where the mesh config is stored.
 	MeshConfigFileName = "meshconfig.json"
+	// SpireBundleConfigMap is the name of the config map that SPIRE writes the trust bundle of the mesh to.
+	SpireBundleConfigMap = "spire-bundle"
+	// SpireBundleKey is the key of the trust bundle in the SpireBundleConfigMap.
+	SpireBundleKey = "bundle.crt"
 	// NatsServer is the name of the nats-server service.
 	NatsServer = "nats-server"
 	// MeshController is the name of the mesh controller.
@@ -124,6 +142,18 @@ var AccessControlModes = map[string]struct{}{
 	AccessControlModeDeny:  {},
 }
 
+// Egress modes.
+const (
+	EgressModeAllowAll = "allow-all"
+	EgressModeStrict   = "strict"
+)
+
+// EgressModes are the supported egress modes.
+var EgressModes = map[string]struct{}{
+	EgressModeAllowAll: {},
+	EgressModeStrict:   {},
+}
+
 // NGINX error log levels.
 const (
 	NginxErrorLogLevelDebug  = "debug"
@@ -176,6 +206,43 @@ var LoadBalancingMethods = map[string]struct{}{
 	RandomTwoLeastTimeLastByte: {},
 }
 
+// Session affinity modes.
+const (
+	SessionAffinityNone     = "none"
+	SessionAffinityCookie   = "cookie"
+	SessionAffinityHeader   = "header"
+	SessionAffinitySourceIP = "source-ip"
+)
+
+// SessionAffinityModes are the supported session affinity modes.
+var SessionAffinityModes = map[string]struct{}{
+	SessionAffinityNone:     {},
+	SessionAffinityCookie:   {},
+	SessionAffinityHeader:   {},
+	SessionAffinitySourceIP: {},
+}
+
+// DefaultSessionAffinityCookie is the name of the cookie used for cookie-based session affinity.
+const DefaultSessionAffinityCookie = "nsm_route"
+
+// Locality modes.
+const (
+	LocalityModeNone   = "none"
+	LocalityModeZone   = "zone"
+	LocalityModeRegion = "region"
+)
+
+// LocalityModes are the supported locality-aware load balancing modes.
+var LocalityModes = map[string]struct{}{
+	LocalityModeNone:   {},
+	LocalityModeZone:   {},
+	LocalityModeRegion: {},
+}
+
+// DefaultLocalityFailoverThreshold is the default percentage of healthy local
+// upstream servers below which traffic fails over to other localities.
+const DefaultLocalityFailoverThreshold = 50
+
 // Kubernetes environments.
 const (
 	Kubernetes = "kubernetes"
@@ -205,3 +272,16 @@ type MetricsConfig struct {
 
 // ExternalServiceAnnotation tells us if an endpoint is for an external service.
 const ExternalServiceAnnotation = "service.nsm.nginx.com/external"
+
+// Annotations that configure the sidecar to originate TLS to an external service.
+const (
+	// TLSOriginationAnnotation tells us if the sidecar originates TLS to the external service.
+	TLSOriginationAnnotation = "service.nsm.nginx.com/tls-origination"
+	// TLSOriginationSNIAnnotation tells us the server name sent in the TLS handshake.
+	TLSOriginationSNIAnnotation = "service.nsm.nginx.com/tls-origination-sni"
+	// TLSOriginationCASecretAnnotation tells us the Secret with the CA bundle used to verify the external service.
+	TLSOriginationCASecretAnnotation = "service.nsm.nginx.com/tls-origination-ca-secret"
+	// TLSOriginationClientCertSecretAnnotation is not supported; a Service that sets it is rejected,
+	// because the sidecar does not present a client certificate to the external service.
+	TLSOriginationClientCertSecretAnnotation = "service.nsm.nginx.com/tls-origination-client-cert-secret"
+)
@@ -0,0 +1,83 @@
+package mesh
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+)
+
+// UpstreamOverrides are the per-Service upstream settings that override the mesh-wide configuration.
+type UpstreamOverrides struct {
+	// LBMethod is the load balancing method for the Service.
+	LBMethod string `json:"lbMethod,omitempty"`
+
+	// SlowStart is the time an upstream server takes to recover its full weight
+	// after becoming available, i.e. 30s.
+	SlowStart string `json:"slowStart,omitempty"`
+
+	// MaxConns is the maximum number of simultaneous connections to each upstream server.
+	// Zero means there is no limit.
+	MaxConns int `json:"maxConns,omitempty"`
+}
+
+// SupportsSlowStart returns whether or not slow start can be used with a load balancing method.
+// NGINX does not support slow start with the random or hash methods.
+func SupportsSlowStart(lbMethod string) bool {
+	return !strings.HasPrefix(lbMethod, Random)
+}
+
+// Validate returns an error if the UpstreamOverrides are not valid.
+// Settings that depend on the mesh-wide configuration are checked by ValidateUpstreamOverrides.
+func (o UpstreamOverrides) Validate() error {
+	if o.LBMethod != "" {
+		if _, ok := LoadBalancingMethods[o.LBMethod]; !ok {
+			return fmt.Errorf("invalid load balancing method '%s'", o.LBMethod)
+		}
+	}
+	if o.SlowStart != "" {
+		if err := specs.ValidateDuration("slow start", o.SlowStart); err != nil {
+			return err
+		}
+		if !SupportsSlowStart(o.LBMethod) {
+			return fmt.Errorf("slow start cannot be used with load balancing method '%s'", o.LBMethod)
+		}
+	}
+	if o.MaxConns < 0 {
+		return errors.New("max conns cannot be negative")
+	}
+
+	return nil
+}
+
+// LBMethodOrDefault returns the load balancing method that overrides the mesh-wide method, if set,
+// otherwise the mesh-wide method.
+func (o UpstreamOverrides) LBMethodOrDefault(meshLBMethod string) string {
+	if o.LBMethod != "" {
+		return o.LBMethod
+	}
+
+	return meshLBMethod
+}
+
+// GetUpstreamOverrideAnnotations returns the upstream overrides in a Service's annotations, if applicable.
+func GetUpstreamOverrideAnnotations(annotations map[string]string) (UpstreamOverrides, error) {
+	overrides := UpstreamOverrides{
+		LBMethod:  annotations[LoadBalancingAnnotation],
+		SlowStart: annotations[SlowStartAnnotation],
+	}
+	if val, ok := annotations[MaxConnsAnnotation]; ok {
+		maxConns, err := strconv.Atoi(val)
+		if err != nil {
+			return UpstreamOverrides{}, fmt.Errorf("invalid max conns '%s': %w", val, err)
+		}
+		overrides.MaxConns = maxConns
+	}
+	if err := overrides.Validate(); err != nil {
+		return UpstreamOverrides{}, err
+	}
+
+	return overrides, nil
+}
@@ -38,6 +38,14 @@ type MeshConfigSpec struct { //nolint:govet // fieldalignment not desired
 	// +optional
 	ClientMaxBodySize *string `json:"clientMaxBodySize,omitempty"`
 
+	// EgressMode for traffic to destinations outside of the mesh.
+	// +optional
+	EgressMode *string `json:"egressMode,omitempty"`
+
+	// Locality is the configuration for locality-aware load balancing.
+	// +optional
+	Locality *LocalitySpec `json:"locality,omitempty"`
+
 	// NGINXErrorLogLevel is the NGINX error log level.
 	// +optional
 	NGINXErrorLogLevel *string `json:"nginxErrorLogLevel,omitempty"`
@@ -78,6 +86,18 @@ type MtlsSpec struct {
 	SvidTTL *string `json:"svidTTL,omitempty"`
 }
 
+// LocalitySpec defines the locality-aware load balancing configuration.
+type LocalitySpec struct {
+	// Mode is the topology level that traffic prefers to stay within: none, zone, or region.
+	// +optional
+	Mode *string `json:"mode,omitempty"`
+
+	// FailoverThreshold is the percentage of healthy upstream servers in the local zone or region
+	// below which traffic is spread across all localities.
+	// +optional
+	FailoverThreshold *int32 `json:"failoverThreshold,omitempty"`
+}
+
 // TelemetrySpec defines the OpenTelemetry configuration.
 type TelemetrySpec struct {
 	// Exporters is the exporters configuration for telemetry.
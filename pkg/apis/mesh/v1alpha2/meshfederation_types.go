@@ -0,0 +1,113 @@
+package v1alpha2
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Bundle endpoint profiles, as defined by the SPIFFE Trust Domain and Bundle specification.
+const (
+	// BundleEndpointProfileHTTPSSPIFFE authenticates the bundle endpoint with an SVID from the remote trust domain.
+	BundleEndpointProfileHTTPSSPIFFE = "https_spiffe"
+	// BundleEndpointProfileHTTPSWeb authenticates the bundle endpoint with a certificate from a public CA.
+	BundleEndpointProfileHTTPSWeb = "https_web"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MeshFederation describes a remote NGINX Service Mesh installation that this mesh federates with.
+// Workloads in federated meshes trust each other's SVIDs.
+type MeshFederation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the remote mesh and its bundle endpoint.
+	Spec MeshFederationSpec `json:"spec"`
+}
+
+// MeshFederationSpec defines the remote mesh and where to get its trust bundle.
+type MeshFederationSpec struct {
+	// TrustDomain is the trust domain of the remote mesh.
+	TrustDomain string `json:"trustDomain"`
+
+	// BundleEndpoint is the SPIFFE bundle endpoint of the remote mesh.
+	BundleEndpoint BundleEndpoint `json:"bundleEndpoint"`
+
+	// TrustBundle is the PEM encoded trust bundle of the remote mesh. It bootstraps the federation
+	// until the trust bundle is refreshed from the bundle endpoint.
+	// +optional
+	TrustBundle string `json:"trustBundle,omitempty"`
+}
+
+// BundleEndpoint defines the SPIFFE bundle endpoint of a remote mesh.
+type BundleEndpoint struct {
+	// URL is the https URL of the bundle endpoint.
+	URL string `json:"url"`
+
+	// Profile is the bundle endpoint profile: https_spiffe or https_web.
+	Profile string `json:"profile"`
+
+	// SPIFFEID is the SPIFFE ID of the bundle endpoint server. Required for the https_spiffe profile.
+	// +optional
+	SPIFFEID string `json:"spiffeID,omitempty"`
+}
+
+// Validate returns an error if the MeshFederationSpec is not valid.
+func (s MeshFederationSpec) Validate() error {
+	td, err := spiffeid.TrustDomainFromString(s.TrustDomain)
+	if err != nil {
+		return fmt.Errorf("invalid trust domain '%s': %w", s.TrustDomain, err)
+	}
+	if err := s.BundleEndpoint.validate(td); err != nil {
+		return err
+	}
+	if s.TrustBundle != "" {
+		if _, err := x509bundle.Parse(td, []byte(s.TrustBundle)); err != nil {
+			return fmt.Errorf("invalid trust bundle: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (e BundleEndpoint) validate(td spiffeid.TrustDomain) error {
+	u, err := url.Parse(e.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("bundle endpoint url '%s' must be an https URL", e.URL)
+	}
+	switch e.Profile {
+	case BundleEndpointProfileHTTPSWeb:
+	case BundleEndpointProfileHTTPSSPIFFE:
+		if e.SPIFFEID == "" {
+			return fmt.Errorf("bundle endpoint spiffeID must be set for the %s profile", BundleEndpointProfileHTTPSSPIFFE)
+		}
+		id, err := spiffeid.FromString(e.SPIFFEID)
+		if err != nil {
+			return fmt.Errorf("invalid bundle endpoint spiffeID '%s': %w", e.SPIFFEID, err)
+		}
+		if !id.MemberOf(td) {
+			return errors.New("bundle endpoint spiffeID must be in the trust domain of the remote mesh")
+		}
+	default:
+		return fmt.Errorf("bundle endpoint profile must be %s or %s, got '%s'",
+			BundleEndpointProfileHTTPSSPIFFE, BundleEndpointProfileHTTPSWeb, e.Profile)
+	}
+
+	return nil
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MeshFederationList is a list of MeshFederation resources.
+type MeshFederationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []MeshFederation `json:"items"`
+}
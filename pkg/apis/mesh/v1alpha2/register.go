@@ -45,6 +45,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&MeshConfigList{},
 		&MeshConfigClass{},
 		&MeshConfigClassList{},
+		&MeshFederation{},
+		&MeshFederationList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 
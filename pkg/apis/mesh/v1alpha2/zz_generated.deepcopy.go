@@ -9,6 +9,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BundleEndpoint) DeepCopyInto(out *BundleEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleEndpoint.
+func (in *BundleEndpoint) DeepCopy() *BundleEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(BundleEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExportersSpec) DeepCopyInto(out *ExportersSpec) {
 	*out = *in
@@ -25,6 +40,31 @@ func (in *ExportersSpec) DeepCopy() *ExportersSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalitySpec) DeepCopyInto(out *LocalitySpec) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(string)
+		**out = **in
+	}
+	if in.FailoverThreshold != nil {
+		in, out := &in.FailoverThreshold, &out.FailoverThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalitySpec.
+func (in *LocalitySpec) DeepCopy() *LocalitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MeshConfig) DeepCopyInto(out *MeshConfig) {
 	*out = *in
@@ -191,6 +231,16 @@ func (in *MeshConfigSpec) DeepCopyInto(out *MeshConfigSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.EgressMode != nil {
+		in, out := &in.EgressMode, &out.EgressMode
+		*out = new(string)
+		**out = **in
+	}
+	if in.Locality != nil {
+		in, out := &in.Locality, &out.Locality
+		*out = new(LocalitySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.NGINXErrorLogLevel != nil {
 		in, out := &in.NGINXErrorLogLevel, &out.NGINXErrorLogLevel
 		*out = new(string)
@@ -243,6 +293,80 @@ func (in *MeshConfigStatus) DeepCopy() *MeshConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshFederation) DeepCopyInto(out *MeshFederation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshFederation.
+func (in *MeshFederation) DeepCopy() *MeshFederation {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshFederation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MeshFederation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshFederationList) DeepCopyInto(out *MeshFederationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MeshFederation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshFederationList.
+func (in *MeshFederationList) DeepCopy() *MeshFederationList {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshFederationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MeshFederationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshFederationSpec) DeepCopyInto(out *MeshFederationSpec) {
+	*out = *in
+	out.BundleEndpoint = in.BundleEndpoint
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshFederationSpec.
+func (in *MeshFederationSpec) DeepCopy() *MeshFederationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshFederationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MtlsSpec) DeepCopyInto(out *MtlsSpec) {
 	*out = *in
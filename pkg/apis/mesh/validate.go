@@ -30,3 +30,35 @@ func ValidateLBMethod(k8sClient client.Client, lbMethod string) error {
 
 	return nil
 }
+
+// ValidateUpstreamOverrides ensures the upstream overrides of a Service are valid with the mesh-wide load balancing method.
+// The load balancing method that the Service uses, whether overridden or mesh-wide, is validated with ValidateLBMethod,
+// and slow start is rejected if that method does not support it.
+func ValidateUpstreamOverrides(k8sClient client.Client, meshLBMethod string, overrides UpstreamOverrides) error {
+	if err := overrides.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	lbMethod := overrides.LBMethodOrDefault(meshLBMethod)
+	if overrides.SlowStart != "" && !SupportsSlowStart(lbMethod) {
+		return fmt.Errorf("invalid configuration: slow start cannot be used with load balancing method '%s'", lbMethod)
+	}
+
+	return ValidateLBMethod(k8sClient, lbMethod)
+}
+
+// ValidateSessionAffinity ensures the session affinity is valid for a Service using the load balancing method.
+// Hash-based session affinity replaces the load balancing method, so the method is only validated
+// if it is still in use.
+func ValidateSessionAffinity(k8sClient client.Client, lbMethod string, affinity SessionAffinity) error {
+	if !affinity.Enabled() {
+		return ValidateLBMethod(k8sClient, lbMethod)
+	}
+	if err := affinity.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if affinity.IsHash() {
+		return nil
+	}
+
+	return ValidateLBMethod(k8sClient, lbMethod)
+}
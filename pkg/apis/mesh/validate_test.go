@@ -42,4 +42,148 @@ var _ = Describe("Validate", func() {
 			Expect(mesh.ValidateLBMethod(client, mesh.Random)).ToNot(Succeed())
 		})
 	})
+
+	Context("upstream overrides", func() {
+		It("gets the upstream overrides from annotations", func() {
+			overrides, err := mesh.GetUpstreamOverrideAnnotations(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(overrides).To(Equal(mesh.UpstreamOverrides{}))
+
+			overrides, err = mesh.GetUpstreamOverrideAnnotations(map[string]string{
+				mesh.LoadBalancingAnnotation: mesh.LeastConn,
+				mesh.SlowStartAnnotation:     "30s",
+				mesh.MaxConnsAnnotation:      "100",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(overrides).To(Equal(mesh.UpstreamOverrides{LBMethod: mesh.LeastConn, SlowStart: "30s", MaxConns: 100}))
+		})
+
+		It("rejects invalid upstream overrides", func() {
+			invalid := []map[string]string{
+				{mesh.LoadBalancingAnnotation: "invalid"},
+				{mesh.SlowStartAnnotation: "30"},
+				{mesh.SlowStartAnnotation: "30s", mesh.LoadBalancingAnnotation: mesh.RandomTwoLeastConn},
+				{mesh.MaxConnsAnnotation: "many"},
+				{mesh.MaxConnsAnnotation: "-1"},
+			}
+			for _, annotations := range invalid {
+				_, err := mesh.GetUpstreamOverrideAnnotations(annotations)
+				Expect(err).To(HaveOccurred(), "%v", annotations)
+			}
+		})
+
+		It("validates the upstream overrides with the mesh-wide load balancing method", func() {
+			scheme := runtime.NewScheme()
+			Expect(specs.AddToScheme(scheme)).To(Succeed())
+			cb := &specs.CircuitBreaker{ObjectMeta: metav1.ObjectMeta{Name: "test-cb", Namespace: v1.NamespaceDefault}}
+			client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(cb).Build()
+
+			overrides := mesh.UpstreamOverrides{LBMethod: mesh.LeastConn, SlowStart: "30s"}
+			Expect(mesh.ValidateUpstreamOverrides(client, mesh.Random, overrides)).To(Succeed())
+			// the overridden method is validated with the circuit breakers
+			overrides = mesh.UpstreamOverrides{LBMethod: mesh.RandomTwo}
+			Expect(mesh.ValidateUpstreamOverrides(client, mesh.LeastConn, overrides)).ToNot(Succeed())
+			// slow start is validated with the mesh-wide method if the method is not overridden
+			overrides = mesh.UpstreamOverrides{SlowStart: "30s"}
+			Expect(mesh.ValidateUpstreamOverrides(client, mesh.LeastConn, overrides)).To(Succeed())
+			Expect(mesh.ValidateUpstreamOverrides(fake.NewClientBuilder().WithScheme(scheme).Build(), mesh.Random, overrides)).
+				To(MatchError(ContainSubstring("slow start cannot be used")))
+			Expect(mesh.ValidateUpstreamOverrides(client, mesh.LeastConn, mesh.UpstreamOverrides{SlowStart: "30"})).ToNot(Succeed())
+		})
+	})
+
+	Context("session affinity", func() {
+		var fakeClientBuilder *fake.ClientBuilder
+		scheme := runtime.NewScheme()
+		Expect(specs.AddToScheme(scheme)).To(Succeed())
+		cb := &specs.CircuitBreaker{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cb",
+				Namespace: v1.NamespaceDefault,
+			},
+		}
+
+		BeforeEach(func() {
+			fakeClientBuilder = fake.NewClientBuilder().WithScheme(scheme)
+		})
+
+		It("validates the load balancing method when session affinity is not set", func() {
+			client := fakeClientBuilder.WithRuntimeObjects(cb).Build()
+			Expect(mesh.ValidateSessionAffinity(client, mesh.Random, mesh.SessionAffinity{})).ToNot(Succeed())
+			Expect(mesh.ValidateSessionAffinity(client, mesh.LeastConn, mesh.SessionAffinity{})).To(Succeed())
+		})
+
+		It("is valid when hash affinity replaces a random method and circuit breakers exist", func() {
+			client := fakeClientBuilder.WithRuntimeObjects(cb).Build()
+			affinity := mesh.SessionAffinity{Mode: mesh.SessionAffinitySourceIP}
+			Expect(mesh.ValidateSessionAffinity(client, mesh.Random, affinity)).To(Succeed())
+		})
+
+		It("is invalid when cookie affinity uses a random method and circuit breakers exist", func() {
+			client := fakeClientBuilder.WithRuntimeObjects(cb).Build()
+			affinity := mesh.SessionAffinity{Mode: mesh.SessionAffinityCookie}
+			Expect(mesh.ValidateSessionAffinity(client, mesh.RandomTwo, affinity)).ToNot(Succeed())
+		})
+
+		It("is invalid when the session affinity is invalid", func() {
+			affinity := mesh.SessionAffinity{Mode: mesh.SessionAffinityHeader}
+			Expect(mesh.ValidateSessionAffinity(fakeClientBuilder.Build(), mesh.LeastConn, affinity)).ToNot(Succeed())
+		})
+
+		It("gets the session affinity from annotations", func() {
+			affinity, err := mesh.GetSessionAffinityAnnotations(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(affinity.Enabled()).To(BeFalse())
+
+			affinity, err = mesh.GetSessionAffinityAnnotations(map[string]string{
+				mesh.SessionAffinityAnnotation:    "Header",
+				mesh.SessionAffinityKeyAnnotation: "X-User-ID",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(affinity).To(Equal(mesh.SessionAffinity{Mode: mesh.SessionAffinityHeader, Key: "X-User-ID"}))
+
+			_, err = mesh.GetSessionAffinityAnnotations(map[string]string{mesh.SessionAffinityAnnotation: "invalid"})
+			Expect(err).To(HaveOccurred())
+
+			_, err = mesh.GetSessionAffinityAnnotations(map[string]string{
+				mesh.SessionAffinityAnnotation:    mesh.SessionAffinityCookie,
+				mesh.SessionAffinityKeyAnnotation: "bad cookie;",
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("TLS origination", func() {
+		It("gets the TLS origination from annotations", func() {
+			origination, err := mesh.GetTLSOriginationAnnotations(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(origination).To(BeNil())
+
+			origination, err = mesh.GetTLSOriginationAnnotations(map[string]string{
+				mesh.ExternalServiceAnnotation:        "true",
+				mesh.TLSOriginationAnnotation:         "true",
+				mesh.TLSOriginationSNIAnnotation:      "api.example.com",
+				mesh.TLSOriginationCASecretAnnotation: "example-ca",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(origination).To(Equal(&specs.TLSOrigination{
+				SNI:                 "api.example.com",
+				CACertificateSecret: "example-ca",
+			}))
+		})
+
+		It("returns an error for invalid annotations", func() {
+			for _, annotations := range []map[string]string{
+				{mesh.ExternalServiceAnnotation: "true", mesh.TLSOriginationAnnotation: "yes"},
+				{mesh.ExternalServiceAnnotation: "true", mesh.TLSOriginationSNIAnnotation: "api.example.com"},
+				{mesh.TLSOriginationAnnotation: "true"},
+				{mesh.ExternalServiceAnnotation: "true", mesh.TLSOriginationAnnotation: "true", mesh.TLSOriginationCASecretAnnotation: "Bad_Name"},
+				// client certificates are not supported
+				{mesh.ExternalServiceAnnotation: "true", mesh.TLSOriginationAnnotation: "true", mesh.TLSOriginationClientCertSecretAnnotation: "client"},
+			} {
+				_, err := mesh.GetTLSOriginationAnnotations(annotations)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
 })
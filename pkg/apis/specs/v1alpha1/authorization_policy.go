@@ -0,0 +1,212 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuthorizationAction is the action taken on requests that match an AuthorizationPolicy.
+type AuthorizationAction string
+
+// Actions of an AuthorizationPolicy. Deny policies take precedence over allow policies.
+const (
+	AuthorizationActionAllow AuthorizationAction = "ALLOW"
+	AuthorizationActionDeny  AuthorizationAction = "DENY"
+)
+
+const spiffeScheme = "spiffe://"
+
+var httpMethods = map[string]struct{}{
+	"GET":     {},
+	"HEAD":    {},
+	"POST":    {},
+	"PUT":     {},
+	"PATCH":   {},
+	"DELETE":  {},
+	"CONNECT": {},
+	"OPTIONS": {},
+	"TRACE":   {},
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AuthorizationPolicy allows or denies requests to a destination based on the identity of the source,
+// the HTTP method and path of the request, and the claims of a validated JWT.
+type AuthorizationPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the authorization policy for a destination's traffic
+	Spec AuthorizationPolicySpec `json:"spec"`
+}
+
+// AuthorizationPolicySpec defines the requests that are allowed or denied.
+type AuthorizationPolicySpec struct {
+	// Destination is the resource to which the policy applies
+	Destination v1.ObjectReference `json:"destination"`
+
+	// Action is the action taken on matching requests, either ALLOW or DENY.
+	Action AuthorizationAction `json:"action"`
+
+	// Rules is the list of rules that requests are matched against.
+	// A request matches the policy if it matches any of the rules.
+	Rules []AuthorizationRule `json:"rules"`
+
+	// JWT configures the validation of JSON Web Tokens whose claims are matched by the rules.
+	// +optional
+	JWT *JWTValidation `json:"jwt,omitempty"`
+}
+
+// AuthorizationRule matches a request when all of its non-empty fields match.
+type AuthorizationRule struct {
+	// From matches the source of the request.
+	// +optional
+	From *AuthorizationSource `json:"from,omitempty"`
+
+	// To matches the HTTP operation of the request.
+	// +optional
+	To *AuthorizationOperation `json:"to,omitempty"`
+
+	// When matches the claims of the validated JWT of the request.
+	// +optional
+	When []ClaimCondition `json:"when,omitempty"`
+}
+
+// AuthorizationSource matches the identity of the source of a request.
+type AuthorizationSource struct {
+	// Principals is a list of SPIFFE IDs, i.e. spiffe://example.org/ns/default/sa/client.
+	// +optional
+	Principals []string `json:"principals,omitempty"`
+
+	// Namespaces is a list of namespaces of the source.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// AuthorizationOperation matches the HTTP method and path of a request.
+type AuthorizationOperation struct {
+	// Methods is a list of HTTP methods, i.e. GET.
+	// +optional
+	Methods []string `json:"methods,omitempty"`
+
+	// Paths is a list of exact paths, or path prefixes ending in *, i.e. /api/*.
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+}
+
+// ClaimCondition matches a claim of a JWT against a list of values.
+type ClaimCondition struct {
+	// Claim is the name of the claim, i.e. groups.
+	Claim string `json:"claim"`
+
+	// Values is the list of values that match the claim.
+	Values []string `json:"values"`
+}
+
+// JWTValidation defines how JSON Web Tokens are validated.
+type JWTValidation struct {
+	// Issuer is the expected issuer (iss) of the token.
+	Issuer string `json:"issuer"`
+
+	// JWKSURI is the https URI of the JSON Web Key Set used to verify the signature of the token.
+	JWKSURI string `json:"jwksURI"`
+
+	// Audiences is a list of accepted audiences (aud) of the token.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+// Validate returns an error if the AuthorizationPolicySpec is not valid.
+func (s AuthorizationPolicySpec) Validate() error {
+	if err := validateDestination(s.Destination); err != nil {
+		return err
+	}
+	if s.Action != AuthorizationActionAllow && s.Action != AuthorizationActionDeny {
+		return fmt.Errorf("action must be %s or %s, got '%s'", AuthorizationActionAllow, AuthorizationActionDeny, s.Action)
+	}
+	if len(s.Rules) == 0 {
+		return errors.New("at least one rule must be set")
+	}
+	if s.JWT != nil {
+		if err := s.JWT.validate(); err != nil {
+			return err
+		}
+	}
+	for _, rule := range s.Rules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+		if len(rule.When) > 0 && s.JWT == nil {
+			return errors.New("jwt must be set when a rule matches claims")
+		}
+	}
+
+	return nil
+}
+
+func (r AuthorizationRule) validate() error {
+	if r.From == nil && r.To == nil && len(r.When) == 0 {
+		return errors.New("rule must set at least one of from, to, or when")
+	}
+	if r.From != nil {
+		for _, principal := range r.From.Principals {
+			if !strings.HasPrefix(principal, spiffeScheme) {
+				return fmt.Errorf("principal '%s' must be a SPIFFE ID", principal)
+			}
+		}
+	}
+	if r.To != nil {
+		for _, method := range r.To.Methods {
+			if _, ok := httpMethods[method]; !ok {
+				return fmt.Errorf("'%s' is not a valid HTTP method", method)
+			}
+		}
+		for _, path := range r.To.Paths {
+			if !strings.HasPrefix(path, "/") || strings.Contains(strings.TrimSuffix(path, "*"), "*") {
+				return fmt.Errorf("path '%s' must begin with '/' and can only end with '*'", path)
+			}
+		}
+	}
+	for _, cond := range r.When {
+		if cond.Claim == "" {
+			return errors.New("claim cannot be empty")
+		}
+		if len(cond.Values) == 0 {
+			return fmt.Errorf("claim '%s' must have at least one value", cond.Claim)
+		}
+	}
+
+	return nil
+}
+
+func (j JWTValidation) validate() error {
+	if j.Issuer == "" {
+		return errors.New("jwt issuer cannot be empty")
+	}
+	u, err := url.Parse(j.JWKSURI)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("jwt jwksURI '%s' must be an https URL", j.JWKSURI)
+	}
+
+	return nil
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AuthorizationPolicyList satisfies K8s code gen requirements.
+type AuthorizationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []AuthorizationPolicy `json:"items"`
+}
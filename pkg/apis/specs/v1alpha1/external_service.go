@@ -0,0 +1,184 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ExternalServiceProtocol is the protocol of an ExternalService port.
+type ExternalServiceProtocol string
+
+// Protocols of an ExternalService port.
+const (
+	ExternalServiceProtocolHTTP  ExternalServiceProtocol = "HTTP"
+	ExternalServiceProtocolHTTPS ExternalServiceProtocol = "HTTPS"
+	ExternalServiceProtocolTCP   ExternalServiceProtocol = "TCP"
+	ExternalServiceProtocolTLS   ExternalServiceProtocol = "TLS"
+)
+
+var externalServiceProtocols = map[ExternalServiceProtocol]struct{}{
+	ExternalServiceProtocolHTTP:  {},
+	ExternalServiceProtocolHTTPS: {},
+	ExternalServiceProtocolTCP:   {},
+	ExternalServiceProtocolTLS:   {},
+}
+
+const wildcardPrefix = "*."
+
+// ExportToAll exports an ExternalService to the workloads of all namespaces.
+const ExportToAll = "*"
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ExternalService registers hosts outside of the mesh that workloads are allowed to send traffic to.
+type ExternalService struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the hosts and ports of the external service
+	Spec ExternalServiceSpec `json:"spec"`
+}
+
+// ExternalServiceSpec defines the hosts and ports of an external service.
+type ExternalServiceSpec struct {
+	// Hosts is a list of DNS names of the external service.
+	// A name can start with a wildcard label, i.e. *.example.com.
+	Hosts []string `json:"hosts"`
+
+	// Ports is a list of ports of the external service.
+	Ports []ExternalServicePort `json:"ports"`
+
+	// ExportTo is a list of namespaces whose workloads are allowed to reach the external service,
+	// in addition to the workloads of the namespace of the ExternalService. "*" exports it to all namespaces.
+	// +optional
+	ExportTo []string `json:"exportTo,omitempty"`
+}
+
+// ExternalServicePort defines a port of an external service.
+type ExternalServicePort struct {
+	// TLS configures the sidecar to originate TLS to the port.
+	// Only HTTP ports can originate TLS.
+	// +optional
+	TLS *TLSOrigination `json:"tls,omitempty"`
+
+	// Name of the port.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Protocol of the port: HTTP, HTTPS, TCP, or TLS.
+	Protocol ExternalServiceProtocol `json:"protocol"`
+
+	// Number of the port.
+	Number int32 `json:"number"`
+}
+
+// TLSOrigination defines how the sidecar originates TLS to an external service.
+type TLSOrigination struct {
+	// SNI is the server name sent in the TLS handshake.
+	// If not set, the host of the request is used.
+	// +optional
+	SNI string `json:"sni,omitempty"`
+
+	// CACertificateSecret is the name of a Secret with the CA bundle, in the ca.crt key,
+	// used to verify the certificate of the external service.
+	// If not set, the certificate of the external service is verified with the system root CAs.
+	// +optional
+	CACertificateSecret string `json:"caCertificateSecret,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the certificate of the external service.
+	// Cannot be set with CACertificateSecret.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// Validate returns an error if the ExternalServiceSpec is not valid.
+func (s ExternalServiceSpec) Validate() error {
+	if len(s.Hosts) == 0 {
+		return errors.New("at least one host must be set")
+	}
+	for _, host := range s.Hosts {
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(host, wildcardPrefix)); len(errs) > 0 {
+			return fmt.Errorf("host '%s' is not a valid DNS name: %s", host, strings.Join(errs, ", "))
+		}
+	}
+	if len(s.Ports) == 0 {
+		return errors.New("at least one port must be set")
+	}
+	ports := make(map[int32]struct{}, len(s.Ports))
+	for _, port := range s.Ports {
+		if err := port.validate(); err != nil {
+			return err
+		}
+		if _, ok := ports[port.Number]; ok {
+			return fmt.Errorf("port %d is set more than once", port.Number)
+		}
+		ports[port.Number] = struct{}{}
+	}
+	for _, namespace := range s.ExportTo {
+		if namespace == ExportToAll {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("exportTo '%s' is not a valid namespace: %s", namespace, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+func (p ExternalServicePort) validate() error {
+	if p.Number < 1 || p.Number > maxPort {
+		return fmt.Errorf("'%d' is not a valid port", p.Number)
+	}
+	if _, ok := externalServiceProtocols[p.Protocol]; !ok {
+		return fmt.Errorf("'%s' is not a valid protocol for port %d", p.Protocol, p.Number)
+	}
+	if p.TLS != nil {
+		if p.Protocol != ExternalServiceProtocolHTTP {
+			return fmt.Errorf("port %d must use the %s protocol to originate TLS", p.Number, ExternalServiceProtocolHTTP)
+		}
+		if err := p.TLS.Validate(); err != nil {
+			return fmt.Errorf("invalid tls for port %d: %w", p.Number, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate returns an error if the TLSOrigination is not valid.
+func (t TLSOrigination) Validate() error {
+	if t.SNI != "" {
+		if errs := validation.IsDNS1123Subdomain(t.SNI); len(errs) > 0 {
+			return fmt.Errorf("sni '%s' is not a valid DNS name: %s", t.SNI, strings.Join(errs, ", "))
+		}
+	}
+	if t.InsecureSkipVerify && t.CACertificateSecret != "" {
+		return errors.New("insecureSkipVerify cannot be set with caCertificateSecret")
+	}
+	if name := t.CACertificateSecret; name != "" {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("caCertificateSecret '%s' is not a valid Secret name: %s", name, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ExternalServiceList satisfies K8s code gen requirements.
+type ExternalServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ExternalService `json:"items"`
+}
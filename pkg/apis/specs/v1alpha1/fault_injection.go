@@ -0,0 +1,117 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	minAbortStatus = 400
+	maxAbortStatus = 599
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FaultInjection delays or aborts a percentage of the requests sent to a destination.
+// It is intended for testing the resilience of applications in the mesh.
+type FaultInjection struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the faults injected into a destination's traffic
+	Spec FaultInjectionSpec `json:"spec"`
+}
+
+// FaultInjectionSpec defines the faults that are injected into matching requests.
+type FaultInjectionSpec struct {
+	// Destination is the resource whose requests should have faults injected
+	Destination v1.ObjectReference `json:"destination"`
+
+	// Sources defines from where traffic should have faults injected
+	// +optional
+	Sources []v1.ObjectReference `json:"sources,omitempty"`
+
+	// Rules allows defining a list of HTTP Route Groups that this fault injection
+	// object should match.
+	// +optional
+	Rules []RouteRule `json:"rules,omitempty"`
+
+	// Delay adds latency to a percentage of requests.
+	// +optional
+	Delay *FaultDelay `json:"delay,omitempty"`
+
+	// Abort fails a percentage of requests with a status code.
+	// +optional
+	Abort *FaultAbort `json:"abort,omitempty"`
+}
+
+// FaultDelay defines the latency to inject into requests.
+type FaultDelay struct {
+	// Percent of requests to delay.
+	Percent int `json:"percent"`
+
+	// FixedDelay is the amount of time to delay a request, i.e. 5s
+	FixedDelay string `json:"fixedDelay"`
+}
+
+// FaultAbort defines the error to return for aborted requests.
+type FaultAbort struct {
+	// Percent of requests to abort.
+	Percent int `json:"percent"`
+
+	// HTTPStatus is the status code returned for aborted requests.
+	HTTPStatus int `json:"httpStatus"`
+}
+
+// Validate returns an error if the FaultInjectionSpec is not valid.
+func (s FaultInjectionSpec) Validate() error {
+	if err := validateDestination(s.Destination); err != nil {
+		return err
+	}
+	if err := validateRules(s.Rules); err != nil {
+		return err
+	}
+	if s.Delay == nil && s.Abort == nil {
+		return errors.New("at least one of delay or abort must be set")
+	}
+	if s.Delay != nil {
+		if err := validatePercent("delay", s.Delay.Percent); err != nil {
+			return err
+		}
+		if s.Delay.FixedDelay == "" {
+			return errors.New("delay fixedDelay must be set")
+		}
+		if err := ValidateDuration("delay fixedDelay", s.Delay.FixedDelay); err != nil {
+			return err
+		}
+	}
+	if s.Abort != nil {
+		if err := validatePercent("abort", s.Abort.Percent); err != nil {
+			return err
+		}
+		if s.Abort.HTTPStatus < minAbortStatus || s.Abort.HTTPStatus > maxAbortStatus {
+			return fmt.Errorf("abort httpStatus must be between %d and %d, got %d",
+				minAbortStatus, maxAbortStatus, s.Abort.HTTPStatus)
+		}
+	}
+
+	return nil
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FaultInjectionList satisfies K8s code gen requirements.
+type FaultInjectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []FaultInjection `json:"items"`
+}
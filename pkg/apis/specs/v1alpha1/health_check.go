@@ -0,0 +1,118 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// statusRegexp matches a single status code, i.e. 200, or a range, i.e. 200-399.
+var statusRegexp = regexp.MustCompile(`^[1-5][0-9]{2}(-[1-5][0-9]{2})?$`)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HealthCheck actively probes the endpoints of a Service and stops sending traffic
+// to the endpoints that fail.
+type HealthCheck struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the health check for a destination's endpoints
+	Spec HealthCheckSpec `json:"spec"`
+}
+
+// HealthCheckSpec defines how the endpoints of the destination are probed.
+type HealthCheckSpec struct {
+	// Destination is the Service whose endpoints are health checked
+	Destination v1.ObjectReference `json:"destination"`
+
+	// Port is the port to send health checks to. Defaults to the port of the upstream server.
+	// +optional
+	Port int `json:"port,omitempty"`
+
+	// Path is the request path of the health check. Defaults to /.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Interval is the time between two health checks, i.e. 5s. Defaults to 5s.
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// ExpectedStatus is the status code, i.e. 200, or range of status codes, i.e. 200-399,
+	// of a healthy response. Defaults to 200-399.
+	// +optional
+	ExpectedStatus string `json:"expectedStatus,omitempty"`
+
+	// Passes is the number of consecutive passed health checks before an endpoint
+	// is considered healthy. Defaults to 1.
+	// +optional
+	Passes int `json:"passes,omitempty"`
+
+	// Fails is the number of consecutive failed health checks before an endpoint
+	// is considered unhealthy. Defaults to 1.
+	// +optional
+	Fails int `json:"fails,omitempty"`
+}
+
+// Validate returns an error if the HealthCheckSpec is not valid.
+func (s HealthCheckSpec) Validate() error {
+	if err := validateDestination(s.Destination); err != nil {
+		return err
+	}
+	if s.Destination.Kind != "Service" {
+		return fmt.Errorf("destination kind must be Service, got '%s'", s.Destination.Kind)
+	}
+	if s.Port < 0 || s.Port > maxPort {
+		return fmt.Errorf("'%d' is not a valid port", s.Port)
+	}
+	if s.Path != "" && !strings.HasPrefix(s.Path, "/") {
+		return errors.New("path must begin with '/'")
+	}
+	if err := ValidateDuration("interval", s.Interval); err != nil {
+		return err
+	}
+	if s.Passes < 0 || s.Fails < 0 {
+		return errors.New("passes and fails cannot be negative")
+	}
+
+	return validateStatus(s.ExpectedStatus)
+}
+
+func validateStatus(status string) error {
+	if status == "" {
+		return nil
+	}
+	if !statusRegexp.MatchString(status) {
+		return fmt.Errorf("expectedStatus '%s' must be a status code or range, i.e. 200-399", status)
+	}
+	if low, high, found := strings.Cut(status, "-"); found {
+		// both values are guaranteed to be numbers by the regexp
+		l, _ := strconv.Atoi(low)
+		h, _ := strconv.Atoi(high)
+		if l > h {
+			return fmt.Errorf("expectedStatus range '%s' is reversed", status)
+		}
+	}
+
+	return nil
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HealthCheckList satisfies K8s code gen requirements.
+type HealthCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []HealthCheck `json:"items"`
+}
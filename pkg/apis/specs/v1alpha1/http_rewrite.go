@@ -0,0 +1,146 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HTTPRewrite modifies the path and headers of requests sent to a destination,
+// and the headers of the responses.
+type HTTPRewrite struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the rewrites applied to a destination's traffic
+	Spec HTTPRewriteSpec `json:"spec"`
+}
+
+// HTTPRewriteSpec defines the rewrites applied to matching requests.
+type HTTPRewriteSpec struct {
+	// Destination is the resource whose requests should be rewritten
+	Destination v1.ObjectReference `json:"destination"`
+
+	// Rules allows defining a list of HTTP Route Groups that this rewrite
+	// object should match.
+	// +optional
+	Rules []RouteRule `json:"rules,omitempty"`
+
+	// Path rewrites the path of the request.
+	// +optional
+	Path *PathRewrite `json:"path,omitempty"`
+
+	// RequestHeaders modifies the headers of the request.
+	// +optional
+	RequestHeaders *HeaderModifier `json:"requestHeaders,omitempty"`
+
+	// ResponseHeaders modifies the headers of the response.
+	// +optional
+	ResponseHeaders *HeaderModifier `json:"responseHeaders,omitempty"`
+}
+
+// PathRewrite replaces the matched prefix of the request path.
+type PathRewrite struct {
+	// Prefix is the path prefix to replace, i.e. /api/v1
+	Prefix string `json:"prefix"`
+
+	// ReplacePrefix is the value that replaces the prefix, i.e. /v1
+	ReplacePrefix string `json:"replacePrefix"`
+}
+
+// HeaderModifier defines the headers to set, add, or remove.
+type HeaderModifier struct {
+	// Set overwrites the values of the headers.
+	// +optional
+	Set map[string]string `json:"set,omitempty"`
+
+	// Add appends values to the headers.
+	// +optional
+	Add map[string]string `json:"add,omitempty"`
+
+	// Remove deletes the headers.
+	// +optional
+	Remove []string `json:"remove,omitempty"`
+}
+
+// Validate returns an error if the HTTPRewriteSpec is not valid.
+func (s HTTPRewriteSpec) Validate() error {
+	if err := validateDestination(s.Destination); err != nil {
+		return err
+	}
+	if err := validateRules(s.Rules); err != nil {
+		return err
+	}
+	if s.Path == nil && s.RequestHeaders == nil && s.ResponseHeaders == nil {
+		return errors.New("at least one of path, requestHeaders, or responseHeaders must be set")
+	}
+	if s.Path != nil {
+		if !strings.HasPrefix(s.Path.Prefix, "/") || !strings.HasPrefix(s.Path.ReplacePrefix, "/") {
+			return errors.New("path prefix and replacePrefix must begin with '/'")
+		}
+	}
+	if err := s.RequestHeaders.validate(); err != nil {
+		return fmt.Errorf("invalid requestHeaders: %w", err)
+	}
+	if err := s.ResponseHeaders.validate(); err != nil {
+		return fmt.Errorf("invalid responseHeaders: %w", err)
+	}
+
+	return nil
+}
+
+func (h *HeaderModifier) validate() error {
+	if h == nil {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	check := func(name string) error {
+		if name == "" || strings.ContainsAny(name, " :\t") {
+			return fmt.Errorf("'%s' is not a valid header name", name)
+		}
+		key := strings.ToLower(name)
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf("header '%s' is modified more than once", name)
+		}
+		seen[key] = struct{}{}
+
+		return nil
+	}
+	for name := range h.Set {
+		if err := check(name); err != nil {
+			return err
+		}
+	}
+	for name := range h.Add {
+		if err := check(name); err != nil {
+			return err
+		}
+	}
+	for _, name := range h.Remove {
+		if err := check(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HTTPRewriteList satisfies K8s code gen requirements.
+type HTTPRewriteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []HTTPRewrite `json:"items"`
+}
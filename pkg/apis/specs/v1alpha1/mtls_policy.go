@@ -0,0 +1,93 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// mtlsModes are the mTLS modes that an MTLSPolicy can set.
+// These match the mesh-wide mTLS modes.
+var mtlsModes = map[string]struct{}{
+	"off":        {},
+	"permissive": {},
+	"strict":     {},
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MTLSPolicy sets the mTLS mode of the workloads in its namespace, overriding the mesh-wide mTLS mode.
+type MTLSPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the mTLS mode of the selected workloads
+	Spec MTLSPolicySpec `json:"spec"`
+}
+
+// MTLSPolicySpec defines the mTLS mode of a namespace or of the workloads selected in it.
+type MTLSPolicySpec struct {
+	// Selector selects the Pods that the policy applies to.
+	// If not set, the policy applies to all Pods in the namespace.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Mode is the mTLS mode of the selected Pods: off, permissive, or strict.
+	Mode string `json:"mode"`
+
+	// PortModes overrides the mode for specific container ports of the selected Pods.
+	// +optional
+	PortModes []PortMTLSMode `json:"portModes,omitempty"`
+}
+
+// PortMTLSMode sets the mTLS mode of a single container port.
+type PortMTLSMode struct {
+	// Port is the container port.
+	Port int32 `json:"port"`
+
+	// Mode is the mTLS mode of the port: off, permissive, or strict.
+	Mode string `json:"mode"`
+}
+
+// Validate returns an error if the MTLSPolicySpec is not valid.
+func (s MTLSPolicySpec) Validate() error {
+	if _, ok := mtlsModes[s.Mode]; !ok {
+		return fmt.Errorf("'%s' is not a valid mTLS mode", s.Mode)
+	}
+	if s.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(s.Selector); err != nil {
+			return fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+	ports := make(map[int32]struct{}, len(s.PortModes))
+	for _, portMode := range s.PortModes {
+		if portMode.Port < 1 || portMode.Port > maxPort {
+			return fmt.Errorf("'%d' is not a valid port", portMode.Port)
+		}
+		if _, ok := mtlsModes[portMode.Mode]; !ok {
+			return fmt.Errorf("'%s' is not a valid mTLS mode for port %d", portMode.Mode, portMode.Port)
+		}
+		if _, ok := ports[portMode.Port]; ok {
+			return errors.New("a port can only have one mode")
+		}
+		ports[portMode.Port] = struct{}{}
+	}
+
+	return nil
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MTLSPolicyList satisfies K8s code gen requirements.
+type MTLSPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []MTLSPolicy `json:"items"`
+}
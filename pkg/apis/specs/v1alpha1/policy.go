@@ -0,0 +1,66 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// RouteRule is the TrafficSpec that applies to a traffic policy.
+type RouteRule struct {
+	// Kind is the kind of TrafficSpec to allow.
+	Kind string `json:"kind"`
+	// Name of the TrafficSpec to use.
+	Name string `json:"name"`
+	// Matches is a list of TrafficSpec routes that are applied to the policy.
+	// +optional
+	Matches []string `json:"matches,omitempty"`
+}
+
+// HTTPRouteGroupKind is the only TrafficSpec kind supported in policy rules.
+const HTTPRouteGroupKind = "HTTPRouteGroup"
+
+const maxPercent = 100
+
+// durationRegexp matches an NGINX time value, i.e. 500ms, 10s, 1m.
+var durationRegexp = regexp.MustCompile(`^[0-9]+(ms|s|m|h)$`)
+
+func validateDestination(dest v1.ObjectReference) error {
+	if dest.Name == "" || dest.Kind == "" {
+		return errors.New("destination must have a name and kind")
+	}
+
+	return nil
+}
+
+func validateRules(rules []RouteRule) error {
+	for _, rule := range rules {
+		if rule.Kind != HTTPRouteGroupKind {
+			return fmt.Errorf("rule kind must be %s, got '%s'", HTTPRouteGroupKind, rule.Kind)
+		}
+		if rule.Name == "" {
+			return errors.New("rule name cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+// ValidateDuration returns an error if a non-empty value is not a valid NGINX time, i.e. 500ms, 10s, 1m.
+func ValidateDuration(field, value string) error {
+	if value != "" && !durationRegexp.MatchString(value) {
+		return fmt.Errorf("%s '%s' is not a valid duration, i.e. 10s", field, value)
+	}
+
+	return nil
+}
+
+func validatePercent(field string, percent int) error {
+	if percent < 0 || percent > maxPercent {
+		return fmt.Errorf("%s percent must be between 0 and %d, got %d", field, maxPercent, percent)
+	}
+
+	return nil
+}
@@ -0,0 +1,449 @@
+package v1alpha1_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+)
+
+var _ = Describe("Policy validation", func() {
+	dest := v1.ObjectReference{Kind: "Service", Name: "dest-svc", Namespace: "default"}
+	rules := []specs.RouteRule{{Kind: specs.HTTPRouteGroupKind, Name: "hrg", Matches: []string{"get"}}}
+
+	Context("RetryPolicy", func() {
+		var spec specs.RetryPolicySpec
+
+		BeforeEach(func() {
+			spec = specs.RetryPolicySpec{
+				Destination:   dest,
+				Rules:         rules,
+				RetryOn:       []specs.RetryOn{specs.RetryOnError, specs.RetryOnHTTP503},
+				Attempts:      3,
+				PerTryTimeout: "500ms",
+				Timeout:       "10s",
+				Budget:        &specs.RetryBudget{Percent: 20, MinRetriesPerSecond: 5},
+			}
+		})
+
+		It("is valid", func() {
+			Expect(spec.Validate()).To(Succeed())
+		})
+
+		It("requires a destination", func() {
+			spec.Destination = v1.ObjectReference{}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires at least one attempt", func() {
+			spec.Attempts = 0
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects unknown retryOn conditions", func() {
+			spec.RetryOn = append(spec.RetryOn, "http_418")
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects invalid durations", func() {
+			spec.PerTryTimeout = "5 seconds"
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects an out of range budget", func() {
+			spec.Budget.Percent = 101
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects rules that are not HTTPRouteGroups", func() {
+			spec.Rules = []specs.RouteRule{{Kind: "TCPRoute", Name: "tcp"}}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("TimeoutPolicy", func() {
+		It("is valid", func() {
+			spec := specs.TimeoutPolicySpec{Destination: dest, Rules: rules, ConnectTimeout: "1s", ReadTimeout: "30s"}
+			Expect(spec.Validate()).To(Succeed())
+		})
+
+		It("requires at least one timeout", func() {
+			spec := specs.TimeoutPolicySpec{Destination: dest}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects invalid durations", func() {
+			spec := specs.TimeoutPolicySpec{Destination: dest, SendTimeout: "-1s"}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("FaultInjection", func() {
+		var spec specs.FaultInjectionSpec
+
+		BeforeEach(func() {
+			spec = specs.FaultInjectionSpec{
+				Destination: dest,
+				Rules:       rules,
+				Delay:       &specs.FaultDelay{Percent: 50, FixedDelay: "3s"},
+				Abort:       &specs.FaultAbort{Percent: 10, HTTPStatus: 503},
+			}
+		})
+
+		It("is valid", func() {
+			Expect(spec.Validate()).To(Succeed())
+		})
+
+		It("requires a delay or an abort", func() {
+			spec.Delay = nil
+			spec.Abort = nil
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires a valid fixed delay", func() {
+			spec.Delay.FixedDelay = ""
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Delay.FixedDelay = "3"
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects an out of range percent", func() {
+			spec.Abort.Percent = -1
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects a non-error abort status", func() {
+			spec.Abort.HTTPStatus = 200
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("TrafficMirror", func() {
+		var spec specs.TrafficMirrorSpec
+
+		BeforeEach(func() {
+			percent := 20
+			spec = specs.TrafficMirrorSpec{
+				Destination: dest,
+				Rules:       rules,
+				Mirror:      specs.MirrorBackend{Service: "dest-svc-canary", Port: 8080},
+				Percent:     &percent,
+			}
+		})
+
+		It("is valid", func() {
+			Expect(spec.Validate()).To(Succeed())
+		})
+
+		It("requires a mirror service other than the destination", func() {
+			spec.Mirror.Service = ""
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Mirror.Service = dest.Name
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects an out of range percent", func() {
+			percent := 101
+			spec.Percent = &percent
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("HTTPRewrite", func() {
+		var spec specs.HTTPRewriteSpec
+
+		BeforeEach(func() {
+			spec = specs.HTTPRewriteSpec{
+				Destination: dest,
+				Path:        &specs.PathRewrite{Prefix: "/api/v1", ReplacePrefix: "/v1"},
+				RequestHeaders: &specs.HeaderModifier{
+					Set:    map[string]string{"X-Env": "canary"},
+					Remove: []string{"X-Debug"},
+				},
+			}
+		})
+
+		It("is valid", func() {
+			Expect(spec.Validate()).To(Succeed())
+		})
+
+		It("requires a rewrite", func() {
+			spec.Path = nil
+			spec.RequestHeaders = nil
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires absolute path prefixes", func() {
+			spec.Path.ReplacePrefix = "v1"
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects a header that is modified more than once", func() {
+			spec.RequestHeaders.Remove = append(spec.RequestHeaders.Remove, "x-env")
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects invalid header names", func() {
+			spec.ResponseHeaders = &specs.HeaderModifier{Add: map[string]string{"bad header": "v"}}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("HealthCheck", func() {
+		var spec specs.HealthCheckSpec
+
+		BeforeEach(func() {
+			spec = specs.HealthCheckSpec{
+				Destination:    dest,
+				Path:           "/healthz",
+				Interval:       "10s",
+				ExpectedStatus: "200-299",
+				Passes:         2,
+				Fails:          3,
+			}
+		})
+
+		It("is valid", func() {
+			Expect(spec.Validate()).To(Succeed())
+
+			spec.ExpectedStatus = "204"
+			Expect(spec.Validate()).To(Succeed())
+		})
+
+		It("requires a Service destination", func() {
+			spec.Destination.Kind = "Deployment"
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires an absolute path", func() {
+			spec.Path = "healthz"
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects invalid expected statuses", func() {
+			spec.ExpectedStatus = "2xx"
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.ExpectedStatus = "399-200"
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects negative thresholds", func() {
+			spec.Fails = -1
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("AuthorizationPolicy", func() {
+		var spec specs.AuthorizationPolicySpec
+
+		BeforeEach(func() {
+			spec = specs.AuthorizationPolicySpec{
+				Destination: dest,
+				Action:      specs.AuthorizationActionAllow,
+				Rules: []specs.AuthorizationRule{
+					{
+						From: &specs.AuthorizationSource{
+							Principals: []string{"spiffe://example.org/ns/default/sa/client"},
+							Namespaces: []string{"default"},
+						},
+						To: &specs.AuthorizationOperation{
+							Methods: []string{"GET", "POST"},
+							Paths:   []string{"/api/*", "/healthz"},
+						},
+						When: []specs.ClaimCondition{{Claim: "groups", Values: []string{"admin"}}},
+					},
+				},
+				JWT: &specs.JWTValidation{
+					Issuer:  "https://issuer.example.com",
+					JWKSURI: "https://issuer.example.com/.well-known/jwks.json",
+				},
+			}
+		})
+
+		It("is valid", func() {
+			Expect(spec.Validate()).To(Succeed())
+
+			spec.Action = specs.AuthorizationActionDeny
+			Expect(spec.Validate()).To(Succeed())
+		})
+
+		It("rejects an invalid action", func() {
+			spec.Action = "AUDIT"
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires a non-empty rule", func() {
+			spec.Rules = nil
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Rules = []specs.AuthorizationRule{{}}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires SPIFFE ID principals", func() {
+			spec.Rules[0].From.Principals = []string{"default/client"}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects invalid methods and paths", func() {
+			spec.Rules[0].To.Methods = []string{"get"}
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Rules[0].To.Methods = nil
+			spec.Rules[0].To.Paths = []string{"api/*"}
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Rules[0].To.Paths = []string{"/api/*/users"}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires jwt validation when matching claims", func() {
+			spec.JWT = nil
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects invalid claim conditions", func() {
+			spec.Rules[0].When = []specs.ClaimCondition{{Claim: "groups"}}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires an https jwks URI", func() {
+			spec.JWT.JWKSURI = "http://issuer.example.com/jwks.json"
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.JWT.JWKSURI = "https://issuer.example.com/jwks.json"
+			spec.JWT.Issuer = ""
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("MTLSPolicy", func() {
+		var spec specs.MTLSPolicySpec
+
+		BeforeEach(func() {
+			spec = specs.MTLSPolicySpec{
+				Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "backend"}},
+				Mode:      "strict",
+				PortModes: []specs.PortMTLSMode{{Port: 9090, Mode: "permissive"}},
+			}
+		})
+
+		It("is valid", func() {
+			Expect(spec.Validate()).To(Succeed())
+
+			spec.Selector = nil
+			Expect(spec.Validate()).To(Succeed())
+		})
+
+		It("rejects an invalid mode", func() {
+			spec.Mode = "STRICT"
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Mode = "strict"
+			spec.PortModes[0].Mode = "none"
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects an invalid selector", func() {
+			spec.Selector.MatchExpressions = []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Like"}}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects invalid and duplicate ports", func() {
+			spec.PortModes[0].Port = 0
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.PortModes[0].Port = 65536
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.PortModes = []specs.PortMTLSMode{{Port: 9090, Mode: "off"}, {Port: 9090, Mode: "strict"}}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("ExternalService", func() {
+		var spec specs.ExternalServiceSpec
+
+		BeforeEach(func() {
+			spec = specs.ExternalServiceSpec{
+				Hosts: []string{"api.example.com", "*.example.org"},
+				Ports: []specs.ExternalServicePort{
+					{Number: 443, Protocol: specs.ExternalServiceProtocolHTTPS},
+					{Number: 80, Protocol: specs.ExternalServiceProtocolHTTP, TLS: &specs.TLSOrigination{SNI: "api.example.com"}},
+				},
+			}
+		})
+
+		It("is valid", func() {
+			Expect(spec.Validate()).To(Succeed())
+		})
+
+		It("requires valid hosts", func() {
+			spec.Hosts = nil
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Hosts = []string{"api.*.example.com"}
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Hosts = []string{"https://api.example.com"}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires valid and unique ports", func() {
+			spec.Ports = nil
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Ports = []specs.ExternalServicePort{{Number: 0, Protocol: specs.ExternalServiceProtocolTCP}}
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Ports = []specs.ExternalServicePort{{Number: 443, Protocol: "UDP"}}
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Ports = []specs.ExternalServicePort{
+				{Number: 443, Protocol: specs.ExternalServiceProtocolTLS},
+				{Number: 443, Protocol: specs.ExternalServiceProtocolHTTPS},
+			}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires valid namespaces to export to", func() {
+			spec.ExportTo = []string{"prod", specs.ExportToAll}
+			Expect(spec.Validate()).To(Succeed())
+
+			spec.ExportTo = []string{"Prod"}
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.ExportTo = []string{"*.prod"}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("only originates TLS for HTTP ports", func() {
+			spec.Ports[0].TLS = &specs.TLSOrigination{}
+			Expect(spec.Validate()).ToNot(Succeed())
+		})
+
+		It("requires valid TLS origination settings", func() {
+			spec.Ports[1].TLS = &specs.TLSOrigination{CACertificateSecret: "example-ca"}
+			Expect(spec.Validate()).To(Succeed())
+
+			spec.Ports[1].TLS.SNI = "*.example.com"
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Ports[1].TLS.SNI = ""
+			spec.Ports[1].TLS.CACertificateSecret = "Example_CA"
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Ports[1].TLS.CACertificateSecret = "example-ca"
+			spec.Ports[1].TLS.InsecureSkipVerify = true
+			Expect(spec.Validate()).ToNot(Succeed())
+
+			spec.Ports[1].TLS.CACertificateSecret = ""
+			Expect(spec.Validate()).To(Succeed())
+		})
+	})
+})
@@ -41,10 +41,28 @@ var (
 // Adds the list of known types to Scheme.
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
+		&AuthorizationPolicy{},
+		&AuthorizationPolicyList{},
 		&CircuitBreaker{},
 		&CircuitBreakerList{},
+		&ExternalService{},
+		&ExternalServiceList{},
+		&FaultInjection{},
+		&FaultInjectionList{},
+		&HealthCheck{},
+		&HealthCheckList{},
+		&HTTPRewrite{},
+		&HTTPRewriteList{},
+		&MTLSPolicy{},
+		&MTLSPolicyList{},
 		&RateLimit{},
 		&RateLimitList{},
+		&RetryPolicy{},
+		&RetryPolicyList{},
+		&TimeoutPolicy{},
+		&TimeoutPolicyList{},
+		&TrafficMirror{},
+		&TrafficMirrorList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 
@@ -0,0 +1,147 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RetryOn is a condition under which a request is retried on the next upstream.
+type RetryOn string
+
+// Conditions that can trigger a retry. These map to the parameters of the NGINX
+// proxy_next_upstream directive.
+const (
+	RetryOnError         RetryOn = "error"
+	RetryOnTimeout       RetryOn = "timeout"
+	RetryOnInvalidHeader RetryOn = "invalid_header"
+	RetryOnHTTP500       RetryOn = "http_500"
+	RetryOnHTTP502       RetryOn = "http_502"
+	RetryOnHTTP503       RetryOn = "http_503"
+	RetryOnHTTP504       RetryOn = "http_504"
+	RetryOnHTTP403       RetryOn = "http_403"
+	RetryOnHTTP404       RetryOn = "http_404"
+	RetryOnHTTP429       RetryOn = "http_429"
+	RetryOnNonIdempotent RetryOn = "non_idempotent"
+)
+
+var retryOnConditions = map[RetryOn]struct{}{
+	RetryOnError:         {},
+	RetryOnTimeout:       {},
+	RetryOnInvalidHeader: {},
+	RetryOnHTTP500:       {},
+	RetryOnHTTP502:       {},
+	RetryOnHTTP503:       {},
+	RetryOnHTTP504:       {},
+	RetryOnHTTP403:       {},
+	RetryOnHTTP404:       {},
+	RetryOnHTTP429:       {},
+	RetryOnNonIdempotent: {},
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RetryPolicy defines how failed requests to a destination are retried.
+type RetryPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the retry policy for a destination's traffic
+	Spec RetryPolicySpec `json:"spec"`
+}
+
+// RetryPolicySpec defines the conditions and limits for retrying requests.
+type RetryPolicySpec struct {
+	// Destination is the resource whose requests should be retried
+	Destination v1.ObjectReference `json:"destination"`
+
+	// Sources defines from where traffic should be retried
+	// +optional
+	Sources []v1.ObjectReference `json:"sources,omitempty"`
+
+	// Rules allows defining a list of HTTP Route Groups that this retry policy
+	// object should match.
+	// +optional
+	Rules []RouteRule `json:"rules,omitempty"`
+
+	// RetryOn is the list of conditions that trigger a retry. Defaults to error and timeout.
+	// +optional
+	RetryOn []RetryOn `json:"retryOn,omitempty"`
+
+	// Attempts is the maximum number of attempts, including the first request.
+	Attempts int `json:"attempts"`
+
+	// PerTryTimeout is the timeout for each attempt, i.e. 5s
+	// +optional
+	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+
+	// Timeout limits the total time spent retrying a request, i.e. 30s
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	// Budget limits the share of requests to the destination that can be retries.
+	// +optional
+	Budget *RetryBudget `json:"budget,omitempty"`
+}
+
+// RetryBudget limits retries so they cannot overwhelm a destination.
+type RetryBudget struct {
+	// Percent of active requests that are allowed to be retries.
+	Percent int `json:"percent"`
+
+	// MinRetriesPerSecond is the number of retries allowed per second
+	// regardless of Percent.
+	// +optional
+	MinRetriesPerSecond int `json:"minRetriesPerSecond,omitempty"`
+}
+
+// Validate returns an error if the RetryPolicySpec is not valid.
+func (s RetryPolicySpec) Validate() error {
+	if err := validateDestination(s.Destination); err != nil {
+		return err
+	}
+	if err := validateRules(s.Rules); err != nil {
+		return err
+	}
+	if s.Attempts < 1 {
+		return fmt.Errorf("attempts must be greater than 0, got %d", s.Attempts)
+	}
+	for _, cond := range s.RetryOn {
+		if _, ok := retryOnConditions[cond]; !ok {
+			return fmt.Errorf("'%s' is not a valid retryOn condition", cond)
+		}
+	}
+	if err := ValidateDuration("perTryTimeout", s.PerTryTimeout); err != nil {
+		return err
+	}
+	if err := ValidateDuration("timeout", s.Timeout); err != nil {
+		return err
+	}
+	if s.Budget != nil {
+		if err := validatePercent("budget", s.Budget.Percent); err != nil {
+			return err
+		}
+		if s.Budget.MinRetriesPerSecond < 0 {
+			return errors.New("budget minRetriesPerSecond cannot be negative")
+		}
+	}
+
+	return nil
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RetryPolicyList satisfies K8s code gen requirements.
+type RetryPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []RetryPolicy `json:"items"`
+}
@@ -0,0 +1,83 @@
+package v1alpha1
+
+import (
+	"errors"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TimeoutPolicy sets the timeouts applied to requests sent to a destination.
+type TimeoutPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the timeout policy for a destination's traffic
+	Spec TimeoutPolicySpec `json:"spec"`
+}
+
+// TimeoutPolicySpec defines the timeouts for requests to the destination.
+// Timeouts are NGINX time values, i.e. 500ms or 10s.
+type TimeoutPolicySpec struct {
+	// Destination is the resource to which the timeouts apply
+	Destination v1.ObjectReference `json:"destination"`
+
+	// Sources defines from where traffic should be subject to the timeouts
+	// +optional
+	Sources []v1.ObjectReference `json:"sources,omitempty"`
+
+	// Rules allows defining a list of HTTP Route Groups that this timeout policy
+	// object should match.
+	// +optional
+	Rules []RouteRule `json:"rules,omitempty"`
+
+	// ConnectTimeout is the timeout for establishing a connection with the destination.
+	// +optional
+	ConnectTimeout string `json:"connectTimeout,omitempty"`
+
+	// ReadTimeout is the timeout between two successive reads of the response.
+	// +optional
+	ReadTimeout string `json:"readTimeout,omitempty"`
+
+	// SendTimeout is the timeout between two successive writes of the request.
+	// +optional
+	SendTimeout string `json:"sendTimeout,omitempty"`
+}
+
+// Validate returns an error if the TimeoutPolicySpec is not valid.
+func (s TimeoutPolicySpec) Validate() error {
+	if err := validateDestination(s.Destination); err != nil {
+		return err
+	}
+	if err := validateRules(s.Rules); err != nil {
+		return err
+	}
+	if s.ConnectTimeout == "" && s.ReadTimeout == "" && s.SendTimeout == "" {
+		return errors.New("at least one of connectTimeout, readTimeout, or sendTimeout must be set")
+	}
+	if err := ValidateDuration("connectTimeout", s.ConnectTimeout); err != nil {
+		return err
+	}
+	if err := ValidateDuration("readTimeout", s.ReadTimeout); err != nil {
+		return err
+	}
+
+	return ValidateDuration("sendTimeout", s.SendTimeout)
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TimeoutPolicyList satisfies K8s code gen requirements.
+type TimeoutPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TimeoutPolicy `json:"items"`
+}
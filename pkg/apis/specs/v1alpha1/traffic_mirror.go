@@ -0,0 +1,91 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const maxPort = 65535
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficMirror shadows a percentage of the requests sent to a destination to another
+// Service. Responses from the mirror are discarded.
+type TrafficMirror struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines how a destination's traffic is mirrored
+	Spec TrafficMirrorSpec `json:"spec"`
+}
+
+// TrafficMirrorSpec defines which requests are mirrored and where they are sent.
+type TrafficMirrorSpec struct {
+	// Destination is the resource whose requests should be mirrored
+	Destination v1.ObjectReference `json:"destination"`
+
+	// Rules allows defining a list of HTTP Route Groups that this traffic mirror
+	// object should match.
+	// +optional
+	Rules []RouteRule `json:"rules,omitempty"`
+
+	// Mirror is the Service that receives the copies of the requests.
+	Mirror MirrorBackend `json:"mirror"`
+
+	// Percent of requests to mirror. Defaults to 100.
+	// +optional
+	Percent *int `json:"percent,omitempty"`
+}
+
+// MirrorBackend defines the Service that mirrored requests are sent to.
+type MirrorBackend struct {
+	// Service is the name of the Kubernetes Service to send traffic to.
+	// The Service must be in the same namespace as the destination.
+	Service string `json:"service"`
+
+	// Port is the port on the Service to send traffic to. Defaults to 80.
+	// +optional
+	Port int `json:"port,omitempty"`
+}
+
+// Validate returns an error if the TrafficMirrorSpec is not valid.
+func (s TrafficMirrorSpec) Validate() error {
+	if err := validateDestination(s.Destination); err != nil {
+		return err
+	}
+	if err := validateRules(s.Rules); err != nil {
+		return err
+	}
+	if s.Mirror.Service == "" {
+		return errors.New("mirror service must be set")
+	}
+	if s.Mirror.Service == s.Destination.Name {
+		return errors.New("mirror service cannot be the destination")
+	}
+	if s.Mirror.Port < 0 || s.Mirror.Port > maxPort {
+		return fmt.Errorf("'%d' is not a valid mirror port", s.Mirror.Port)
+	}
+	if s.Percent != nil {
+		return validatePercent("mirror", *s.Percent)
+	}
+
+	return nil
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficMirrorList satisfies K8s code gen requirements.
+type TrafficMirrorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TrafficMirror `json:"items"`
+}
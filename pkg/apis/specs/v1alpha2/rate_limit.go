@@ -1,11 +1,38 @@
 package v1alpha2
 
 import (
+	"errors"
+	"fmt"
+
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// RateLimitScope determines where a rate limit is enforced.
+type RateLimitScope string
+
+const (
+	// RateLimitScopeLocal enforces the rate separately in each destination sidecar,
+	// so the total allowed rate scales with the number of replicas.
+	RateLimitScopeLocal RateLimitScope = "local"
+	// RateLimitScopeGlobal shares the rate across all destination sidecars, which
+	// synchronize their rate limit state with each other.
+	RateLimitScopeGlobal RateLimitScope = "global"
+)
+
+// RateLimitKeyType is the attribute of a request that traffic is counted by.
+type RateLimitKeyType string
+
+const (
+	// RateLimitKeyHeader counts requests by the value of a request header.
+	RateLimitKeyHeader RateLimitKeyType = "header"
+	// RateLimitKeyJWTClaim counts requests by the value of a claim in the request's JWT.
+	RateLimitKeyJWTClaim RateLimitKeyType = "jwtClaim"
+	// RateLimitKeySourceIdentity counts requests by the SPIFFE ID of the source.
+	RateLimitKeySourceIdentity RateLimitKeyType = "sourceIdentity"
+)
+
 // +genclient
 // +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -53,6 +80,17 @@ type RateLimitSpec struct {
 	// see: https://www.nginx.com/blog/rate-limiting-nginx/#bursts
 	// +optional
 	Burst int `json:"burst,omitempty"`
+
+	// Scope sets whether the rate is enforced per destination sidecar or shared
+	// across all of them. Defaults to local.
+	// +optional
+	Scope RateLimitScope `json:"scope,omitempty"`
+
+	// Key is the attribute of a request that traffic is counted by. Defaults to the source address.
+	// Applies to all traffic to the destination, or to the traffic of each rule if rules are set.
+	// A rule that sets a key must use the same key.
+	// +optional
+	Key *RateLimitKey `json:"key,omitempty"`
 }
 
 // RateLimitRule is the TrafficSpec that applies to a Rate Limit.
@@ -64,6 +102,84 @@ type RateLimitRule struct {
 	// Matches is a list of TrafficSpec routes that are applied to the Rate Limit object.
 	// +optional
 	Matches []string `json:"matches,omitempty"`
+	// Key is the attribute of a request that traffic is counted by. Defaults to the source address.
+	// +optional
+	Key *RateLimitKey `json:"key,omitempty"`
+}
+
+// RateLimitKey defines the attribute of a request that traffic is counted by.
+type RateLimitKey struct {
+	// Type of the key.
+	Type RateLimitKeyType `json:"type"`
+	// Name of the header or JWT claim. Not used for the sourceIdentity type.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// Validate returns an error if the scope or the keys of the RateLimitSpec are not valid.
+func (s RateLimitSpec) Validate() error {
+	switch s.Scope {
+	case "", RateLimitScopeLocal, RateLimitScopeGlobal:
+	default:
+		return fmt.Errorf("'%s' is not a valid scope", s.Scope)
+	}
+
+	if err := s.Key.validate(); err != nil {
+		return err
+	}
+	var key *RateLimitKey
+	for i, rule := range s.Rules {
+		if err := rule.Key.validate(); err != nil {
+			return err
+		}
+		if s.Key != nil && rule.Key != nil && !keysEqual(s.Key, rule.Key) {
+			return fmt.Errorf("the key of rule '%s' must be the same as the key of the rate limit", rule.Name)
+		}
+		if s.Key == nil && i > 0 && !keysEqual(key, rule.Key) {
+			return errors.New("all rules must use the same key")
+		}
+		key = rule.Key
+	}
+
+	return nil
+}
+
+// CountKey returns the key that traffic is counted by: the key of the RateLimitSpec if set,
+// otherwise the key of its rules. Returns nil if traffic is counted by the source address.
+func (s RateLimitSpec) CountKey() *RateLimitKey {
+	if s.Key != nil || len(s.Rules) == 0 {
+		return s.Key
+	}
+
+	return s.Rules[0].Key
+}
+
+func (k *RateLimitKey) validate() error {
+	if k == nil {
+		return nil
+	}
+	switch k.Type {
+	case RateLimitKeyHeader, RateLimitKeyJWTClaim:
+		if k.Name == "" {
+			return fmt.Errorf("key name must be set for key type '%s'", k.Type)
+		}
+	case RateLimitKeySourceIdentity:
+		if k.Name != "" {
+			return fmt.Errorf("key name cannot be set for key type '%s'", k.Type)
+		}
+	default:
+		return fmt.Errorf("'%s' is not a valid key type", k.Type)
+	}
+
+	return nil
+}
+
+func keysEqual(a, b *RateLimitKey) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,89 @@
+package v1alpha2_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha2"
+)
+
+var _ = Describe("RateLimit", func() {
+	var spec specs.RateLimitSpec
+	header := &specs.RateLimitKey{Type: specs.RateLimitKeyHeader, Name: "x-tenant-id"}
+
+	BeforeEach(func() {
+		spec = specs.RateLimitSpec{
+			Name:  "100rs",
+			Rate:  "100r/s",
+			Scope: specs.RateLimitScopeGlobal,
+			Rules: []specs.RateLimitRule{
+				{Kind: "HTTPRouteGroup", Name: "hrg1", Key: header},
+				{Kind: "HTTPRouteGroup", Name: "hrg2", Key: header},
+			},
+		}
+	})
+
+	It("is valid", func() {
+		Expect(spec.Validate()).To(Succeed())
+
+		spec.Scope = ""
+		spec.Rules = nil
+		Expect(spec.Validate()).To(Succeed())
+	})
+
+	It("rejects an unknown scope", func() {
+		spec.Scope = "cluster"
+		Expect(spec.Validate()).ToNot(Succeed())
+	})
+
+	It("requires a name for header and JWT claim keys", func() {
+		spec.Rules[0].Key = &specs.RateLimitKey{Type: specs.RateLimitKeyJWTClaim}
+		Expect(spec.Validate()).ToNot(Succeed())
+	})
+
+	It("does not allow a name for source identity keys", func() {
+		spec.Rules[0].Key = &specs.RateLimitKey{Type: specs.RateLimitKeySourceIdentity, Name: "foo"}
+		Expect(spec.Validate()).ToNot(Succeed())
+	})
+
+	It("rejects an unknown key type", func() {
+		spec.Rules[0].Key = &specs.RateLimitKey{Type: "cookie", Name: "session"}
+		Expect(spec.Validate()).ToNot(Succeed())
+	})
+
+	It("counts traffic by the key of the rate limit or of its rules", func() {
+		Expect(spec.CountKey()).To(Equal(header))
+
+		spec.Rules = nil
+		Expect(spec.CountKey()).To(BeNil())
+
+		identity := &specs.RateLimitKey{Type: specs.RateLimitKeySourceIdentity}
+		spec.Key = identity
+		Expect(spec.Validate()).To(Succeed())
+		Expect(spec.CountKey()).To(Equal(identity))
+	})
+
+	It("requires the rules to use the key of the rate limit", func() {
+		spec.Key = &specs.RateLimitKey{Type: specs.RateLimitKeyHeader, Name: "x-tenant-id"}
+		Expect(spec.Validate()).To(Succeed())
+
+		spec.Rules[1].Key = nil
+		Expect(spec.Validate()).To(Succeed())
+
+		spec.Rules[0].Key = &specs.RateLimitKey{Type: specs.RateLimitKeySourceIdentity}
+		Expect(spec.Validate()).ToNot(Succeed())
+	})
+
+	It("rejects an invalid key of the rate limit", func() {
+		spec.Key = &specs.RateLimitKey{Type: specs.RateLimitKeyHeader}
+		Expect(spec.Validate()).ToNot(Succeed())
+	})
+
+	It("requires all rules to use the same key", func() {
+		spec.Rules[1].Key = &specs.RateLimitKey{Type: specs.RateLimitKeySourceIdentity}
+		Expect(spec.Validate()).ToNot(Succeed())
+
+		spec.Rules[1].Key = nil
+		Expect(spec.Validate()).ToNot(Succeed())
+	})
+})
@@ -37,6 +37,21 @@ func (in *RateLimit) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitKey) DeepCopyInto(out *RateLimitKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitKey.
+func (in *RateLimitKey) DeepCopy() *RateLimitKey {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RateLimitList) DeepCopyInto(out *RateLimitList) {
 	*out = *in
@@ -77,6 +92,11 @@ func (in *RateLimitRule) DeepCopyInto(out *RateLimitRule) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(RateLimitKey)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitRule.
@@ -110,6 +130,11 @@ func (in *RateLimitSpec) DeepCopyInto(out *RateLimitSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(RateLimitKey)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitSpec.
@@ -39,3 +39,23 @@ func TestMeshControllerConnection(k8sClient client.Client, namespace string, ret
 
 	return err
 }
+
+// MessageBus is a connection to the message bus of the mesh, i.e. a nats.SecureMessageBus.
+type MessageBus interface {
+	HealthCheck() error
+}
+
+// TestMessageBusConnection checks that the message bus is connected and the server is answering.
+func TestMessageBusConnection(bus MessageBus, retries int) error {
+	var err error
+	for i := 0; i < retries; i++ {
+		if i > 0 {
+			time.Sleep(5 * time.Second)
+		}
+		if err = bus.HealthCheck(); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
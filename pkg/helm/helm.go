@@ -19,10 +19,12 @@ import (
 type Values struct {
 	Telemetry          *Telemetry `yaml:"telemetry" json:"telemetry"`
 	MTLS               MTLS       `yaml:"mtls" json:"mtls"`
+	Locality           Locality   `yaml:"locality" json:"locality"`
 	ClientMaxBodySize  string     `yaml:"clientMaxBodySize" json:"clientMaxBodySize"`
 	PrometheusAddress  string     `yaml:"prometheusAddress" json:"prometheusAddress"`
 	Environment        string     `yaml:"environment" json:"environment"`
 	AccessControlMode  string     `yaml:"accessControlMode" json:"accessControlMode"`
+	EgressMode         string     `yaml:"egressMode" json:"egressMode"`
 	NGINXErrorLogLevel string     `yaml:"nginxErrorLogLevel" json:"nginxErrorLogLevel"`
 	NGINXLBMethod      string     `yaml:"nginxLBMethod" json:"nginxLBMethod"`
 	NGINXLogFormat     string     `yaml:"nginxLogFormat" json:"nginxLogFormat"`
@@ -58,6 +60,12 @@ type OTLP struct {
 	Port int    `yaml:"port" json:"port"`
 }
 
+// Locality is the locality-aware load balancing struct within Values.
+type Locality struct {
+	Mode              string `yaml:"mode" json:"mode"`
+	FailoverThreshold int    `yaml:"failoverThreshold" json:"failoverThreshold"`
+}
+
 // MTLS is the mTLS struct within Values.
 type MTLS struct {
 	UpstreamAuthority     UpstreamAuthority `yaml:"upstreamAuthority,omitempty" json:"upstreamAuthority,omitempty"`
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/golang/glog"
 	v1 "k8s.io/api/core/v1"
@@ -129,8 +131,13 @@ func CreateInjectionConfig(
 		},
 	}
 
+	globalRateLimit, err := pod.GetGlobalRateLimitAnnotation(podAnnotations)
+	if err != nil {
+		glog.Warning(err.Error())
+	}
+
 	// set port arguments
-	if err := setPortArgs(containers, ignorePorts, &initContainer, &proxySidecar); err != nil {
+	if err := setPortArgs(containers, ignorePorts, globalRateLimit, &initContainer, &proxySidecar); err != nil {
 		return nil, err
 	}
 
@@ -330,10 +337,31 @@ func createRegistryKey(meshConfig mesh.FullMeshConfig, podNamespace string) (*v1
 	return &secret, nil
 }
 
+// FederatesWith returns the value of the federates-with annotation of a pod: the trust domains in the
+// existing annotation and the federated trust domains, sorted and without duplicates.
+// It returns an empty string if there are none.
+func FederatesWith(podAnnotations map[string]string, trustDomains []string) string {
+	set := make(map[string]struct{}, len(trustDomains))
+	for _, td := range append(strings.Split(podAnnotations[mesh.FederatesWithAnnotation], ","), trustDomains...) {
+		if td = strings.TrimSpace(td); td != "" {
+			set[td] = struct{}{}
+		}
+	}
+	federatesWith := make([]string, 0, len(set))
+	for td := range set {
+		federatesWith = append(federatesWith, td)
+	}
+	sort.Strings(federatesWith)
+
+	return strings.Join(federatesWith, ",")
+}
+
 // setPortArgs sets the service port and ignore port arguments on the init/sidecar containers.
+// The zone sync port is only ignored if the pod synchronizes global rate limits.
 func setPortArgs(
 	containers []v1.Container,
 	ignorePorts IgnorePorts,
+	globalRateLimit bool,
 	initContainer,
 	proxySidecar *v1.Container,
 ) error {
@@ -345,10 +373,17 @@ func setPortArgs(
 	for port := range ports {
 		proxySidecar.Args = append(proxySidecar.Args, "-s", port)
 	}
-	initContainer.Args = append(initContainer.Args, "--ignore-incoming-ports", strconv.Itoa(sidecar.MetricsPort))
+	// the metrics and zone sync ports are served by the sidecar itself, so never redirect them
+	sidecarPorts := []int{sidecar.MetricsPort}
+	if globalRateLimit {
+		sidecarPorts = append(sidecarPorts, sidecar.ZoneSyncPort)
+	}
+	for _, port := range sidecarPorts {
+		initContainer.Args = append(initContainer.Args, "--ignore-incoming-ports", strconv.Itoa(port))
+	}
 	if ignorePorts.Incoming != nil {
 		for _, port := range ignorePorts.Incoming {
-			if port != sidecar.MetricsPort {
+			if port != sidecar.MetricsPort && (!globalRateLimit || port != sidecar.ZoneSyncPort) {
 				initContainer.Args = append(initContainer.Args, "--ignore-incoming-ports", strconv.Itoa(port))
 			}
 		}
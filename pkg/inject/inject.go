@@ -72,6 +72,9 @@ type (
 	Inject struct {
 		Resources   []byte
 		IgnorePorts IgnorePorts
+		// FederatesWith are the trust domains of the MeshFederations. The injected workloads
+		// receive the trust bundles of these trust domains from SPIRE.
+		FederatesWith []string
 	}
 )
 
@@ -134,7 +137,7 @@ func IntoFile(
 		switch o := obj.(type) {
 		case *appsv1.Deployment:
 			registryKey, err = updateResource(
-				meshConfig, injectConfig.IgnorePorts, &o.Spec.Template.ObjectMeta,
+				meshConfig, injectConfig, &o.Spec.Template.ObjectMeta,
 				&o.Spec.Template.Spec, "deployment", o.Name, o.Namespace, registryKeySeen)
 			if err != nil {
 				return "", err
@@ -142,7 +145,7 @@ func IntoFile(
 			tmplArgs.Inputs = append(tmplArgs.Inputs, injectInput{o, doc, true})
 		case *appsv1.DaemonSet:
 			registryKey, err = updateResource(
-				meshConfig, injectConfig.IgnorePorts, &o.Spec.Template.ObjectMeta,
+				meshConfig, injectConfig, &o.Spec.Template.ObjectMeta,
 				&o.Spec.Template.Spec, "daemonset", o.Name, o.Namespace, registryKeySeen)
 			if err != nil {
 				return "", err
@@ -150,7 +153,7 @@ func IntoFile(
 			tmplArgs.Inputs = append(tmplArgs.Inputs, injectInput{o, doc, true})
 		case *appsv1.StatefulSet:
 			registryKey, err = updateResource(
-				meshConfig, injectConfig.IgnorePorts, &o.Spec.Template.ObjectMeta,
+				meshConfig, injectConfig, &o.Spec.Template.ObjectMeta,
 				&o.Spec.Template.Spec, "statefulset", o.Name, o.Namespace, registryKeySeen)
 			if err != nil {
 				return "", err
@@ -158,7 +161,7 @@ func IntoFile(
 			tmplArgs.Inputs = append(tmplArgs.Inputs, injectInput{o, doc, true})
 		case *appsv1.ReplicaSet:
 			registryKey, err = updateResource(
-				meshConfig, injectConfig.IgnorePorts, &o.Spec.Template.ObjectMeta,
+				meshConfig, injectConfig, &o.Spec.Template.ObjectMeta,
 				&o.Spec.Template.Spec, "replicaset", o.Name, o.Namespace, registryKeySeen)
 			if err != nil {
 				return "", err
@@ -166,7 +169,7 @@ func IntoFile(
 			tmplArgs.Inputs = append(tmplArgs.Inputs, injectInput{o, doc, true})
 		case *batchv1.Job:
 			registryKey, err = updateResource(
-				meshConfig, injectConfig.IgnorePorts, &o.Spec.Template.ObjectMeta,
+				meshConfig, injectConfig, &o.Spec.Template.ObjectMeta,
 				&o.Spec.Template.Spec, "job", o.Name, o.Namespace, registryKeySeen)
 			if err != nil {
 				return "", err
@@ -174,7 +177,7 @@ func IntoFile(
 			tmplArgs.Inputs = append(tmplArgs.Inputs, injectInput{o, doc, true})
 		case *v1.ReplicationController:
 			registryKey, err = updateResource(
-				meshConfig, injectConfig.IgnorePorts, &o.Spec.Template.ObjectMeta,
+				meshConfig, injectConfig, &o.Spec.Template.ObjectMeta,
 				&o.Spec.Template.Spec, "replicationcontroller", o.Name, o.Namespace, registryKeySeen)
 			if err != nil {
 				return "", err
@@ -182,7 +185,7 @@ func IntoFile(
 			tmplArgs.Inputs = append(tmplArgs.Inputs, injectInput{o, doc, true})
 		case *v1.Pod:
 			registryKey, err = updateResource(
-				meshConfig, injectConfig.IgnorePorts, &o.ObjectMeta, &o.Spec,
+				meshConfig, injectConfig, &o.ObjectMeta, &o.Spec,
 				"pod", o.Name, o.Namespace, registryKeySeen)
 			if err != nil {
 				return "", err
@@ -203,7 +206,7 @@ func IntoFile(
 // Injects the sidecar into a PodSpec.
 func updateResource(
 	meshConfig mesh.FullMeshConfig,
-	ignorePorts IgnorePorts,
+	injectConfig Inject,
 	meta *metav1.ObjectMeta,
 	spec *v1.PodSpec,
 	parentType,
@@ -211,7 +214,7 @@ func updateResource(
 	namespace string,
 	registryKeySeen map[string]struct{},
 ) (*v1.Secret, error) {
-	ip, err := GetIgnorePorts(meta.Annotations, ignorePorts)
+	ip, err := GetIgnorePorts(meta.Annotations, injectConfig.IgnorePorts)
 	if err != nil {
 		return nil, err
 	}
@@ -247,6 +250,9 @@ func updateResource(
 	for k, v := range cfg.Labels {
 		meta.Labels[k] = v
 	}
+	if federatesWith := FederatesWith(meta.Annotations, injectConfig.FederatesWith); federatesWith != "" {
+		meta.Annotations[mesh.FederatesWithAnnotation] = federatesWith
+	}
 
 	if _, ok := registryKeySeen[namespace]; !ok {
 		registryKeySeen[namespace] = struct{}{}
@@ -2,16 +2,20 @@ package inject_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/yaml"
 
 	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
 	"github.com/nginxinc/nginx-service-mesh/pkg/inject"
+	"github.com/nginxinc/nginx-service-mesh/pkg/sidecar"
 )
 
 var _ = Describe("Inject", func() {
@@ -150,6 +154,67 @@ replicas: 3
 		validCount := strings.Count(cfg, "docker-registry")
 		Expect(validCount).To(Equal(2))
 	})
+	It("ignores the zone sync port only for pods with global rate limits", func() {
+		deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+spec:
+  template:
+    metadata:
+      annotations:
+        %s
+`
+		initArgs := func(annotation string) []string {
+			injectConfig.Resources = []byte(fmt.Sprintf(deployment, annotation))
+			cfg, err := inject.IntoFile(injectConfig, meshConfig)
+			Expect(err).ToNot(HaveOccurred())
+			var injected appsv1.Deployment
+			Expect(yaml.Unmarshal([]byte(cfg), &injected)).To(Succeed())
+			Expect(injected.Spec.Template.Spec.InitContainers).To(HaveLen(1))
+
+			return injected.Spec.Template.Spec.InitContainers[0].Args
+		}
+		zoneSyncPort := strconv.Itoa(sidecar.ZoneSyncPort)
+
+		args := initArgs(`config.nsm.nginx.com/global-rate-limit: "true"`)
+		Expect(strings.Join(args, " ")).To(ContainSubstring("--ignore-incoming-ports " + zoneSyncPort))
+
+		for _, annotation := range []string{`config.nsm.nginx.com/global-rate-limit: "false"`, `other: "true"`} {
+			args = initArgs(annotation)
+			Expect(args).To(ContainElement(strconv.Itoa(sidecar.MetricsPort)))
+			Expect(args).ToNot(ContainElement(zoneSyncPort), annotation)
+		}
+	})
+	It("federates the pods with the trust domains of the mesh federations", func() {
+		deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+spec:
+  template:
+    metadata:
+      annotations:
+        %s
+`
+		annotations := func(annotation string) map[string]string {
+			injectConfig.Resources = []byte(fmt.Sprintf(deployment, annotation))
+			cfg, err := inject.IntoFile(injectConfig, meshConfig)
+			Expect(err).ToNot(HaveOccurred())
+			var injected appsv1.Deployment
+			Expect(yaml.Unmarshal([]byte(cfg), &injected)).To(Succeed())
+
+			return injected.Spec.Template.Annotations
+		}
+
+		Expect(annotations(`other: "true"`)).ToNot(HaveKey(mesh.FederatesWithAnnotation))
+
+		injectConfig.FederatesWith = []string{"west.example.com", "east.example.com"}
+		Expect(annotations(`other: "true"`)).To(
+			HaveKeyWithValue(mesh.FederatesWithAnnotation, "east.example.com,west.example.com"))
+		Expect(annotations(`spiffe.io/federatesWith: "north.example.com, west.example.com"`)).To(
+			HaveKeyWithValue(mesh.FederatesWithAnnotation, "east.example.com,north.example.com,west.example.com"))
+	})
 	It("errors when container ports are invalid", func() {
 		resources, err := os.ReadFile("testdata/unsupportedSCTPContainerPort.yaml")
 		Expect(err).ToNot(HaveOccurred())
@@ -32,6 +32,7 @@ import (
 	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/yaml"
 
+	meshv1alpha2 "github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh/v1alpha2"
 	nsmspecsv1alpha1 "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
 	nsmspecsv1alpha2 "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha2"
 	"github.com/nginxinc/nginx-service-mesh/pkg/k8s"
@@ -59,6 +60,7 @@ func NewFakeK8s(namespace string, skipRelease bool, objects ...runtime.Object) *
 	_ = specsv1alpha3.AddToScheme(scheme)
 	_ = nsmspecsv1alpha1.AddToScheme(scheme)
 	_ = nsmspecsv1alpha2.AddToScheme(scheme)
+	_ = meshv1alpha2.AddToScheme(scheme)
 
 	fakeClientSet := fake.NewSimpleClientset(objects...)
 
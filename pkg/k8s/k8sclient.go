@@ -175,15 +175,25 @@ func (k *ClientImpl) DynamicClientSet() dynamic.Interface {
 }
 
 var meshCRDs = map[string]struct{}{
-	"spiffeids.spiffeid.spiffe.io":        {},
-	"trafficsplits.split.smi-spec.io":     {},
-	"traffictargets.access.smi-spec.io":   {},
-	"httproutegroups.specs.smi-spec.io":   {},
-	"tcproutes.specs.smi-spec.io":         {},
-	"ratelimits.specs.smi.nginx.com":      {},
-	"circuitbreakers.specs.smi.nginx.com": {},
-	"meshconfigclasses.nsm.nginx.com":     {},
-	"meshconfigs.nsm.nginx.com":           {},
+	"spiffeids.spiffeid.spiffe.io":              {},
+	"trafficsplits.split.smi-spec.io":           {},
+	"traffictargets.access.smi-spec.io":         {},
+	"httproutegroups.specs.smi-spec.io":         {},
+	"tcproutes.specs.smi-spec.io":               {},
+	"ratelimits.specs.smi.nginx.com":            {},
+	"circuitbreakers.specs.smi.nginx.com":       {},
+	"retrypolicies.specs.smi.nginx.com":         {},
+	"timeoutpolicies.specs.smi.nginx.com":       {},
+	"faultinjections.specs.smi.nginx.com":       {},
+	"trafficmirrors.specs.smi.nginx.com":        {},
+	"httprewrites.specs.smi.nginx.com":          {},
+	"healthchecks.specs.smi.nginx.com":          {},
+	"authorizationpolicies.specs.smi.nginx.com": {},
+	"mtlspolicies.specs.smi.nginx.com":          {},
+	"externalservices.specs.smi.nginx.com":      {},
+	"meshconfigclasses.nsm.nginx.com":           {},
+	"meshconfigs.nsm.nginx.com":                 {},
+	"meshfederations.nsm.nginx.com":             {},
 }
 
 var errCRDAlreadyExists = errors.New("CRD already exists")
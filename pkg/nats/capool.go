@@ -0,0 +1,165 @@
+// Package nats contains the secure message bus implementation for the nats-server.
+package nats
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+var (
+	// ErrNoCABundle indicates that no CA bundle has been set in an InMemoryCAPool.
+	ErrNoCABundle = errors.New("no CA bundle set")
+	// ErrInvalidCABundle indicates that a CA bundle contains no certificates.
+	ErrInvalidCABundle = errors.New("could not parse any certificates from CA bundle")
+)
+
+// CAPool provides the root CAs used to verify the certificates of NATS peers.
+type CAPool interface {
+	CertPool() (*x509.CertPool, error)
+}
+
+// FileCAPool caches the root CAs of a CA file, so that the file is not read on every handshake.
+// The directory of the file is watched, and the cache is invalidated each time it changes.
+type FileCAPool struct {
+	pool    *x509.CertPool
+	watcher *fsnotify.Watcher
+	file    string
+	lock    sync.Mutex
+}
+
+// NewFileCAPool creates a new FileCAPool for the CA file.
+// The file is read and watched the first time the CertPool is requested.
+func NewFileCAPool(file string) *FileCAPool {
+	return &FileCAPool{file: file}
+}
+
+// CertPool returns the cached root CAs, and reads the CA file if they are not cached.
+// If the CA file cannot be watched, it is read each time.
+func (p *FileCAPool) CertPool() (*x509.CertPool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.pool != nil {
+		return p.pool, nil
+	}
+	// watch before reading, so that a change while reading invalidates the cache
+	if p.watcher == nil {
+		if err := p.watch(); err != nil {
+			log.Printf("not caching CA file %s: %v", p.file, err)
+		}
+	}
+	pool, err := initRootCAs(p.file)
+	if err != nil {
+		return nil, err
+	}
+	if p.watcher != nil {
+		p.pool = pool
+	}
+
+	return pool, nil
+}
+
+// Close stops watching the CA file.
+func (p *FileCAPool) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.watcher == nil {
+		return nil
+	}
+	err := p.watcher.Close()
+	p.watcher = nil
+	p.pool = nil
+
+	return err
+}
+
+// watch watches the directory of the CA file, so that a file replaced by a rename or a symlink swap
+// invalidates the cache as well.
+func (p *FileCAPool) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating file watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(p.file)); err != nil {
+		_ = watcher.Close()
+
+		return fmt.Errorf("error watching CA file: %w", err)
+	}
+	p.watcher = watcher
+
+	go func() {
+		for {
+			select {
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				p.invalidate(watcher)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("error watching CA file %s: %v", p.file, err)
+				p.invalidate(watcher)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// invalidate clears the cache, unless the watcher has been replaced.
+func (p *FileCAPool) invalidate(watcher *fsnotify.Watcher) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.watcher == watcher {
+		p.pool = nil
+	}
+}
+
+// InMemoryCAPool holds root CAs that are updated in memory instead of read from disk.
+// Update has the signature of a spiffe.BundleChangeHook, so the pool can be fed
+// directly by a CABundleManager.
+type InMemoryCAPool struct {
+	pool *x509.CertPool
+	lock sync.RWMutex
+}
+
+// NewInMemoryCAPool creates a new InMemoryCAPool with no root CAs.
+func NewInMemoryCAPool() *InMemoryCAPool {
+	return &InMemoryCAPool{}
+}
+
+// Update replaces the root CAs with the PEM encoded CA bundle.
+func (p *InMemoryCAPool) Update(caBundle []byte) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBundle) {
+		return ErrInvalidCABundle
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.pool = pool
+
+	return nil
+}
+
+// CertPool returns the root CAs. Returns an error if Update has not been called.
+func (p *InMemoryCAPool) CertPool() (*x509.CertPool, error) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.pool == nil {
+		return nil, ErrNoCABundle
+	}
+
+	return p.pool, nil
+}
@@ -0,0 +1,113 @@
+// Package nats contains the secure message bus implementation for the nats-server.
+package nats
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// eventBufferSize is the number of connection events buffered before new events are dropped.
+	eventBufferSize = 16
+	// healthCheckTimeout is how long HealthCheck waits for the nats-server to answer.
+	healthCheckTimeout = 2 * time.Second
+)
+
+// ConnectionState is the state of the connection of a SecureMessageBus to NATs.
+type ConnectionState string
+
+// Connection states.
+const (
+	// StateConnected means the bus connected to NATs.
+	StateConnected ConnectionState = "connected"
+	// StateDisconnected means the bus lost the connection to NATs, and is reconnecting if allowed.
+	StateDisconnected ConnectionState = "disconnected"
+	// StateReconnected means the bus reconnected to NATs. Subscriptions are restored.
+	StateReconnected ConnectionState = "reconnected"
+	// StateClosed means the connection to NATs is closed, and the bus does not reconnect.
+	StateClosed ConnectionState = "closed"
+)
+
+// ConnectionEvent is a change of the state of the connection of a SecureMessageBus to NATs.
+type ConnectionEvent struct {
+	Time time.Time
+	// Err is the cause of a disconnect, if any.
+	Err   error
+	State ConnectionState
+}
+
+// Events returns the channel that the connection events of the bus are written to.
+// Events are dropped if the channel is full.
+func (m *SecureMessageBus) Events() <-chan ConnectionEvent {
+	return m.events
+}
+
+// HealthCheck returns an error if the bus is not connected to NATs,
+// or if the nats-server does not answer a ping in time.
+func (m *SecureMessageBus) HealthCheck() error {
+	if m.Conn == nil {
+		return ErrNoConnection
+	}
+	if !m.Conn.IsConnected() {
+		return fmt.Errorf("%w: connection is %s", ErrNoConnection, m.Conn.Status())
+	}
+	if err := m.Conn.FlushTimeout(healthCheckTimeout); err != nil {
+		return fmt.Errorf("nats-server did not answer: %w", err)
+	}
+
+	return nil
+}
+
+// ReconnectBackoff is a NATs option that waits between reconnect attempts with exponential backoff,
+// starting at initialBackoff and capped at maxBackoff.
+func ReconnectBackoff(initialBackoff, maxBackoff time.Duration) nats.Option {
+	return nats.CustomReconnectDelay(func(attempts int) time.Duration {
+		backoff := initialBackoff
+		for i := 1; i < attempts && backoff < maxBackoff; i++ {
+			backoff *= 2
+		}
+		if backoff > maxBackoff {
+			return maxBackoff
+		}
+
+		return backoff
+	})
+}
+
+// addEventHandlers adds handlers that write connection events to the options, keeping the handlers set by the caller.
+func (m *SecureMessageBus) addEventHandlers(opts *nats.Options) {
+	disconnected := opts.DisconnectedErrCB
+	opts.DisconnectedErrCB = func(nc *nats.Conn, err error) {
+		m.connectionEvent(nc, StateDisconnected, err)
+		if disconnected != nil {
+			disconnected(nc, err)
+		}
+	}
+	reconnected := opts.ReconnectedCB
+	opts.ReconnectedCB = func(nc *nats.Conn) {
+		m.connectionEvent(nc, StateReconnected, nil)
+		if reconnected != nil {
+			reconnected(nc)
+		}
+	}
+	closed := opts.ClosedCB
+	opts.ClosedCB = func(nc *nats.Conn) {
+		m.connectionEvent(nc, StateClosed, nil)
+		if closed != nil {
+			closed(nc)
+		}
+	}
+}
+
+// connectionEvent writes a connection event, unless it is from a connection that has been replaced by Connect.
+func (m *SecureMessageBus) connectionEvent(nc *nats.Conn, state ConnectionState, err error) {
+	if nc != m.current.Load() {
+		return
+	}
+	select {
+	case m.events <- ConnectionEvent{Time: time.Now(), Err: err, State: state}:
+	default:
+	}
+}
@@ -0,0 +1,100 @@
+// Package nats contains the secure message bus implementation for the nats-server.
+package nats
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ErrNoStream indicates that no JetStream stream name or subjects were provided.
+var ErrNoStream = errors.New("no JetStream stream name or subjects provided")
+
+// JetStreamConfig configures the JetStream stream of a SecureMessageBus.
+// The nats-server must have JetStream enabled.
+type JetStreamConfig struct {
+	// Stream is the name of the stream.
+	Stream string
+	// Subjects are the subjects stored in the stream, i.e. NatsAgentConfigChannel. Wildcards are not supported.
+	Subjects []string
+	// MaxMsgsPerSubject is the number of messages kept for each subject. Defaults to 1, the last message.
+	MaxMsgsPerSubject int64
+	// MemoryStorage keeps the stream in memory instead of on disk.
+	MemoryStorage bool
+}
+
+// EnableJetStream stores the messages of the subjects of the config in a JetStream stream, so that a subscriber
+// receives the last message of a subject when it subscribes, i.e. after an agent reconnects, instead of waiting
+// for the next message. The stream is created or updated. Must be called after Connect.
+// Messages published to the subjects are acknowledged by the nats-server.
+func (m *SecureMessageBus) EnableJetStream(config JetStreamConfig) error {
+	if !m.IsConnected() {
+		return ErrNoConnection
+	}
+	if config.Stream == "" || len(config.Subjects) == 0 {
+		return ErrNoStream
+	}
+	js, err := m.Conn.JetStream()
+	if err != nil {
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	streamConfig := &nats.StreamConfig{
+		Name:              config.Stream,
+		Subjects:          config.Subjects,
+		MaxMsgsPerSubject: config.MaxMsgsPerSubject,
+		Storage:           nats.FileStorage,
+	}
+	if streamConfig.MaxMsgsPerSubject == 0 {
+		streamConfig.MaxMsgsPerSubject = 1
+	}
+	if config.MemoryStorage {
+		streamConfig.Storage = nats.MemoryStorage
+	}
+	if _, err = js.StreamInfo(config.Stream); errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = js.AddStream(streamConfig)
+	} else if err == nil {
+		_, err = js.UpdateStream(streamConfig)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to configure JetStream stream '%s': %w", config.Stream, err)
+	}
+
+	subjects := make(map[string]struct{}, len(config.Subjects))
+	for _, subj := range config.Subjects {
+		subjects[subj] = struct{}{}
+	}
+	m.js = js
+	m.jsStream = config.Stream
+	m.jsSubjects = subjects
+
+	return nil
+}
+
+// inStream returns whether the messages of the subject are stored in the JetStream stream.
+func (m *SecureMessageBus) inStream(subj string) bool {
+	if m.js == nil {
+		return false
+	}
+	_, ok := m.jsSubjects[subj]
+
+	return ok
+}
+
+// jetStreamSubscribe subscribes to a subject of the JetStream stream, starting with the last message of the subject.
+func (m *SecureMessageBus) jetStreamSubscribe(subj string, handler nats.MsgHandler, options subscribeOptions) (*nats.Subscription, error) {
+	opts := []nats.SubOpt{
+		nats.BindStream(m.jsStream),
+		nats.DeliverLastPerSubject(),
+		nats.AckNone(),
+	}
+	if options.durable != "" {
+		opts = append(opts, nats.Durable(options.durable))
+	}
+	if options.queue != "" {
+		return m.js.QueueSubscribe(subj, options.queue, handler, opts...)
+	}
+
+	return m.js.Subscribe(subj, handler, opts...)
+}
@@ -0,0 +1,215 @@
+// Package nats contains the secure message bus implementation for the nats-server.
+package nats
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ErrorHeader is the header of a reply that holds the error returned by a RequestHandler.
+const ErrorHeader = "Nsm-Error"
+
+// ErrNoHandler indicates that a nil handler was provided.
+var ErrNoHandler = errors.New("no handler provided")
+
+// RemoteError is the error returned by the RequestHandler of a request.
+type RemoteError struct {
+	msg string
+}
+
+func (e RemoteError) Error() string {
+	return "request failed: " + e.msg
+}
+
+// RequestHandler handles the data of a request and returns the data of the reply.
+// If an error is returned, the requester gets a RemoteError.
+type RequestHandler func(data []byte) ([]byte, error)
+
+// Subscription is a handle to a subscription of a SecureMessageBus.
+type Subscription struct {
+	bus *SecureMessageBus
+	sub *nats.Subscription
+	// subscribe subscribes on the current connection of the bus.
+	subscribe func() (*nats.Subscription, error)
+	dropped   atomic.Uint64
+	lock      sync.Mutex
+}
+
+// newSubscription subscribes on the connection of the bus, and tracks the subscription
+// so that it is restored when the connection is replaced.
+func (m *SecureMessageBus) newSubscription(subscribe func(sub *Subscription) (*nats.Subscription, error)) (*Subscription, error) {
+	s := &Subscription{bus: m}
+	s.subscribe = func() (*nats.Subscription, error) {
+		return subscribe(s)
+	}
+	if err := s.resubscribe(); err != nil {
+		return nil, err
+	}
+	m.track(s)
+
+	return s, nil
+}
+
+// Unsubscribe removes the subscription.
+func (s *Subscription) Unsubscribe() error {
+	s.bus.untrack(s)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.sub.Unsubscribe()
+}
+
+// resubscribe subscribes on the current connection of the bus.
+func (s *Subscription) resubscribe() error {
+	sub, err := s.subscribe()
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sub = sub
+
+	return nil
+}
+
+// Dropped returns the number of messages that were dropped because the channel of the subscription was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Subject returns the subject of the subscription.
+func (s *Subscription) Subject() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.sub.Subject
+}
+
+type subscribeOptions struct {
+	queue        string
+	durable      string
+	dropWhenFull bool
+}
+
+// SubscribeOption configures a subscription.
+type SubscribeOption func(*subscribeOptions)
+
+// WithQueueGroup adds the subscription to a queue group.
+// Each message is delivered to only one subscription of the queue group.
+func WithQueueGroup(queue string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.queue = queue
+	}
+}
+
+// WithDropWhenFull delivers messages without blocking.
+// Messages are dropped, and counted, when the channel of the subscription is full.
+func WithDropWhenFull() SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.dropWhenFull = true
+	}
+}
+
+// WithDurable names the JetStream consumer of the subscription, so that it resumes where it left off
+// after a restart. Only used for subjects of the JetStream stream.
+func WithDurable(name string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.durable = name
+	}
+}
+
+// SubscribeWithOptions subscribes to the provided subject and returns a handle to the subscription.
+// If subscribe is called before connection to NATs an error will be returned.
+// Data from messages sent to the subject will be placed on the msgCh channel. Unless WithDropWhenFull is set,
+// delivery blocks until the msgCh channel has room.
+// If JetStream is enabled and the subject is in the stream, the last message of the subject is delivered first.
+func (m *SecureMessageBus) SubscribeWithOptions(subj string, msgCh chan []byte, opts ...SubscribeOption) (*Subscription, error) {
+	if !m.IsConnected() {
+		return nil, ErrNoConnection
+	}
+	options := subscribeOptions{}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	sub, err := m.newSubscription(func(sub *Subscription) (*nats.Subscription, error) {
+		handler := func(msg *nats.Msg) {
+			if !options.dropWhenFull {
+				msgCh <- msg.Data
+
+				return
+			}
+			select {
+			case msgCh <- msg.Data:
+			default:
+				sub.dropped.Add(1)
+			}
+		}
+		if m.inStream(subj) {
+			return m.jetStreamSubscribe(subj, handler, options)
+		}
+
+		return m.Conn.QueueSubscribe(subj, options.queue, handler)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to '%s': %w", subj, err)
+	}
+
+	return sub, nil
+}
+
+// Request sends a request with the data to the subject and waits for the reply.
+// Returns a RemoteError if the RequestHandler of the subject returned an error.
+func (m *SecureMessageBus) Request(subj string, data []byte, timeout time.Duration) ([]byte, error) {
+	if !m.IsConnected() {
+		return nil, ErrNoConnection
+	}
+	reply, err := m.Conn.Request(subj, data, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("request to '%s' failed: %w", subj, err)
+	}
+	if msg := reply.Header.Get(ErrorHeader); msg != "" {
+		return nil, RemoteError{msg: msg}
+	}
+
+	return reply.Data, nil
+}
+
+// Respond replies to the requests sent to the subject with the handler.
+// Use WithQueueGroup to share the requests between multiple responders.
+func (m *SecureMessageBus) Respond(subj string, handler RequestHandler, opts ...SubscribeOption) (*Subscription, error) {
+	if !m.IsConnected() {
+		return nil, ErrNoConnection
+	}
+	if handler == nil {
+		return nil, ErrNoHandler
+	}
+	options := subscribeOptions{}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	sub, err := m.newSubscription(func(*Subscription) (*nats.Subscription, error) {
+		return m.Conn.QueueSubscribe(subj, options.queue, func(msg *nats.Msg) {
+			reply := nats.NewMsg(msg.Reply)
+			data, err := handler(msg.Data)
+			if err != nil {
+				reply.Header.Set(ErrorHeader, err.Error())
+			} else {
+				reply.Data = data
+			}
+			// the requester times out if the reply is lost
+			_ = msg.RespondMsg(reply)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to respond to '%s': %w", subj, err)
+	}
+
+	return sub, nil
+}
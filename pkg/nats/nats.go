@@ -7,9 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 )
 
 var (
@@ -36,7 +40,17 @@ func (e RootCertParseError) Error() string {
 // and provides methods to publish and subscribe to subjects.
 type SecureMessageBus struct {
 	Conn *nats.Conn
-	opts nats.Options
+	js   nats.JetStreamContext
+	// jsSubjects are the subjects of the JetStream stream.
+	jsSubjects map[string]struct{}
+	// subs are the subscriptions that are restored when Connect replaces the connection.
+	subs     map[*Subscription]struct{}
+	events   chan ConnectionEvent
+	jsStream string
+	opts     nats.Options
+	// current is the connection that connection events are written for.
+	current  atomic.Pointer[nats.Conn]
+	subsLock sync.Mutex
 }
 
 // SecurableConfig implements everything that
@@ -47,13 +61,20 @@ type SecurableConfig interface {
 	CreateTLSConfig() (*tls.Config, error)
 	CAFile() string
 	ServerName() string
+	// RootCAs returns the root CAs used to verify the certificates of peers.
+	RootCAs() (*x509.CertPool, error)
+	// ServerSPIFFEIDMatcher returns the matcher of the SPIFFE ID of the server.
+	// If nil, the SPIFFE ID of the server is not verified.
+	ServerSPIFFEIDMatcher() SPIFFEIDMatcher
 }
 
 // NewSecureMessageBus returns a new instance of a Secure Message Bus.
 // Must provide a valid secure config in order to enable mTLS with the NATs server.
 func NewSecureMessageBus(secureConfig SecurableConfig) (*SecureMessageBus, error) {
 	messageBus := &SecureMessageBus{
-		opts: nats.GetDefaultOptions(),
+		opts:   nats.GetDefaultOptions(),
+		subs:   make(map[*Subscription]struct{}),
+		events: make(chan ConnectionEvent, eventBufferSize),
 	}
 	if err := secureConfig.Validate(); err != nil {
 		return nil, fmt.Errorf("%v: %w", ErrInvalidConfig, err) //nolint:errorlint // only one %w allowed
@@ -81,6 +102,8 @@ func (m *SecureMessageBus) Close() error {
 }
 
 // Connect connects to NATs with the specified opts.
+// If the bus is already connected, the previous connection is closed
+// and the subscriptions of the bus are restored on the new connection.
 func (m *SecureMessageBus) Connect(url string, opts ...nats.Option) error {
 	m.opts.Url = url
 	for _, o := range opts {
@@ -88,21 +111,94 @@ func (m *SecureMessageBus) Connect(url string, opts ...nats.Option) error {
 			return fmt.Errorf("failed to apply NATs option: %w", err)
 		}
 	}
-	var err error
-	m.Conn, err = m.opts.Connect()
+	connOpts := m.opts
+	m.addEventHandlers(&connOpts)
+	conn, err := connOpts.Connect()
 	if err != nil {
 		return fmt.Errorf("could not connect to NATs server: %w", err)
 	}
 
+	previous := m.Conn
+	m.Conn = conn
+	m.current.Store(conn)
+	m.connectionEvent(conn, StateConnected, nil)
+	if previous == nil {
+		return nil
+	}
+	previous.Close()
+
+	return m.resubscribe()
+}
+
+// resubscribe restores the JetStream context and the subscriptions of the bus on a new connection.
+func (m *SecureMessageBus) resubscribe() error {
+	if m.js != nil {
+		js, err := m.Conn.JetStream()
+		if err != nil {
+			return fmt.Errorf("failed to create JetStream context: %w", err)
+		}
+		m.js = js
+	}
+
+	m.subsLock.Lock()
+	defer m.subsLock.Unlock()
+	var errs []error
+	for sub := range m.subs {
+		if err := sub.resubscribe(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to restore subscriptions: %w", err)
+	}
+
 	return nil
 }
 
+// Subscriptions returns the subjects of the subscriptions of the bus, sorted.
+func (m *SecureMessageBus) Subscriptions() []string {
+	m.subsLock.Lock()
+	defer m.subsLock.Unlock()
+	subjects := make([]string, 0, len(m.subs))
+	for sub := range m.subs {
+		subjects = append(subjects, sub.Subject())
+	}
+	sort.Strings(subjects)
+
+	return subjects
+}
+
+// track adds a subscription to the subscriptions of the bus.
+func (m *SecureMessageBus) track(sub *Subscription) {
+	m.subsLock.Lock()
+	defer m.subsLock.Unlock()
+	if m.subs == nil {
+		m.subs = make(map[*Subscription]struct{})
+	}
+	m.subs[sub] = struct{}{}
+}
+
+// untrack removes a subscription from the subscriptions of the bus.
+func (m *SecureMessageBus) untrack(sub *Subscription) {
+	m.subsLock.Lock()
+	defer m.subsLock.Unlock()
+	delete(m.subs, sub)
+}
+
 // Publish publishes message to the subject.
 // If publish is called before connection to NATs an error will be returned.
+// If JetStream is enabled and the subject is in the stream, the message is stored in the stream.
 func (m *SecureMessageBus) Publish(subj string, msg []byte) error {
 	if !m.IsConnected() {
 		return ErrNoConnection
 	}
+	if m.inStream(subj) {
+		if _, err := m.js.Publish(subj, msg); err != nil {
+			return fmt.Errorf("failed to publish to JetStream: %w", err)
+		}
+
+		return nil
+	}
 
 	return m.Conn.Publish(subj, msg)
 }
@@ -110,13 +206,9 @@ func (m *SecureMessageBus) Publish(subj string, msg []byte) error {
 // Subscribe subscribes to the provided subject.
 // If subscribe is called before connection to NATs an error will be returned.
 // Data from messages sent to the subject will be placed on the msgCh channel.
+// Use SubscribeWithOptions for queue groups, non-blocking delivery, or to unsubscribe.
 func (m *SecureMessageBus) Subscribe(subj string, msgCh chan []byte) error {
-	if !m.IsConnected() {
-		return ErrNoConnection
-	}
-	_, err := m.Conn.Subscribe(subj, func(m *nats.Msg) {
-		msgCh <- m.Data
-	})
+	_, err := m.SubscribeWithOptions(subj, msgCh)
 
 	return err
 }
@@ -132,11 +224,15 @@ func (m *SecureMessageBus) IsConnected() bool {
 // NATS client.
 type InMemorySecureConfig struct {
 	certGetter       func(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+	serverIDMatcher  SPIFFEIDMatcher
+	caPool           CAPool
 	serverName       string
 	caBundleFilepath string
 }
 
 // NewInMemorySecureConfig creates a new InMemorySecureConfig.
+// The CA bundle file is read on every handshake. Use WithCAPool to cache it
+// with a FileCAPool, or to provide the root CAs in memory instead.
 func NewInMemorySecureConfig(
 	server, caBundle string,
 	cert func(*tls.CertificateRequestInfo) (*tls.Certificate, error),
@@ -160,6 +256,35 @@ func (i InMemorySecureConfig) ServerName() string {
 	return i.serverName
 }
 
+// RootCAs getter for the root CAs.
+// implements SecurableConfig from NATS package.
+func (i InMemorySecureConfig) RootCAs() (*x509.CertPool, error) {
+	return rootCAs(i.caPool, i.caBundleFilepath)
+}
+
+// WithCAPool returns a copy of the config that gets the root CAs from the pool,
+// i.e. a FileCAPool or an InMemoryCAPool fed by a CABundleManager.
+// The caller owns the pool, and closes a FileCAPool when it is no longer used.
+func (i InMemorySecureConfig) WithCAPool(pool CAPool) InMemorySecureConfig {
+	i.caPool = pool
+
+	return i
+}
+
+// ServerSPIFFEIDMatcher getter for the matcher of the server SPIFFE ID.
+// implements SecurableConfig from NATS package.
+func (i InMemorySecureConfig) ServerSPIFFEIDMatcher() SPIFFEIDMatcher {
+	return i.serverIDMatcher
+}
+
+// WithServerSPIFFEID returns a copy of the config that only connects to a server
+// whose SPIFFE ID matches the matcher.
+func (i InMemorySecureConfig) WithServerSPIFFEID(matcher SPIFFEIDMatcher) InMemorySecureConfig {
+	i.serverIDMatcher = matcher
+
+	return i
+}
+
 // Validate validates potential TLS Config.
 // implements SecurableConfig from NATS package.
 func (i *InMemorySecureConfig) Validate() error {
@@ -195,9 +320,14 @@ type OnDiskSecureConfig struct {
 	// ServerName is the server name of the nats-server.
 	// Must match one of the DNS names on the server certificate.
 	serverName string
+	// serverIDMatcher matches the SPIFFE ID of the nats-server, if set.
+	serverIDMatcher SPIFFEIDMatcher
+	// caPool provides the root CAs. If not set, the CA file is read on every handshake.
+	caPool CAPool
 }
 
 // NewOnDiskSecureConfig make an OnDiskSecureConfig.
+// The CA file is read on every handshake. Use WithCAPool to cache it.
 func NewOnDiskSecureConfig(
 	serverName, certFile, keyFile, caFile string,
 ) OnDiskSecureConfig {
@@ -258,42 +388,95 @@ func (sc OnDiskSecureConfig) CAFile() string {
 	return sc.caFile
 }
 
+// RootCAs implements getter for SecurableConfig interface.
+func (sc OnDiskSecureConfig) RootCAs() (*x509.CertPool, error) {
+	return rootCAs(sc.caPool, sc.caFile)
+}
+
+// WithCAPool returns a copy of the config that gets the root CAs from the pool.
+// The caller owns the pool, and closes a FileCAPool when it is no longer used.
+func (sc OnDiskSecureConfig) WithCAPool(pool CAPool) OnDiskSecureConfig {
+	sc.caPool = pool
+
+	return sc
+}
+
+// ServerSPIFFEIDMatcher implements getter for SecurableConfig interface.
+func (sc OnDiskSecureConfig) ServerSPIFFEIDMatcher() SPIFFEIDMatcher {
+	return sc.serverIDMatcher
+}
+
+// WithServerSPIFFEID returns a copy of the config that only connects to a server
+// whose SPIFFE ID matches the matcher.
+func (sc OnDiskSecureConfig) WithServerSPIFFEID(matcher SPIFFEIDMatcher) OnDiskSecureConfig {
+	sc.serverIDMatcher = matcher
+
+	return sc
+}
+
 // VerifyServerCertificate uses a custom CA File from a securable config
-// to validate a NATS server during connection.
+// to validate a NATS server during connection. If the securable config has
+// a server SPIFFE ID matcher, the SPIFFE ID URI SAN of the server must match it.
 func VerifyServerCertificate(conf SecurableConfig, certificates [][]byte) error {
 	if len(certificates) == 0 {
 		return ErrNoCertificate
 	}
+	leaf, err := verifyCertificateChain(conf, certificates, x509.VerifyOptions{
+		DNSName: conf.ServerName(),
+	})
+	if err != nil {
+		return err
+	}
+
+	matcher := conf.ServerSPIFFEIDMatcher()
+	if matcher == nil {
+		return nil
+	}
+	id, err := x509svid.IDFromCert(leaf)
+	if err != nil {
+		return fmt.Errorf("failed to get SPIFFE ID from server certificate: %w", err)
+	}
+
+	return matcher(id)
+}
 
+// verifyCertificateChain parses the certificates presented by a peer and verifies them against
+// the CA File from a securable config. Returns the leaf certificate.
+func verifyCertificateChain(conf SecurableConfig, certificates [][]byte, opts x509.VerifyOptions) (*x509.Certificate, error) {
 	certs := make([]*x509.Certificate, len(certificates))
 	for i, asn1Data := range certificates {
 		cert, err := x509.ParseCertificate(asn1Data)
 		if err != nil {
-			return fmt.Errorf("tls: failed to parse certificate from server: %w", err)
+			return nil, fmt.Errorf("tls: failed to parse certificate from peer: %w", err)
 		}
 		certs[i] = cert
 	}
 
-	rootCAs, err := initRootCAs(conf.CAFile())
+	rootCAs, err := conf.RootCAs()
 	if err != nil {
-		return fmt.Errorf("failed to initialize root ca: %w", err)
-	}
-
-	opts := x509.VerifyOptions{
-		Roots:         rootCAs,
-		CurrentTime:   time.Now(),
-		DNSName:       conf.ServerName(),
-		Intermediates: x509.NewCertPool(),
+		return nil, fmt.Errorf("failed to initialize root ca: %w", err)
 	}
 
+	opts.Roots = rootCAs
+	opts.CurrentTime = time.Now()
+	opts.Intermediates = x509.NewCertPool()
 	for _, cert := range certs[1:] {
 		opts.Intermediates.AddCert(cert)
 	}
 	if _, err = certs[0].Verify(opts); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return certs[0], nil
+}
+
+// rootCAs returns the root CAs of the pool, or of the file if there is no pool.
+func rootCAs(pool CAPool, file string) (*x509.CertPool, error) {
+	if pool != nil {
+		return pool.CertPool()
+	}
+
+	return initRootCAs(file)
 }
 
 func initRootCAs(file ...string) (*x509.CertPool, error) {
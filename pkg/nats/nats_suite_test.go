@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
 	"math/big"
+	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
@@ -20,6 +22,11 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+const (
+	serverSPIFFEID = "spiffe://example.org/nats-server"
+	clientSPIFFEID = "spiffe://example.org/agent"
+)
+
 var (
 	testFilename string
 	testDataDir  string
@@ -74,6 +81,8 @@ type NATSSession struct {
 	pidFile    *os.File
 	configFile *os.File
 	server     *server.Server
+	caPrivKey  *rsa.PrivateKey
+	ca         *x509.Certificate
 	certDir    string
 	Port       int
 }
@@ -106,32 +115,32 @@ func (ns *NATSSession) bootstrap() {
 func (ns *NATSSession) writeCerts() {
 	caPrivKey, caCert, ca, err := newTestCA() //nolint:varnamelen // ca is a good name
 	Expect(err).ToNot(HaveOccurred())
-
-	serverPrivKey, serverCert, err := newTestCertFromCA(caPrivKey, &ca)
-	Expect(err).ToNot(HaveOccurred())
-
-	clientPrivKey, clientCert, err := newTestCertFromCA(caPrivKey, &ca)
-	Expect(err).ToNot(HaveOccurred())
+	ns.caPrivKey = caPrivKey
+	ns.ca = &ca
 
 	buf, err := encodeCert(caCert)
 	Expect(err).ToNot(HaveOccurred())
 	Expect(pemToFile(buf, ns.certDir, "ca_pem")).To(Succeed())
 
-	buf, err = encodePrivateKey(serverPrivKey)
-	Expect(err).ToNot(HaveOccurred())
-	Expect(pemToFile(buf, ns.certDir, "server-key_pem")).To(Succeed())
+	ns.WriteCert("server", serverSPIFFEID)
+	ns.WriteCert("client", clientSPIFFEID)
+}
 
-	buf, err = encodeCert(serverCert)
+// WriteCert writes a cert and key signed by the test CA with the SPIFFE ID
+// to the <name>-cert_pem and <name>-key_pem files.
+func (ns *NATSSession) WriteCert(name, spiffeID string) {
+	uri, err := url.Parse(spiffeID)
+	Expect(err).ToNot(HaveOccurred())
+	privKey, cert, err := newTestCertFromCA(ns.caPrivKey, ns.ca, uri)
 	Expect(err).ToNot(HaveOccurred())
-	Expect(pemToFile(buf, ns.certDir, "server-cert_pem")).To(Succeed())
 
-	buf, err = encodePrivateKey(clientPrivKey)
+	buf, err := encodePrivateKey(privKey)
 	Expect(err).ToNot(HaveOccurred())
-	Expect(pemToFile(buf, ns.certDir, "client-key_pem")).To(Succeed())
+	Expect(pemToFile(buf, ns.certDir, name+"-key_pem")).To(Succeed())
 
-	buf, err = encodeCert(clientCert)
+	buf, err = encodeCert(cert)
 	Expect(err).ToNot(HaveOccurred())
-	Expect(pemToFile(buf, ns.certDir, "client-cert_pem")).To(Succeed())
+	Expect(pemToFile(buf, ns.certDir, name+"-cert_pem")).To(Succeed())
 }
 
 func (ns *NATSSession) writeTLSConfigFile() {
@@ -147,6 +156,20 @@ func (ns *NATSSession) Start() {
 	ns.server = testserver.RunServer(opts)
 }
 
+// StartMTLSServer starts a nats-server in process that uses the TLS config, on a random open port.
+// The options are applied to the default test options. Returns the server and its port.
+func StartMTLSServer(tlsConfig *tls.Config, options ...func(*server.Options)) (*server.Server, int) {
+	opts := testserver.DefaultTestOptions
+	opts.Host = "localhost"
+	opts.Port = pickPort()
+	opts.TLSConfig = tlsConfig
+	for _, o := range options {
+		o(&opts)
+	}
+
+	return testserver.RunServer(&opts), opts.Port
+}
+
 // Cleanup kills the server and cleans up the temp files.
 func (ns *NATSSession) Cleanup() {
 	ns.server.Shutdown()
@@ -207,12 +230,14 @@ func newTestCA() (*rsa.PrivateKey, []byte, x509.Certificate, error) {
 	return caPrivKey, caBytes, ca, err
 }
 
-// newTestCertFromCA creates a cert for testing.
+// newTestCertFromCA creates a cert with the URI SANs for testing.
 func newTestCertFromCA(
 	caPrivKey *rsa.PrivateKey,
 	ca *x509.Certificate, //nolint:varnamelen // ca is a perfectly clear name here
+	uris ...*url.URL,
 ) (*rsa.PrivateKey, []byte, error) {
 	cert := testCert
+	cert.URIs = uris
 
 	//nolint:gosec // 1024 cert generation is faster
 	certPrivKey, err := rsa.GenerateKey(rand.Reader, 1024)
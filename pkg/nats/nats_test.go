@@ -2,16 +2,23 @@ package nats_test
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"testing"
 	"time"
 
+	"github.com/nats-io/nats-server/v2/server"
 	gonats "github.com/nats-io/nats.go"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 
+	"github.com/nginxinc/nginx-service-mesh/pkg/health"
 	"github.com/nginxinc/nginx-service-mesh/pkg/nats"
+	"github.com/nginxinc/nginx-service-mesh/pkg/spiffe"
 )
 
 const natsSubject = "test.subject"
@@ -310,3 +317,540 @@ var _ = Describe("NATs secure message bus", func() {
 		curSession.Cleanup()
 	})
 })
+
+var _ = Describe("NATs mTLS server", func() {
+	serverConf := nats.NewOnDiskSecureConfig(
+		"localhost",
+		filepath.Join(testDataDir, "server-cert_pem"),
+		filepath.Join(testDataDir, "server-key_pem"),
+		filepath.Join(testDataDir, "ca_pem"),
+	)
+
+	It("fails to create a server TLS config without allowed SPIFFE IDs", func() {
+		_, err := nats.NewServerTLSConfig(serverConf, nil)
+		Expect(err).To(MatchError(nats.ErrNoAllowedSPIFFEIDs))
+		_, err = nats.NewServerTLSConfig(serverConf, []string{"not-a-spiffe-id"})
+		Expect(err).To(HaveOccurred())
+		_, err = nats.NewServerTLSConfig(nats.OnDiskSecureConfig{}, []string{clientSPIFFEID})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("verifies the SPIFFE ID of the client certificate", func() {
+		clientCert, err := tls.LoadX509KeyPair(
+			filepath.Join(testDataDir, "client-cert_pem"),
+			filepath.Join(testDataDir, "client-key_pem"),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nats.VerifyClientCertificate(serverConf, []string{clientSPIFFEID}, clientCert.Certificate)).To(Succeed())
+		Expect(nats.VerifyClientCertificate(serverConf, []string{serverSPIFFEID}, clientCert.Certificate)).
+			To(MatchError(nats.ErrSPIFFEIDNotAllowed))
+		Expect(nats.VerifyClientCertificate(serverConf, []string{clientSPIFFEID}, nil)).
+			To(MatchError(nats.ErrNoClientCertificate))
+	})
+
+	Context("with a running server", func() {
+		var (
+			srv  *server.Server
+			port int
+		)
+
+		BeforeEach(func() {
+			tlsConfig, err := nats.NewServerTLSConfig(serverConf, []string{clientSPIFFEID})
+			Expect(err).ToNot(HaveOccurred())
+			srv, port = StartMTLSServer(tlsConfig)
+		})
+		AfterEach(func() {
+			srv.Shutdown()
+		})
+
+		It("accepts clients with an allowed SPIFFE ID", func() {
+			bus, err := nats.NewSecureMessageBus(nats.NewOnDiskSecureConfig(
+				"localhost",
+				filepath.Join(testDataDir, "client-cert_pem"),
+				filepath.Join(testDataDir, "client-key_pem"),
+				filepath.Join(testDataDir, "ca_pem"),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bus.Connect(fmt.Sprintf("localhost:%d", port))).To(Succeed())
+			defer func() { Expect(bus.Close()).To(Succeed()) }()
+
+			msgCh := make(chan []byte, 1)
+			Expect(bus.Subscribe(natsSubject, msgCh)).To(Succeed())
+			Expect(bus.Publish(natsSubject, []byte("mtls message"))).To(Succeed())
+			Eventually(msgCh).Should(Receive(Equal([]byte("mtls message"))))
+		})
+
+		It("rejects clients with a SPIFFE ID that is not allowed", func() {
+			natsSession.WriteCert("other-client", "spiffe://example.org/other")
+			bus, err := nats.NewSecureMessageBus(nats.NewOnDiskSecureConfig(
+				"localhost",
+				filepath.Join(testDataDir, "other-client-cert_pem"),
+				filepath.Join(testDataDir, "other-client-key_pem"),
+				filepath.Join(testDataDir, "ca_pem"),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			err = bus.Connect(fmt.Sprintf("localhost:%d", port))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("bad certificate"))
+			Expect(bus.IsConnected()).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("Server SPIFFE ID authorization", func() {
+	It("matches SPIFFE IDs", func() {
+		id := spiffeid.RequireFromString("spiffe://example.org/nats/server")
+
+		matcher, err := nats.MatchSPIFFEID("spiffe://example.org/nats/server")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matcher(id)).To(Succeed())
+		Expect(matcher(spiffeid.RequireFromString("spiffe://example.org/nats"))).To(MatchError(nats.ErrSPIFFEIDMismatch))
+
+		matcher, err = nats.MatchTrustDomain("example.org")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matcher(id)).To(Succeed())
+		Expect(matcher(spiffeid.RequireFromString("spiffe://other.org/nats/server"))).To(MatchError(nats.ErrSPIFFEIDMismatch))
+
+		for _, prefix := range []string{"spiffe://example.org", "spiffe://example.org/nats", "spiffe://example.org/nats/"} {
+			matcher, err = nats.MatchPathPrefix(prefix)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matcher(id)).To(Succeed(), prefix)
+		}
+		matcher, err = nats.MatchPathPrefix("spiffe://example.org/nat")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matcher(id)).To(MatchError(nats.ErrSPIFFEIDMismatch))
+		matcher, err = nats.MatchPathPrefix("spiffe://other.org/nats")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matcher(id)).To(MatchError(nats.ErrSPIFFEIDMismatch))
+
+		_, err = nats.MatchSPIFFEID("not-a-spiffe-id")
+		Expect(err).To(HaveOccurred())
+		_, err = nats.MatchTrustDomain("Not A Trust Domain")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("connecting to the NATs server", func() {
+		conf := nats.NewOnDiskSecureConfig(
+			"localhost",
+			filepath.Join(testDataDir, "client-cert_pem"),
+			filepath.Join(testDataDir, "client-key_pem"),
+			filepath.Join(testDataDir, "ca_pem"),
+		)
+		connect := func(newMatcher func(string) (nats.SPIFFEIDMatcher, error), expected string) error {
+			matcher, err := newMatcher(expected)
+			Expect(err).ToNot(HaveOccurred())
+			bus, err := nats.NewSecureMessageBus(conf.WithServerSPIFFEID(matcher))
+			Expect(err).ToNot(HaveOccurred())
+			if err := bus.Connect(fmt.Sprintf("localhost:%d", natsSession.Port)); err != nil {
+				return err
+			}
+
+			return bus.Close()
+		}
+
+		It("connects to a server with the expected SPIFFE ID", func() {
+			Expect(connect(nats.MatchSPIFFEID, serverSPIFFEID)).To(Succeed())
+			Expect(connect(nats.MatchTrustDomain, "example.org")).To(Succeed())
+			Expect(connect(nats.MatchPathPrefix, "spiffe://example.org")).To(Succeed())
+		})
+
+		It("does not connect to a server with an unexpected SPIFFE ID", func() {
+			Expect(connect(nats.MatchSPIFFEID, clientSPIFFEID)).To(MatchError(nats.ErrSPIFFEIDMismatch))
+			Expect(connect(nats.MatchTrustDomain, "other.org")).To(MatchError(nats.ErrSPIFFEIDMismatch))
+			Expect(connect(nats.MatchPathPrefix, "spiffe://example.org/nats")).To(MatchError(nats.ErrSPIFFEIDMismatch))
+		})
+
+		It("verifies the SPIFFE ID of the server certificate", func() {
+			matcher, err := nats.MatchSPIFFEID(serverSPIFFEID)
+			Expect(err).ToNot(HaveOccurred())
+			serverCert, err := tls.LoadX509KeyPair(
+				filepath.Join(testDataDir, "server-cert_pem"),
+				filepath.Join(testDataDir, "server-key_pem"),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nats.VerifyServerCertificate(conf.WithServerSPIFFEID(matcher), serverCert.Certificate)).To(Succeed())
+
+			// a server certificate without a SPIFFE ID does not match
+			_, noIDCert, err := newTestCertFromCA(natsSession.caPrivKey, natsSession.ca)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nats.VerifyServerCertificate(conf, [][]byte{noIDCert})).To(Succeed())
+			err = nats.VerifyServerCertificate(conf.WithServerSPIFFEID(matcher), [][]byte{noIDCert})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("failed to get SPIFFE ID"))
+		})
+	})
+})
+
+var _ = Describe("CA pools", func() {
+	It("caches the CA file until it changes", func() {
+		dir := GinkgoT().TempDir()
+		caFile := filepath.Join(dir, "ca_pem")
+		caPEM, err := os.ReadFile(filepath.Join(testDataDir, "ca_pem"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(caFile, caPEM, 0o600)).To(Succeed())
+
+		pool := nats.NewFileCAPool(caFile)
+		defer func() { Expect(pool.Close()).To(Succeed()) }()
+		first, err := pool.CertPool()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pool.CertPool()).To(BeIdenticalTo(first))
+
+		_, newCA, _, err := newTestCA()
+		Expect(err).ToNot(HaveOccurred())
+		buf, err := encodeCert(newCA)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(caFile+".tmp", buf.Bytes(), 0o600)).To(Succeed())
+		Expect(os.Rename(caFile+".tmp", caFile)).To(Succeed())
+
+		Eventually(pool.CertPool).ShouldNot(BeIdenticalTo(first))
+		second, err := pool.CertPool()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second.Equal(first)).To(BeFalse())
+		Expect(pool.CertPool()).To(BeIdenticalTo(second))
+	})
+
+	It("reads the CA file on every handshake unless a pool is set", func() {
+		dir := GinkgoT().TempDir()
+		caFile := filepath.Join(dir, "ca_pem")
+		caPEM, err := os.ReadFile(filepath.Join(testDataDir, "ca_pem"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(caFile, caPEM, 0o600)).To(Succeed())
+
+		conf := nats.NewOnDiskSecureConfig("localhost", "", "", caFile)
+		first, err := conf.RootCAs()
+		Expect(err).ToNot(HaveOccurred())
+
+		_, newCA, _, err := newTestCA()
+		Expect(err).ToNot(HaveOccurred())
+		buf, err := encodeCert(newCA)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(caFile, buf.Bytes(), 0o600)).To(Succeed())
+
+		second, err := conf.RootCAs()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second.Equal(first)).To(BeFalse())
+	})
+
+	It("holds the root CAs in memory", func() {
+		pool := nats.NewInMemoryCAPool()
+		// the pool can be fed by a CABundleManager
+		var hook spiffe.BundleChangeHook = pool.Update
+
+		_, err := pool.CertPool()
+		Expect(err).To(MatchError(nats.ErrNoCABundle))
+		Expect(hook([]byte("not a bundle"))).To(MatchError(nats.ErrInvalidCABundle))
+
+		caPEM, err := os.ReadFile(filepath.Join(testDataDir, "ca_pem"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hook(caPEM)).To(Succeed())
+
+		clientCert, err := tls.LoadX509KeyPair(
+			filepath.Join(testDataDir, "client-cert_pem"),
+			filepath.Join(testDataDir, "client-key_pem"),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		conf := nats.NewInMemorySecureConfig("localhost", "does-not-exist", func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &clientCert, nil
+		}).WithCAPool(pool)
+		bus, err := nats.NewSecureMessageBus(&conf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bus.Connect(fmt.Sprintf("localhost:%d", natsSession.Port))).To(Succeed())
+		Expect(bus.Close()).To(Succeed())
+	})
+})
+
+func BenchmarkVerifyServerCertificate(b *testing.B) {
+	dir := b.TempDir()
+	caPrivKey, caCert, ca, err := newTestCA()
+	if err != nil {
+		b.Fatal(err)
+	}
+	_, serverCert, err := newTestCertFromCA(caPrivKey, &ca)
+	if err != nil {
+		b.Fatal(err)
+	}
+	buf, err := encodeCert(caCert)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := pemToFile(buf, dir, "ca_pem"); err != nil {
+		b.Fatal(err)
+	}
+
+	conf := nats.NewOnDiskSecureConfig("localhost", "", "", filepath.Join(dir, "ca_pem"))
+	pool := nats.NewFileCAPool(filepath.Join(dir, "ca_pem"))
+	defer pool.Close()
+	for name, conf := range map[string]nats.OnDiskSecureConfig{
+		"cached":   conf.WithCAPool(pool),
+		"uncached": conf,
+	} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := nats.VerifyServerCertificate(conf, [][]byte{serverCert}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+var _ = Describe("NATs messaging", func() {
+	var bus *nats.SecureMessageBus
+
+	newBus := func(port int) *nats.SecureMessageBus {
+		b, err := nats.NewSecureMessageBus(nats.NewOnDiskSecureConfig(
+			"localhost",
+			filepath.Join(testDataDir, "client-cert_pem"),
+			filepath.Join(testDataDir, "client-key_pem"),
+			filepath.Join(testDataDir, "ca_pem"),
+		))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b.Connect(fmt.Sprintf("localhost:%d", port))).To(Succeed())
+
+		return b
+	}
+
+	BeforeEach(func() {
+		bus = newBus(natsSession.Port)
+	})
+	AfterEach(func() {
+		Expect(bus.Close()).To(Succeed())
+	})
+
+	It("delivers each message to one subscription of a queue group", func() {
+		msgCh := make(chan []byte, 10)
+		for i := 0; i < 2; i++ {
+			_, err := bus.SubscribeWithOptions("test.queue", msgCh, nats.WithQueueGroup("agents"))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		for i := 0; i < 5; i++ {
+			Expect(bus.Publish("test.queue", []byte("msg"))).To(Succeed())
+		}
+		Eventually(msgCh).Should(HaveLen(5))
+		Consistently(msgCh).Should(HaveLen(5))
+	})
+
+	It("drops and counts messages when the channel is full", func() {
+		msgCh := make(chan []byte, 1)
+		sub, err := bus.SubscribeWithOptions("test.drop", msgCh, nats.WithDropWhenFull())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sub.Subject()).To(Equal("test.drop"))
+		for i := 0; i < 5; i++ {
+			Expect(bus.Publish("test.drop", []byte("msg"))).To(Succeed())
+		}
+		Eventually(sub.Dropped).Should(Equal(uint64(4)))
+		Expect(msgCh).To(HaveLen(1))
+	})
+
+	It("stops delivering messages after unsubscribing", func() {
+		msgCh := make(chan []byte, 1)
+		sub, err := bus.SubscribeWithOptions("test.unsubscribe", msgCh)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sub.Unsubscribe()).To(Succeed())
+		Expect(bus.Publish("test.unsubscribe", []byte("msg"))).To(Succeed())
+		Consistently(msgCh).ShouldNot(Receive())
+	})
+
+	It("replies to requests", func() {
+		_, err := bus.Respond("test.request", func(data []byte) ([]byte, error) {
+			if len(data) == 0 {
+				return nil, errors.New("empty request")
+			}
+
+			return append([]byte("reply to "), data...), nil
+		}, nats.WithQueueGroup("responders"))
+		Expect(err).ToNot(HaveOccurred())
+
+		reply, err := bus.Request("test.request", []byte("ping"), time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reply).To(Equal([]byte("reply to ping")))
+
+		_, err = bus.Request("test.request", nil, time.Second)
+		var remoteErr nats.RemoteError
+		Expect(errors.As(err, &remoteErr)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("empty request"))
+
+		_, err = bus.Request("test.no-responders", []byte("ping"), time.Second)
+		Expect(err).To(HaveOccurred())
+
+		_, err = bus.Respond("test.request", nil)
+		Expect(err).To(MatchError(nats.ErrNoHandler))
+	})
+
+	It("errors without a connection", func() {
+		noConnBus := &nats.SecureMessageBus{}
+		_, err := noConnBus.SubscribeWithOptions("test.subject", make(chan []byte))
+		Expect(err).To(MatchError(nats.ErrNoConnection))
+		_, err = noConnBus.Request("test.subject", nil, time.Second)
+		Expect(err).To(MatchError(nats.ErrNoConnection))
+		_, err = noConnBus.Respond("test.subject", nil)
+		Expect(err).To(MatchError(nats.ErrNoConnection))
+		Expect(noConnBus.EnableJetStream(nats.JetStreamConfig{})).To(MatchError(nats.ErrNoConnection))
+	})
+
+	Context("with JetStream", func() {
+		var (
+			srv     *server.Server
+			jsBus   *nats.SecureMessageBus
+			jsCfg   = nats.JetStreamConfig{Stream: "agent-config", Subjects: []string{"test.config"}}
+			jsStart = func() int {
+				tlsConfig, err := nats.NewServerTLSConfig(nats.NewOnDiskSecureConfig(
+					"localhost",
+					filepath.Join(testDataDir, "server-cert_pem"),
+					filepath.Join(testDataDir, "server-key_pem"),
+					filepath.Join(testDataDir, "ca_pem"),
+				), []string{clientSPIFFEID})
+				Expect(err).ToNot(HaveOccurred())
+				var port int
+				srv, port = StartMTLSServer(tlsConfig, func(opts *server.Options) {
+					opts.JetStream = true
+					opts.StoreDir = GinkgoT().TempDir()
+				})
+
+				return port
+			}
+		)
+
+		BeforeEach(func() {
+			jsBus = newBus(jsStart())
+		})
+		AfterEach(func() {
+			Expect(jsBus.Close()).To(Succeed())
+			srv.Shutdown()
+		})
+
+		It("delivers the last message of a subject to new subscriptions", func() {
+			Expect(jsBus.EnableJetStream(nats.JetStreamConfig{Stream: "agent-config"})).To(MatchError(nats.ErrNoStream))
+			Expect(jsBus.EnableJetStream(jsCfg)).To(Succeed())
+			// enabling again updates the stream
+			Expect(jsBus.EnableJetStream(jsCfg)).To(Succeed())
+
+			Expect(jsBus.Publish("test.config", []byte("config v1"))).To(Succeed())
+			Expect(jsBus.Publish("test.config", []byte("config v2"))).To(Succeed())
+
+			msgCh := make(chan []byte, 10)
+			sub, err := jsBus.SubscribeWithOptions("test.config", msgCh)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(msgCh).Should(Receive(Equal([]byte("config v2"))))
+			Consistently(msgCh).ShouldNot(Receive())
+
+			Expect(jsBus.Publish("test.config", []byte("config v3"))).To(Succeed())
+			Eventually(msgCh).Should(Receive(Equal([]byte("config v3"))))
+			Expect(sub.Unsubscribe()).To(Succeed())
+
+			// subjects that are not in the stream are not stored
+			otherCh := make(chan []byte, 1)
+			Expect(jsBus.Publish("test.other", []byte("not stored"))).To(Succeed())
+			_, err = jsBus.SubscribeWithOptions("test.other", otherCh)
+			Expect(err).ToNot(HaveOccurred())
+			Consistently(otherCh).ShouldNot(Receive())
+		})
+	})
+})
+
+var _ = Describe("NATs connection state", func() {
+	var (
+		bus       *nats.SecureMessageBus
+		tlsConfig *tls.Config
+	)
+
+	startServer := func(port int) *server.Server {
+		srv, _ := StartMTLSServer(tlsConfig, func(opts *server.Options) {
+			if port != 0 {
+				opts.Port = port
+			}
+		})
+
+		return srv
+	}
+	expectEvent := func(state nats.ConnectionState) {
+		EventuallyWithOffset(1, bus.Events()).Should(Receive(HaveField("State", state)))
+	}
+
+	BeforeEach(func() {
+		var err error
+		tlsConfig, err = nats.NewServerTLSConfig(nats.NewOnDiskSecureConfig(
+			"localhost",
+			filepath.Join(testDataDir, "server-cert_pem"),
+			filepath.Join(testDataDir, "server-key_pem"),
+			filepath.Join(testDataDir, "ca_pem"),
+		), []string{clientSPIFFEID})
+		Expect(err).ToNot(HaveOccurred())
+		bus, err = nats.NewSecureMessageBus(nats.NewOnDiskSecureConfig(
+			"localhost",
+			filepath.Join(testDataDir, "client-cert_pem"),
+			filepath.Join(testDataDir, "client-key_pem"),
+			filepath.Join(testDataDir, "ca_pem"),
+		))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("reports connection events and restores subscriptions after reconnecting", func() {
+		srv := startServer(0)
+		port := srv.Addr().(*net.TCPAddr).Port
+		Expect(bus.Connect(
+			fmt.Sprintf("localhost:%d", port),
+			nats.ReconnectBackoff(10*time.Millisecond, 50*time.Millisecond),
+		)).To(Succeed())
+		expectEvent(nats.StateConnected)
+		Expect(bus.HealthCheck()).To(Succeed())
+		Expect(health.TestMessageBusConnection(bus, 1)).To(Succeed())
+
+		msgCh := make(chan []byte, 1)
+		_, err := bus.SubscribeWithOptions("test.reconnect", msgCh)
+		Expect(err).ToNot(HaveOccurred())
+
+		srv.Shutdown()
+		expectEvent(nats.StateDisconnected)
+		Expect(bus.HealthCheck()).To(MatchError(nats.ErrNoConnection))
+
+		srv = startServer(port)
+		defer srv.Shutdown()
+		expectEvent(nats.StateReconnected)
+		Expect(bus.Subscriptions()).To(Equal([]string{"test.reconnect"}))
+		Expect(bus.Publish("test.reconnect", []byte("after reconnect"))).To(Succeed())
+		Eventually(msgCh).Should(Receive(Equal([]byte("after reconnect"))))
+
+		Expect(bus.Close()).To(Succeed())
+		expectEvent(nats.StateClosed)
+	})
+
+	It("restores subscriptions when connecting again", func() {
+		srv := startServer(0)
+		defer srv.Shutdown()
+		url := srv.ClientURL()
+		Expect(bus.Connect(url)).To(Succeed())
+
+		msgCh := make(chan []byte, 2)
+		sub, err := bus.SubscribeWithOptions("test.restore", msgCh)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = bus.SubscribeWithOptions("test.unsubscribed", msgCh)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bus.Subscriptions()).To(Equal([]string{"test.restore", "test.unsubscribed"}))
+
+		Expect(bus.Connect(url)).To(Succeed())
+		Expect(bus.Subscriptions()).To(Equal([]string{"test.restore", "test.unsubscribed"}))
+		Expect(bus.Conn.NumSubscriptions()).To(Equal(2))
+
+		// the message is received once, on the new connection
+		Expect(bus.Publish("test.restore", []byte("msg"))).To(Succeed())
+		Eventually(msgCh).Should(Receive(Equal([]byte("msg"))))
+		Consistently(msgCh).ShouldNot(Receive())
+
+		Expect(sub.Unsubscribe()).To(Succeed())
+		Expect(bus.Subscriptions()).To(Equal([]string{"test.unsubscribed"}))
+		Expect(bus.Close()).To(Succeed())
+	})
+
+	It("backs off exponentially between reconnect attempts", func() {
+		opts := gonats.GetDefaultOptions()
+		Expect(nats.ReconnectBackoff(100*time.Millisecond, time.Second)(&opts)).To(Succeed())
+		Expect(opts.CustomReconnectDelayCB(1)).To(Equal(100 * time.Millisecond))
+		Expect(opts.CustomReconnectDelayCB(3)).To(Equal(400 * time.Millisecond))
+		Expect(opts.CustomReconnectDelayCB(100)).To(Equal(time.Second))
+	})
+
+	It("fails the health check without a connection", func() {
+		Expect((&nats.SecureMessageBus{}).HealthCheck()).To(MatchError(nats.ErrNoConnection))
+	})
+})
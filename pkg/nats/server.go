@@ -0,0 +1,103 @@
+// Package nats contains the secure message bus implementation for the nats-server.
+package nats
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+)
+
+var (
+	// ErrNoClientCertificate indicates that no certificate was presented by a client.
+	ErrNoClientCertificate = errors.New("client did not present a certificate")
+	// ErrNoAllowedSPIFFEIDs indicates that no SPIFFE IDs are allowed to connect to the server.
+	ErrNoAllowedSPIFFEIDs = errors.New("no allowed SPIFFE IDs provided")
+	// ErrSPIFFEIDNotAllowed indicates that a client presented a SPIFFE ID that is not allowed.
+	ErrSPIFFEIDNotAllowed = errors.New("SPIFFE ID is not allowed")
+)
+
+// NewServerTLSConfig creates a TLS config for a NATS server from the same secure config used by the clients.
+// The server presents the certificate of the secure config, and requires each client to present a certificate
+// that is signed by the CA File of the secure config and has a SPIFFE ID in allowedIDs.
+// The returned config can be set as the TLSConfig of the nats-server options.
+func NewServerTLSConfig(secureConfig SecurableConfig, allowedIDs []string) (*tls.Config, error) {
+	if err := secureConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("%v: %w", ErrInvalidConfig, err) //nolint:errorlint // only one %w allowed
+	}
+	if len(allowedIDs) == 0 {
+		return nil, ErrNoAllowedSPIFFEIDs
+	}
+	allowed, err := parseAllowedIDs(allowedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	clientConfig, err := secureConfig.CreateTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TLS config: %w", err)
+	}
+	// the client certificate of the secure config is the server certificate
+	getCertificate := clientConfig.GetClientCertificate
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS13,
+		GetCertificate: func(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return getCertificate(nil)
+		},
+		// the client certificate is manually verified
+		ClientAuth: tls.RequireAnyClientCert,
+		VerifyPeerCertificate: func(certificates [][]byte, _ [][]*x509.Certificate) error {
+			return verifyClientCertificate(secureConfig, allowed, certificates)
+		},
+	}, nil
+}
+
+// VerifyClientCertificate uses a custom CA File from a securable config to validate a NATS client
+// during connection, and checks that the SPIFFE ID of the client is in allowedIDs.
+func VerifyClientCertificate(conf SecurableConfig, allowedIDs []string, certificates [][]byte) error {
+	allowed, err := parseAllowedIDs(allowedIDs)
+	if err != nil {
+		return err
+	}
+
+	return verifyClientCertificate(conf, allowed, certificates)
+}
+
+func parseAllowedIDs(allowedIDs []string) (map[spiffeid.ID]struct{}, error) {
+	allowed := make(map[spiffeid.ID]struct{}, len(allowedIDs))
+	for _, id := range allowedIDs {
+		spiffeID, err := spiffeid.FromString(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed SPIFFE ID '%s': %w", id, err)
+		}
+		allowed[spiffeID] = struct{}{}
+	}
+
+	return allowed, nil
+}
+
+func verifyClientCertificate(conf SecurableConfig, allowed map[spiffeid.ID]struct{}, certificates [][]byte) error {
+	if len(certificates) == 0 {
+		return ErrNoClientCertificate
+	}
+	leaf, err := verifyCertificateChain(conf, certificates, x509.VerifyOptions{
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return err
+	}
+
+	id, err := x509svid.IDFromCert(leaf)
+	if err != nil {
+		return fmt.Errorf("failed to get SPIFFE ID from client certificate: %w", err)
+	}
+	if _, ok := allowed[id]; !ok {
+		return fmt.Errorf("%w: '%s'", ErrSPIFFEIDNotAllowed, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+// Package nats contains the secure message bus implementation for the nats-server.
+package nats
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+)
+
+// ErrSPIFFEIDMismatch indicates that a peer presented a SPIFFE ID that does not match the expected identity.
+var ErrSPIFFEIDMismatch = errors.New("SPIFFE ID does not match")
+
+// SPIFFEIDMatcher matches the SPIFFE ID URI SAN of a peer certificate.
+// Returns an error if the SPIFFE ID does not match.
+type SPIFFEIDMatcher func(id spiffeid.ID) error
+
+// MatchSPIFFEID matches exactly the SPIFFE ID, i.e. spiffe://example.org/nats-server.
+func MatchSPIFFEID(id string) (SPIFFEIDMatcher, error) {
+	expected, err := spiffeid.FromString(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID '%s': %w", id, err)
+	}
+
+	return func(actual spiffeid.ID) error {
+		if actual != expected {
+			return fmt.Errorf("%w: expected '%s', got '%s'", ErrSPIFFEIDMismatch, expected, actual)
+		}
+
+		return nil
+	}, nil
+}
+
+// MatchTrustDomain matches every SPIFFE ID in the trust domain, i.e. example.org.
+func MatchTrustDomain(trustDomain string) (SPIFFEIDMatcher, error) {
+	expected, err := spiffeid.TrustDomainFromString(trustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust domain '%s': %w", trustDomain, err)
+	}
+
+	return func(actual spiffeid.ID) error {
+		if actual.TrustDomain() != expected {
+			return fmt.Errorf("%w: expected trust domain '%s', got '%s'", ErrSPIFFEIDMismatch, expected, actual)
+		}
+
+		return nil
+	}, nil
+}
+
+// MatchPathPrefix matches the SPIFFE IDs in the trust domain of the prefix whose path is, or is below,
+// the path of the prefix. The path is matched by segment, so spiffe://example.org/nats matches
+// spiffe://example.org/nats/server, but not spiffe://example.org/nats-server.
+func MatchPathPrefix(prefix string) (SPIFFEIDMatcher, error) {
+	expected, err := spiffeid.FromString(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID prefix '%s': %w", prefix, err)
+	}
+
+	return func(actual spiffeid.ID) error {
+		if actual.TrustDomain() != expected.TrustDomain() ||
+			(actual.Path() != expected.Path() && !strings.HasPrefix(actual.Path(), expected.Path()+"/")) {
+			return fmt.Errorf("%w: expected prefix '%s', got '%s'", ErrSPIFFEIDMismatch, expected, actual)
+		}
+
+		return nil
+	}, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -51,6 +52,20 @@ func GetClientMaxBodySizeAnnotation(annotations map[string]string) (string, erro
 	return "", nil
 }
 
+// GetGlobalRateLimitAnnotation returns whether or not a Pod's annotation enables global rate limits.
+func GetGlobalRateLimitAnnotation(annotations map[string]string) (bool, error) {
+	if val, ok := annotations[mesh.GlobalRateLimitAnnotation]; ok {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, fmt.Errorf("Invalid annotation value '%s', defaulting to global rate limits disabled", val)
+		}
+
+		return enabled, nil
+	}
+
+	return false, nil
+}
+
 // GetOwner gets a pod's owner type and name.
 func GetOwner(ctx context.Context, k8sClient client.Client, pod *v1.Pod) (string, string, error) {
 	ownerName := pod.Name
@@ -67,6 +67,22 @@ var _ = Describe("Pod", func() {
 		})
 	})
 
+	Context("returns the global-rate-limit annotation", func() {
+		Specify("if no annotation", func() {
+			Expect(pod.GetGlobalRateLimitAnnotation(nil)).To(BeFalse())
+		})
+		Specify("if annotation is set to true", func() {
+			annotations := map[string]string{mesh.GlobalRateLimitAnnotation: "true"}
+			Expect(pod.GetGlobalRateLimitAnnotation(annotations)).To(BeTrue())
+		})
+		Specify("if bad annotation", func() {
+			annotations := map[string]string{mesh.GlobalRateLimitAnnotation: "badvalue"}
+			val, err := pod.GetGlobalRateLimitAnnotation(annotations)
+			Expect(err).To(HaveOccurred())
+			Expect(val).To(BeFalse())
+		})
+	})
+
 	Context("gets a pod owner", func() {
 		trueVal := true
 		It("has a replicaset-based owner", func() {
@@ -0,0 +1,183 @@
+package sidecar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+)
+
+// ErrConflictingJWT indicates that the policies of a destination configure different JWT validations.
+var ErrConflictingJWT = errors.New("conflicting JWT validation")
+
+// AgentAuthorizationRule is a flattened specs.AuthorizationRule.
+// A rule matches a request when every non-empty field, and every claim condition, matches.
+type AgentAuthorizationRule struct {
+	Claims     []specs.ClaimCondition `json:"claims,omitempty"`
+	Principals []string               `json:"principals,omitempty"`
+	Namespaces []string               `json:"namespaces,omitempty"`
+	Methods    []string               `json:"methods,omitempty"`
+	Paths      []string               `json:"paths,omitempty"`
+}
+
+// AgentAuthorizationPolicy holds all of the authorization rules for a destination.
+type AgentAuthorizationPolicy struct {
+	// JWT configures the validation of tokens whose claims are matched by the rules.
+	JWT *specs.JWTValidation `json:"jwt,omitempty"`
+
+	// Allow rules. If any exist, only requests that match one of them are allowed.
+	Allow []AgentAuthorizationRule `json:"allow,omitempty"`
+
+	// Deny rules. Requests that match one of them are denied, even if they match an allow rule.
+	Deny []AgentAuthorizationRule `json:"deny,omitempty"`
+}
+
+// AuthorizationRequest holds the attributes of a request that authorization rules are matched against.
+type AuthorizationRequest struct {
+	// Claims of the validated JWT of the request.
+	Claims    map[string][]string
+	Principal string
+	Namespace string
+	Method    string
+	Path      string
+}
+
+// AgentAuthorization holds a mapping of destination to the authorization policy of the destination.
+type AgentAuthorization map[string]AgentAuthorizationPolicy
+
+// NewAgentAuthorization returns an initialized map from destination to authorization policy.
+func NewAgentAuthorization() AgentAuthorization {
+	return make(AgentAuthorization)
+}
+
+// Add merges the rules of an AuthorizationPolicySpec into the policy of the destination.
+// Returns an error if the spec configures a JWT validation that differs from the one of the destination,
+// since the claims of all rules of a destination are read from the same token.
+func (a AgentAuthorization) Add(dest string, spec specs.AuthorizationPolicySpec) error {
+	policy := a[dest]
+	if spec.JWT != nil {
+		if policy.JWT != nil && !reflect.DeepEqual(policy.JWT, spec.JWT) {
+			return fmt.Errorf("%w for '%s': issuer '%s' and issuer '%s'", ErrConflictingJWT, dest, policy.JWT.Issuer, spec.JWT.Issuer)
+		}
+		policy.JWT = spec.JWT
+	}
+	for _, rule := range spec.Rules {
+		agentRule := newAgentAuthorizationRule(rule)
+		if spec.Action == specs.AuthorizationActionDeny {
+			policy.Deny = append(policy.Deny, agentRule)
+		} else {
+			policy.Allow = append(policy.Allow, agentRule)
+		}
+	}
+	a[dest] = policy
+
+	return nil
+}
+
+// Keyvals returns the keyval representation of the authorization policies for the agent.
+// Each destination has a keyval with an entry per rule, keyed by the action and index of the rule,
+// i.e. deny/0 and allow/0, with the JSON encoded rule as the value.
+func (a AgentAuthorization) Keyvals() (map[string]AgentKeyval, error) {
+	keyvals := make(map[string]AgentKeyval, len(a))
+	for dest, policy := range a {
+		keyval := make(AgentKeyval, len(policy.Allow)+len(policy.Deny))
+		for action, rules := range map[string][]AgentAuthorizationRule{"allow": policy.Allow, "deny": policy.Deny} {
+			for i, rule := range rules {
+				b, err := json.Marshal(rule)
+				if err != nil {
+					return nil, fmt.Errorf("error marshaling authorization rule for '%s': %w", dest, err)
+				}
+				keyval[fmt.Sprintf("%s/%d", action, i)] = string(b)
+			}
+		}
+		keyvals[dest] = keyval
+	}
+
+	return keyvals, nil
+}
+
+// Authorize returns whether or not a request is allowed by the policy.
+// Deny rules are checked first and override allow rules. If the policy has no allow rules,
+// requests that are not denied are allowed.
+func (p AgentAuthorizationPolicy) Authorize(req AuthorizationRequest) bool {
+	for _, rule := range p.Deny {
+		if rule.matches(req) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, rule := range p.Allow {
+		if rule.matches(req) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newAgentAuthorizationRule(rule specs.AuthorizationRule) AgentAuthorizationRule {
+	var agentRule AgentAuthorizationRule
+	if rule.From != nil {
+		agentRule.Principals = rule.From.Principals
+		agentRule.Namespaces = rule.From.Namespaces
+	}
+	if rule.To != nil {
+		agentRule.Methods = rule.To.Methods
+		agentRule.Paths = rule.To.Paths
+	}
+	agentRule.Claims = rule.When
+
+	return agentRule
+}
+
+func (r AgentAuthorizationRule) matches(req AuthorizationRequest) bool {
+	if len(r.Principals) > 0 && !stringExists(req.Principal, r.Principals) {
+		return false
+	}
+	if len(r.Namespaces) > 0 && !stringExists(req.Namespace, r.Namespaces) {
+		return false
+	}
+	if len(r.Methods) > 0 && !stringExists(req.Method, r.Methods) {
+		return false
+	}
+	if len(r.Paths) > 0 && !pathMatches(req.Path, r.Paths) {
+		return false
+	}
+	for _, cond := range r.Claims {
+		if !claimMatches(req.Claims[cond.Claim], cond.Values) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pathMatches returns whether or not a path matches one of the exact paths or path prefixes ending in *.
+func pathMatches(path string, paths []string) bool {
+	for _, p := range paths {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+func claimMatches(claimValues, values []string) bool {
+	for _, val := range claimValues {
+		if stringExists(val, values) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,150 @@
+package sidecar_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+	"github.com/nginxinc/nginx-service-mesh/pkg/sidecar"
+)
+
+var _ = Describe("Authorization", func() {
+	const (
+		dest   = "default/backend"
+		client = "spiffe://example.org/ns/default/sa/client"
+		admin  = "spiffe://example.org/ns/admin/sa/admin"
+	)
+
+	var authz sidecar.AgentAuthorization
+
+	BeforeEach(func() {
+		authz = sidecar.NewAgentAuthorization()
+		Expect(authz.Add(dest, specs.AuthorizationPolicySpec{
+			Action: specs.AuthorizationActionAllow,
+			Rules: []specs.AuthorizationRule{
+				{
+					From: &specs.AuthorizationSource{Namespaces: []string{"default", "admin"}},
+					To:   &specs.AuthorizationOperation{Methods: []string{"GET"}, Paths: []string{"/api/*"}},
+				},
+				{
+					From: &specs.AuthorizationSource{Principals: []string{admin}},
+				},
+			},
+		})).To(Succeed())
+		Expect(authz.Add(dest, specs.AuthorizationPolicySpec{
+			Action: specs.AuthorizationActionDeny,
+			Rules: []specs.AuthorizationRule{
+				{
+					To:   &specs.AuthorizationOperation{Paths: []string{"/api/internal"}},
+					When: []specs.ClaimCondition{{Claim: "groups", Values: []string{"guests"}}},
+				},
+			},
+			JWT: &specs.JWTValidation{Issuer: "issuer", JWKSURI: "https://issuer/jwks.json"},
+		})).To(Succeed())
+	})
+
+	It("merges policies for a destination", func() {
+		policy := authz[dest]
+		Expect(policy.Allow).To(HaveLen(2))
+		Expect(policy.Deny).To(HaveLen(1))
+		Expect(policy.JWT).ToNot(BeNil())
+		Expect(policy.Deny[0].Claims).To(Equal([]specs.ClaimCondition{{Claim: "groups", Values: []string{"guests"}}}))
+	})
+
+	It("rejects a policy with a different JWT validation for a destination", func() {
+		err := authz.Add(dest, specs.AuthorizationPolicySpec{
+			Action: specs.AuthorizationActionAllow,
+			JWT:    &specs.JWTValidation{Issuer: "other", JWKSURI: "https://other/jwks.json"},
+		})
+		Expect(err).To(MatchError(sidecar.ErrConflictingJWT))
+		Expect(authz[dest].JWT.Issuer).To(Equal("issuer"))
+
+		Expect(authz.Add(dest, specs.AuthorizationPolicySpec{
+			Action: specs.AuthorizationActionAllow,
+			JWT:    &specs.JWTValidation{Issuer: "issuer", JWKSURI: "https://issuer/jwks.json"},
+		})).To(Succeed())
+	})
+
+	It("requires every claim condition of a rule to match", func() {
+		Expect(authz.Add("default/other", specs.AuthorizationPolicySpec{
+			Action: specs.AuthorizationActionAllow,
+			Rules: []specs.AuthorizationRule{
+				{
+					When: []specs.ClaimCondition{
+						{Claim: "groups", Values: []string{"staff"}},
+						{Claim: "groups", Values: []string{"admins"}},
+					},
+				},
+			},
+		})).To(Succeed())
+		policy := authz["default/other"]
+
+		Expect(policy.Authorize(sidecar.AuthorizationRequest{
+			Claims: map[string][]string{"groups": {"staff", "admins"}},
+		})).To(BeTrue())
+		Expect(policy.Authorize(sidecar.AuthorizationRequest{
+			Claims: map[string][]string{"groups": {"staff"}},
+		})).To(BeFalse())
+		Expect(policy.Authorize(sidecar.AuthorizationRequest{
+			Claims: map[string][]string{"groups": {"admins"}},
+		})).To(BeFalse())
+	})
+
+	It("allows requests that match an allow rule", func() {
+		policy := authz[dest]
+		Expect(policy.Authorize(sidecar.AuthorizationRequest{
+			Principal: client, Namespace: "default", Method: "GET", Path: "/api/users",
+		})).To(BeTrue())
+		Expect(policy.Authorize(sidecar.AuthorizationRequest{
+			Principal: admin, Namespace: "admin", Method: "DELETE", Path: "/",
+		})).To(BeTrue())
+	})
+
+	It("denies requests that do not match an allow rule", func() {
+		policy := authz[dest]
+		Expect(policy.Authorize(sidecar.AuthorizationRequest{
+			Principal: client, Namespace: "default", Method: "POST", Path: "/api/users",
+		})).To(BeFalse())
+		Expect(policy.Authorize(sidecar.AuthorizationRequest{
+			Principal: client, Namespace: "other", Method: "GET", Path: "/api/users",
+		})).To(BeFalse())
+		Expect(policy.Authorize(sidecar.AuthorizationRequest{
+			Principal: client, Namespace: "default", Method: "GET", Path: "/",
+		})).To(BeFalse())
+	})
+
+	It("lets deny rules override allow rules", func() {
+		policy := authz[dest]
+		req := sidecar.AuthorizationRequest{
+			Principal: admin,
+			Namespace: "admin",
+			Method:    "GET",
+			Path:      "/api/internal",
+			Claims:    map[string][]string{"groups": {"staff", "guests"}},
+		}
+		Expect(policy.Authorize(req)).To(BeFalse())
+
+		req.Claims = map[string][]string{"groups": {"staff"}}
+		Expect(policy.Authorize(req)).To(BeTrue())
+	})
+
+	It("allows requests when there are only deny rules", func() {
+		policy := sidecar.AgentAuthorizationPolicy{Deny: authz[dest].Deny}
+		Expect(policy.Authorize(sidecar.AuthorizationRequest{Method: "GET", Path: "/"})).To(BeTrue())
+	})
+
+	It("builds the keyvals for the agent", func() {
+		keyvals, err := authz.Keyvals()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keyvals).To(HaveKey(dest))
+		Expect(keyvals[dest]).To(HaveLen(3))
+		Expect(keyvals[dest]).To(HaveKey("allow/0"))
+		Expect(keyvals[dest]).To(HaveKey("allow/1"))
+
+		var rule sidecar.AgentAuthorizationRule
+		Expect(json.Unmarshal([]byte(keyvals[dest]["deny/0"]), &rule)).To(Succeed())
+		Expect(rule.Paths).To(Equal([]string{"/api/internal"}))
+	})
+})
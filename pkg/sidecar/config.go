@@ -2,12 +2,17 @@
 package sidecar
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	split "github.com/servicemeshinterface/smi-controller-sdk/apis/split/v1alpha3"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
 	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+	specsv1alpha2 "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha2"
 )
 
 // Config contains all the configs consumed by the sidecar agent.
@@ -21,13 +26,23 @@ type Config struct {
 	Redirects           AgentKeyval
 	HTTPLBMethods       map[string]string
 	StreamLBMethods     map[string]string
+	SessionAffinities   map[string]mesh.SessionAffinity
+	UpstreamOverrides   map[string]mesh.UpstreamOverrides
 	HTTPUpstreams       map[string][]UpstreamServer
 	StreamUpstreams     map[string][]UpstreamServer
 	HTTPEgressUpstream  *EgressEndpoint
+	ExternalServices    AgentEgress
 	TrafficSplits       map[string]AgentTrafficSplit
+	TrafficMirrors      map[string]AgentTrafficMirror
+	HTTPRewrites        map[string]AgentHTTPRewrite
 	RateLimits          AgentLimit
 	CircuitBreakers     AgentBreaker
+	HealthChecks        AgentHealth
+	RetryPolicies       AgentRetry
+	TimeoutPolicies     AgentTimeout
+	FaultInjections     AgentFault
 	HTTPAccessControl   map[string]AgentKeyval
+	HTTPAuthorization   AgentAuthorization
 	StreamAccessControl map[string]AgentKeyval
 	MeshConfig          mesh.FullMeshConfig
 }
@@ -76,6 +91,8 @@ const (
 	RedirectHealthPort = 8895
 	// RedirectHealthHTTPSPort is the port that redirects HTTPS health probes to the application container.
 	RedirectHealthHTTPSPort = 8896
+	// ZoneSyncPort is the port that sidecar proxies use to synchronize state, such as global rate limits, with each other.
+	ZoneSyncPort = 8910
 )
 
 // AgentKeyval holds the data for configuring a single keyval in the agent.
@@ -84,24 +101,105 @@ type AgentKeyval map[string]string
 // Upstream should correspond to a service DNS name.
 type Upstream struct {
 	Name            string
+	SlowStart       string
 	UpstreamServers []UpstreamServer
+	LBMethod        LBMethod
+	MaxConns        int
 	Block           Block
 }
 
-// UpstreamServer defines an upstream address and port.
+// NewUpstream builds an Upstream that uses the mesh-wide load balancing method
+// unless the Service overrides it.
+func NewUpstream(
+	name string,
+	servers []UpstreamServer,
+	block Block,
+	lbMethod string,
+	overrides mesh.UpstreamOverrides,
+	affinity mesh.SessionAffinity,
+) Upstream {
+	lbMethod = overrides.LBMethodOrDefault(lbMethod)
+
+	return Upstream{
+		Name:            name,
+		UpstreamServers: servers,
+		Block:           block,
+		LBMethod: LBMethod{
+			Method:   lbMethod,
+			Block:    block,
+			Affinity: affinity,
+		},
+		SlowStart: overrides.SlowStart,
+		MaxConns:  overrides.MaxConns,
+	}
+}
+
+// ServerParameters returns the parameters of the NGINX server directive for each upstream server.
+// Slow start is left out if the load balancing method does not support it.
+func (u Upstream) ServerParameters() string {
+	var params string
+	if u.MaxConns > 0 {
+		params += fmt.Sprintf(" max_conns=%d", u.MaxConns)
+	}
+	if u.SlowStart != "" && mesh.SupportsSlowStart(u.LBMethod.Method) && !u.LBMethod.usesHash() {
+		params += " slow_start=" + u.SlowStart
+	}
+
+	return params
+}
+
+// UpstreamServer defines an upstream address and port, and the locality of the server.
 type UpstreamServer struct {
 	Address string `json:"address"`
+	Zone    string `json:"zone,omitempty"`
+	Region  string `json:"region,omitempty"`
 	Port    int32  `json:"port"`
+	// Unhealthy is set for servers whose endpoint is not ready, see EndpointHealthy.
+	Unhealthy bool `json:"unhealthy,omitempty"`
 }
 
 // LBMethod represents a load balancing method for an nginx block.
 type LBMethod struct {
-	Method string
-	Block  Block
+	Affinity mesh.SessionAffinity
+	Method   string
+	Block    Block
 }
 
 // String returns the string representation of an LBMethod.
+// Header and source IP session affinity replace the method with a consistent hash,
+// while cookie session affinity adds a sticky cookie to HTTP upstreams.
 func (lb LBMethod) String() string {
+	switch lb.Affinity.Mode {
+	case mesh.SessionAffinityHeader:
+		if lb.Block == HTTP {
+			return "hash " + headerVariable(lb.Affinity.Key) + " consistent;"
+		}
+	case mesh.SessionAffinitySourceIP:
+		return "hash $remote_addr consistent;"
+	}
+
+	lbMethod := lb.methodString()
+	if lb.Affinity.Mode == mesh.SessionAffinityCookie && lb.Block == HTTP {
+		cookie := lb.Affinity.Key
+		if cookie == "" {
+			cookie = mesh.DefaultSessionAffinityCookie
+		}
+		if lbMethod != "" {
+			lbMethod += " "
+		}
+		lbMethod += "sticky cookie " + cookie + ";"
+	}
+
+	return lbMethod
+}
+
+// usesHash returns whether or not session affinity replaces the method with a hash.
+func (lb LBMethod) usesHash() bool {
+	return lb.Affinity.Mode == mesh.SessionAffinitySourceIP ||
+		(lb.Affinity.Mode == mesh.SessionAffinityHeader && lb.Block == HTTP)
+}
+
+func (lb LBMethod) methodString() string {
 	var lbMethod string
 	if lb.Method != mesh.RoundRobin {
 		lbMethod = lb.Method
@@ -125,6 +223,11 @@ func (lb LBMethod) String() string {
 	return lbMethod
 }
 
+// headerVariable returns the NGINX variable for a request header.
+func headerVariable(header string) string {
+	return "$http_" + strings.ReplaceAll(strings.ToLower(header), "-", "_")
+}
+
 // AgentTrafficSplit mirrors a split.TrafficSplitSpec, but uses
 // a map of specs.HTTPMatch json strings instead of v1.TypedLocalObjectReference,
 // for easier handling by the agent.
@@ -169,6 +272,100 @@ func TrafficSplitBackendExists(backend split.TrafficSplitBackend, backends []spl
 	return false
 }
 
+// AgentTrafficMirror mirrors a specs.TrafficMirrorSpec, but uses
+// a string of specs.HTTPMatch instead of the rules field.
+type AgentTrafficMirror struct {
+	// Service represents the destination service.
+	Service string `json:"service"`
+
+	// Matches is a string representation of a list of specs.HTTPMatch that should be applied to the traffic mirror.
+	Matches string `json:"matches,omitempty"`
+
+	// Mirror is the service that receives the copies of the requests.
+	Mirror specs.MirrorBackend `json:"mirror"`
+
+	// Percent of requests to mirror.
+	Percent int `json:"percent"`
+}
+
+// Equals returns whether or not two AgentTrafficMirrors are equal.
+func (a *AgentTrafficMirror) Equals(mirror AgentTrafficMirror) bool {
+	return a.Service == mirror.Service &&
+		a.Matches == mirror.Matches &&
+		a.Mirror == mirror.Mirror &&
+		a.Percent == mirror.Percent
+}
+
+// AgentHTTPRewrite mirrors a specs.HTTPRewriteSpec, but uses
+// a string of specs.HTTPMatch instead of the rules field.
+type AgentHTTPRewrite struct {
+	// Service represents the destination service.
+	Service string `json:"service"`
+
+	// Matches is a string representation of a list of specs.HTTPMatch that should be applied to the rewrite.
+	Matches string `json:"matches,omitempty"`
+
+	// Path rewrites the path of the request.
+	Path *specs.PathRewrite `json:"path,omitempty"`
+
+	// RequestHeaders modifies the headers of the request.
+	RequestHeaders *specs.HeaderModifier `json:"requestHeaders,omitempty"`
+
+	// ResponseHeaders modifies the headers of the response.
+	ResponseHeaders *specs.HeaderModifier `json:"responseHeaders,omitempty"`
+}
+
+// Equals returns whether or not two AgentHTTPRewrites are equal.
+func (a *AgentHTTPRewrite) Equals(rewrite AgentHTTPRewrite) bool {
+	if a.Service != rewrite.Service || a.Matches != rewrite.Matches {
+		return false
+	}
+	if (a.Path == nil) != (rewrite.Path == nil) || (a.Path != nil && *a.Path != *rewrite.Path) {
+		return false
+	}
+
+	return HeaderModifiersEqual(a.RequestHeaders, rewrite.RequestHeaders) &&
+		HeaderModifiersEqual(a.ResponseHeaders, rewrite.ResponseHeaders)
+}
+
+// HeaderModifiersEqual returns whether or not two HeaderModifiers are equal.
+// The order of the headers to remove is not significant.
+func HeaderModifiersEqual(a, b *specs.HeaderModifier) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if len(a.Set) != len(b.Set) || len(a.Add) != len(b.Add) || len(a.Remove) != len(b.Remove) {
+		return false
+	}
+	for name, val := range a.Set {
+		if other, ok := b.Set[name]; !ok || other != val {
+			return false
+		}
+	}
+	for name, val := range a.Add {
+		if other, ok := b.Add[name]; !ok || other != val {
+			return false
+		}
+	}
+	for _, name := range a.Remove {
+		if !stringExists(name, b.Remove) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func stringExists(s string, list []string) bool {
+	for _, n := range list {
+		if n == s {
+			return true
+		}
+	}
+
+	return false
+}
+
 // NginxDynSplitBackend is the expected backend struct of ngx_http_dyn_split_module.
 type NginxDynSplitBackend struct {
 	Service string `json:"name"`
@@ -201,6 +398,41 @@ type AgentRateLimit struct {
 	// see: https://www.nginx.com/blog/rate-limiting-nginx/#bursts
 	// +optional
 	Burst int `json:"burst,omitempty"`
+
+	// Scope sets whether the rate is enforced per sidecar or shared across all
+	// sidecars of the destination using zone synchronization.
+	// +optional
+	Scope specsv1alpha2.RateLimitScope `json:"scope,omitempty"`
+
+	// Key is the attribute of a request that traffic is counted by. If not set,
+	// traffic is counted by the source address.
+	// +optional
+	Key *specsv1alpha2.RateLimitKey `json:"key,omitempty"`
+}
+
+// IsGlobal returns whether or not the rate limit is shared across all sidecars of the destination.
+func (a AgentRateLimit) IsGlobal() bool {
+	return a.Scope == specsv1alpha2.RateLimitScopeGlobal
+}
+
+// ErrGlobalRateLimitDisabled occurs when a global rate limit applies to a pod that did not opt in to global rate limits.
+var ErrGlobalRateLimitDisabled = errors.New("global rate limits are not enabled for the pod")
+
+// ScopeFor returns the scope that the sidecar of the pod enforces the rate limit with.
+// The sidecar of a pod that did not opt in to global rate limits does not synchronize rate limit state,
+// so a global rate limit falls back to the local scope in it; an error that wraps ErrGlobalRateLimitDisabled
+// is returned with the local scope, so the agent can log a warning.
+func (a AgentRateLimit) ScopeFor(pod Pod) (specsv1alpha2.RateLimitScope, error) {
+	if !a.IsGlobal() {
+		return specsv1alpha2.RateLimitScopeLocal, nil
+	}
+	if !pod.GlobalRateLimit {
+		return specsv1alpha2.RateLimitScopeLocal, fmt.Errorf(
+			"%w: rate limit '%s' is enforced locally in pod '%s/%s', set the '%s' annotation to 'true' to share it",
+			ErrGlobalRateLimitDisabled, a.Name, pod.Namespace, pod.Name, mesh.GlobalRateLimitAnnotation)
+	}
+
+	return specsv1alpha2.RateLimitScopeGlobal, nil
 }
 
 // AgentLimit holds a one-to-one mapping of how the agent will configure
@@ -215,14 +447,170 @@ func NewAgentLimit() AgentLimit {
 // AgentBreaker is a map of destination names to their associated circuit breaker specs.
 type AgentBreaker map[string]specs.CircuitBreakerSpec
 
+// Health check defaults used when the HealthCheckSpec leaves a field unset.
+const (
+	DefaultHealthCheckPath     = "/"
+	DefaultHealthCheckInterval = "5s"
+	DefaultHealthCheckStatus   = "200-399"
+	DefaultHealthCheckPasses   = 1
+	DefaultHealthCheckFails    = 1
+)
+
+// AgentHealthCheck holds the parameters of an NGINX Plus active health check
+// for the upstream servers of a service.
+type AgentHealthCheck struct {
+	// Port to send health checks to. Zero means the port of each upstream server.
+	Port int `json:"port,omitempty"`
+
+	// Path is the request path of the health check.
+	Path string `json:"path"`
+
+	// Interval is the time between two health checks.
+	Interval string `json:"interval"`
+
+	// ExpectedStatus is the status code or range of status codes of a healthy response.
+	ExpectedStatus string `json:"expectedStatus"`
+
+	// Passes is the number of consecutive passed checks before an upstream server is healthy.
+	Passes int `json:"passes"`
+
+	// Fails is the number of consecutive failed checks before an upstream server is unhealthy.
+	Fails int `json:"fails"`
+}
+
+// NewAgentHealthCheck returns an AgentHealthCheck for the spec, with defaults
+// applied to any unset fields.
+func NewAgentHealthCheck(spec specs.HealthCheckSpec) AgentHealthCheck {
+	hc := AgentHealthCheck{
+		Port:           spec.Port,
+		Path:           spec.Path,
+		Interval:       spec.Interval,
+		ExpectedStatus: spec.ExpectedStatus,
+		Passes:         spec.Passes,
+		Fails:          spec.Fails,
+	}
+	if hc.Path == "" {
+		hc.Path = DefaultHealthCheckPath
+	}
+	if hc.Interval == "" {
+		hc.Interval = DefaultHealthCheckInterval
+	}
+	if hc.ExpectedStatus == "" {
+		hc.ExpectedStatus = DefaultHealthCheckStatus
+	}
+	if hc.Passes == 0 {
+		hc.Passes = DefaultHealthCheckPasses
+	}
+	if hc.Fails == 0 {
+		hc.Fails = DefaultHealthCheckFails
+	}
+
+	return hc
+}
+
+// AgentHealth is a map of service names to their associated health checks.
+type AgentHealth map[string]AgentHealthCheck
+
+// AgentRetryPolicy is a wrapper around the RetryPolicySpec that contains a string of
+// specs.HTTPMatch instead of the rules field.
+type AgentRetryPolicy struct {
+	// Matches is a string representation of a list of specs.HTTPMatch that should be applied to the retry policy.
+	Matches string `json:"matches,omitempty"`
+
+	// Sources defines from where traffic should be retried
+	// +optional
+	Sources []v1.ObjectReference `json:"sources,omitempty"`
+
+	// RetryOn is the list of conditions that trigger a retry
+	RetryOn []specs.RetryOn `json:"retryOn,omitempty"`
+
+	// Attempts is the maximum number of attempts, including the first request
+	Attempts int `json:"attempts"`
+
+	// PerTryTimeout is the timeout for each attempt
+	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+
+	// Timeout limits the total time spent retrying a request
+	Timeout string `json:"timeout,omitempty"`
+
+	// Budget limits the share of requests to the destination that can be retries
+	Budget *specs.RetryBudget `json:"budget,omitempty"`
+}
+
+// AgentRetry holds a mapping of destination names to the retry policies
+// the agent will configure for them.
+type AgentRetry map[string][]AgentRetryPolicy
+
+// NewAgentRetry returns an initialized map from dest string to array of retry policies.
+func NewAgentRetry() AgentRetry {
+	return make(map[string][]AgentRetryPolicy)
+}
+
+// AgentTimeoutPolicy is a wrapper around the TimeoutPolicySpec that contains a string of
+// specs.HTTPMatch instead of the rules field.
+type AgentTimeoutPolicy struct {
+	// Matches is a string representation of a list of specs.HTTPMatch that should be applied to the timeout policy.
+	Matches string `json:"matches,omitempty"`
+
+	// Sources defines from where traffic should be subject to the timeouts
+	// +optional
+	Sources []v1.ObjectReference `json:"sources,omitempty"`
+
+	// ConnectTimeout is the timeout for establishing a connection with the destination
+	ConnectTimeout string `json:"connectTimeout,omitempty"`
+
+	// ReadTimeout is the timeout between two successive reads of the response
+	ReadTimeout string `json:"readTimeout,omitempty"`
+
+	// SendTimeout is the timeout between two successive writes of the request
+	SendTimeout string `json:"sendTimeout,omitempty"`
+}
+
+// AgentTimeout holds a mapping of destination names to the timeout policies
+// the agent will configure for them.
+type AgentTimeout map[string][]AgentTimeoutPolicy
+
+// NewAgentTimeout returns an initialized map from dest string to array of timeout policies.
+func NewAgentTimeout() AgentTimeout {
+	return make(map[string][]AgentTimeoutPolicy)
+}
+
+// AgentFaultInjection is a wrapper around the FaultInjectionSpec that contains a string of
+// specs.HTTPMatch instead of the rules field.
+type AgentFaultInjection struct {
+	// Matches is a string representation of a list of specs.HTTPMatch that should be applied to the fault injection.
+	Matches string `json:"matches,omitempty"`
+
+	// Sources defines from where traffic should have faults injected
+	// +optional
+	Sources []v1.ObjectReference `json:"sources,omitempty"`
+
+	// Delay adds latency to a percentage of requests
+	Delay *specs.FaultDelay `json:"delay,omitempty"`
+
+	// Abort fails a percentage of requests with a status code
+	Abort *specs.FaultAbort `json:"abort,omitempty"`
+}
+
+// AgentFault holds a mapping of destination names to the fault injections
+// the agent will configure for them.
+type AgentFault map[string][]AgentFaultInjection
+
+// NewAgentFault returns an initialized map from dest string to array of fault injections.
+func NewAgentFault() AgentFault {
+	return make(map[string][]AgentFaultInjection)
+}
+
 // Egress ports.
 const (
 	EgressSSLPort = 443
 	EgressPort    = 80
 )
 
-// EgressEndpoint contains the DNS name and the upstream servers for egress.
+// EgressEndpoint contains the DNS name and the upstream servers for egress,
+// and the TLS settings if the sidecar originates TLS to the endpoint.
 type EgressEndpoint struct {
+	TLS       *EgressTLS
 	DNSName   string
 	Upstreams []UpstreamServer
 }
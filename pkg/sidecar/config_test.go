@@ -1,10 +1,14 @@
 package sidecar_test
 
 import (
+	"strings"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+	specsv1alpha2 "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha2"
 	"github.com/nginxinc/nginx-service-mesh/pkg/sidecar"
 )
 
@@ -28,4 +32,193 @@ var _ = Describe("Config", func() {
 		lbMethod = sidecar.LBMethod{Block: sidecar.HTTP, Method: mesh.LeastConn}
 		Expect(lbMethod.String()).To(Equal("least_conn;"))
 	})
+
+	It("can build an LBMethod string for every method and block", func() {
+		tests := []struct {
+			method string
+			http   string
+			stream string
+		}{
+			{method: mesh.RoundRobin, http: "", stream: ""},
+			{method: mesh.LeastConn, http: "least_conn;", stream: "least_conn;"},
+			{method: mesh.LeastTime, http: "least_time header;", stream: "least_time first_byte;"},
+			{method: mesh.LeastTimeLastByte, http: "least_time last_byte;", stream: "least_time last_byte;"},
+			{
+				method: mesh.LeastTimeLastByteInflight,
+				http:   "least_time last_byte inflight;",
+				stream: "least_time last_byte inflight;",
+			},
+			{method: mesh.Random, http: "random;", stream: "random;"},
+			{method: mesh.RandomTwo, http: "random two;", stream: "random two;"},
+			{method: mesh.RandomTwoLeastConn, http: "random two least_conn;", stream: "random two least_conn;"},
+			{
+				method: mesh.RandomTwoLeastTime,
+				http:   "random two least_time=header;",
+				stream: "random two least_time=first_byte;",
+			},
+			{
+				method: mesh.RandomTwoLeastTimeLastByte,
+				http:   "random two least_time=last_byte;",
+				stream: "random two least_time=last_byte;",
+			},
+		}
+		Expect(tests).To(HaveLen(len(mesh.LoadBalancingMethods)))
+
+		for _, test := range tests {
+			Expect(mesh.LoadBalancingMethods).To(HaveKey(test.method))
+
+			lbMethod := sidecar.LBMethod{Block: sidecar.HTTP, Method: test.method}
+			Expect(lbMethod.String()).To(Equal(test.http), test.method)
+
+			lbMethod = sidecar.LBMethod{Block: sidecar.Stream, Method: test.method}
+			Expect(lbMethod.String()).To(Equal(test.stream), test.method)
+		}
+	})
+
+	It("can build upstream server parameters for every method and block", func() {
+		overrides := mesh.UpstreamOverrides{SlowStart: "30s", MaxConns: 10}
+		for method := range mesh.LoadBalancingMethods {
+			for _, block := range []sidecar.Block{sidecar.HTTP, sidecar.Stream} {
+				upstream := sidecar.NewUpstream("svc", nil, block, method, overrides, mesh.SessionAffinity{})
+				Expect(upstream.LBMethod).To(Equal(sidecar.LBMethod{Method: method, Block: block}))
+
+				if strings.HasPrefix(method, mesh.Random) {
+					Expect(upstream.ServerParameters()).To(Equal(" max_conns=10"), method)
+				} else {
+					Expect(upstream.ServerParameters()).To(Equal(" max_conns=10 slow_start=30s"), method)
+				}
+			}
+		}
+	})
+
+	It("can override the load balancing method of an upstream", func() {
+		upstream := sidecar.NewUpstream("svc", nil, sidecar.HTTP, mesh.LeastTime, mesh.UpstreamOverrides{}, mesh.SessionAffinity{})
+		Expect(upstream.LBMethod.String()).To(Equal("least_time header;"))
+		Expect(upstream.ServerParameters()).To(BeEmpty())
+
+		overrides := mesh.UpstreamOverrides{LBMethod: mesh.RoundRobin, SlowStart: "1m"}
+		upstream = sidecar.NewUpstream("svc", nil, sidecar.HTTP, mesh.Random, overrides, mesh.SessionAffinity{})
+		Expect(upstream.LBMethod.String()).To(BeEmpty())
+		Expect(upstream.ServerParameters()).To(Equal(" slow_start=1m"))
+
+		// hash session affinity does not support slow start
+		affinity := mesh.SessionAffinity{Mode: mesh.SessionAffinitySourceIP}
+		upstream = sidecar.NewUpstream("svc", nil, sidecar.Stream, mesh.LeastConn, overrides, affinity)
+		Expect(upstream.LBMethod.String()).To(Equal("hash $remote_addr consistent;"))
+		Expect(upstream.ServerParameters()).To(BeEmpty())
+	})
+
+	It("can build an LBMethod string with session affinity", func() {
+		header := mesh.SessionAffinity{Mode: mesh.SessionAffinityHeader, Key: "X-User-ID"}
+		lbMethod := sidecar.LBMethod{Block: sidecar.HTTP, Method: mesh.LeastTime, Affinity: header}
+		Expect(lbMethod.String()).To(Equal("hash $http_x_user_id consistent;"))
+
+		// header affinity is not supported in stream blocks
+		lbMethod = sidecar.LBMethod{Block: sidecar.Stream, Method: mesh.LeastTime, Affinity: header}
+		Expect(lbMethod.String()).To(Equal("least_time first_byte;"))
+
+		sourceIP := mesh.SessionAffinity{Mode: mesh.SessionAffinitySourceIP}
+		lbMethod = sidecar.LBMethod{Block: sidecar.HTTP, Method: mesh.Random, Affinity: sourceIP}
+		Expect(lbMethod.String()).To(Equal("hash $remote_addr consistent;"))
+
+		lbMethod = sidecar.LBMethod{Block: sidecar.Stream, Method: mesh.Random, Affinity: sourceIP}
+		Expect(lbMethod.String()).To(Equal("hash $remote_addr consistent;"))
+
+		cookie := mesh.SessionAffinity{Mode: mesh.SessionAffinityCookie}
+		lbMethod = sidecar.LBMethod{Block: sidecar.HTTP, Method: mesh.LeastConn, Affinity: cookie}
+		Expect(lbMethod.String()).To(Equal("least_conn; sticky cookie nsm_route;"))
+
+		cookie.Key = "session"
+		lbMethod = sidecar.LBMethod{Block: sidecar.HTTP, Method: mesh.RoundRobin, Affinity: cookie}
+		Expect(lbMethod.String()).To(Equal("sticky cookie session;"))
+
+		// cookie affinity is not supported in stream blocks
+		lbMethod = sidecar.LBMethod{Block: sidecar.Stream, Method: mesh.LeastConn, Affinity: cookie}
+		Expect(lbMethod.String()).To(Equal("least_conn;"))
+
+		none := mesh.SessionAffinity{Mode: mesh.SessionAffinityNone}
+		lbMethod = sidecar.LBMethod{Block: sidecar.HTTP, Method: mesh.LeastConn, Affinity: none}
+		Expect(lbMethod.String()).To(Equal("least_conn;"))
+	})
+
+	It("can compare AgentTrafficMirrors", func() {
+		mirror := sidecar.AgentTrafficMirror{
+			Service: "dest-svc",
+			Matches: `[{"pathRegex":"/api"}]`,
+			Mirror:  specs.MirrorBackend{Service: "dest-svc-canary", Port: 8080},
+			Percent: 25,
+		}
+		other := mirror
+		Expect(mirror.Equals(other)).To(BeTrue())
+
+		other.Percent = 50
+		Expect(mirror.Equals(other)).To(BeFalse())
+
+		other = mirror
+		other.Mirror.Port = 80
+		Expect(mirror.Equals(other)).To(BeFalse())
+	})
+
+	It("can compare AgentHTTPRewrites", func() {
+		rewrite := sidecar.AgentHTTPRewrite{
+			Service: "dest-svc",
+			Path:    &specs.PathRewrite{Prefix: "/api/v1", ReplacePrefix: "/v1"},
+			RequestHeaders: &specs.HeaderModifier{
+				Set:    map[string]string{"x-env": "canary"},
+				Remove: []string{"x-debug", "x-trace"},
+			},
+		}
+		other := sidecar.AgentHTTPRewrite{
+			Service: "dest-svc",
+			Path:    &specs.PathRewrite{Prefix: "/api/v1", ReplacePrefix: "/v1"},
+			RequestHeaders: &specs.HeaderModifier{
+				Set:    map[string]string{"x-env": "canary"},
+				Remove: []string{"x-trace", "x-debug"},
+			},
+		}
+		Expect(rewrite.Equals(other)).To(BeTrue())
+
+		other.RequestHeaders.Set["x-env"] = "prod"
+		Expect(rewrite.Equals(other)).To(BeFalse())
+
+		other.RequestHeaders.Set["x-env"] = "canary"
+		other.Path = nil
+		Expect(rewrite.Equals(other)).To(BeFalse())
+
+		other.Path = rewrite.Path
+		other.ResponseHeaders = &specs.HeaderModifier{Add: map[string]string{"x-mirror": "true"}}
+		Expect(rewrite.Equals(other)).To(BeFalse())
+	})
+
+	It("applies health check defaults", func() {
+		hc := sidecar.NewAgentHealthCheck(specs.HealthCheckSpec{Port: 8080, Fails: 3})
+		Expect(hc).To(Equal(sidecar.AgentHealthCheck{
+			Port:           8080,
+			Path:           sidecar.DefaultHealthCheckPath,
+			Interval:       sidecar.DefaultHealthCheckInterval,
+			ExpectedStatus: sidecar.DefaultHealthCheckStatus,
+			Passes:         sidecar.DefaultHealthCheckPasses,
+			Fails:          3,
+		}))
+	})
+
+	It("enforces global rate limits locally in pods that did not opt in", func() {
+		global := sidecar.AgentRateLimit{Name: "100rs", Rate: "100r/s", Scope: specsv1alpha2.RateLimitScopeGlobal}
+		local := sidecar.AgentRateLimit{Name: "100rs", Rate: "100r/s"}
+		pod := sidecar.Pod{Name: "pod", Namespace: "default"}
+
+		scope, err := local.ScopeFor(pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scope).To(Equal(specsv1alpha2.RateLimitScopeLocal))
+
+		scope, err = global.ScopeFor(pod)
+		Expect(err).To(MatchError(sidecar.ErrGlobalRateLimitDisabled))
+		Expect(err.Error()).To(ContainSubstring(mesh.GlobalRateLimitAnnotation))
+		Expect(scope).To(Equal(specsv1alpha2.RateLimitScopeLocal))
+
+		pod.GlobalRateLimit = true
+		scope, err = global.ScopeFor(pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scope).To(Equal(specsv1alpha2.RateLimitScopeGlobal))
+	})
 })
@@ -0,0 +1,283 @@
+package sidecar
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+)
+
+// caBundleKey is the key of the CA bundle in a Secret.
+const caBundleKey = "ca.crt"
+
+// EgressTLS holds the settings the sidecar uses to originate TLS to an external service.
+// The CA bundle is PEM encoded. The sidecar does not present a client certificate to the external service.
+type EgressTLS struct {
+	SNI      string `json:"sni,omitempty"`
+	CABundle string `json:"caBundle,omitempty"`
+	// InsecureSkipVerify disables the verification of the external service.
+	// Otherwise it is verified with the CA bundle, or the system root CAs if no CA bundle is set.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// NewEgressTLS builds the EgressTLS of a TLSOrigination, reading the CA bundle from the referenced Secret.
+// The secrets map holds the Secrets in the namespace of the TLSOrigination by name.
+func NewEgressTLS(origination *specs.TLSOrigination, secrets map[string]*v1.Secret) (*EgressTLS, error) {
+	if origination == nil {
+		return nil, nil
+	}
+
+	tls := &EgressTLS{SNI: origination.SNI, InsecureSkipVerify: origination.InsecureSkipVerify}
+	if name := origination.CACertificateSecret; name != "" {
+		caBundle, err := secretData(secrets, name, caBundleKey)
+		if err != nil {
+			return nil, err
+		}
+		tls.CABundle = caBundle
+	}
+
+	return tls, nil
+}
+
+// secretData returns the value of a key in a Secret.
+func secretData(secrets map[string]*v1.Secret, name, key string) (string, error) {
+	secret, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret '%s' not found", name)
+	}
+	val, ok := secret.Data[key]
+	if !ok || len(val) == 0 {
+		return "", fmt.Errorf("secret '%s' does not contain '%s'", name, key)
+	}
+
+	return string(val), nil
+}
+
+// AgentExternalServicePort is a port of an external host that workloads are allowed to reach.
+type AgentExternalServicePort struct {
+	// created is the creation time of the ExternalService, used to resolve conflicts.
+	created metav1.Time
+	TLS     *EgressTLS `json:"tls,omitempty"`
+	// ExternalService is the namespace/name of the ExternalService that registered the port.
+	ExternalService string `json:"externalService,omitempty"`
+	Protocol        string `json:"protocol"`
+	// Namespaces are the namespaces whose workloads are allowed to reach the port, or "*" for all namespaces.
+	Namespaces []string `json:"namespaces"`
+	Port       int32    `json:"port"`
+}
+
+// externalServiceKeyval is the keyval entry of an external service port.
+type externalServiceKeyval struct {
+	SNI          string `json:"sni,omitempty"`
+	Protocol     string `json:"protocol"`
+	Port         int32  `json:"port"`
+	OriginateTLS bool   `json:"originateTLS,omitempty"`
+	VerifyServer bool   `json:"verifyServer,omitempty"`
+	CustomCA     bool   `json:"customCA,omitempty"`
+}
+
+// AgentEgress holds a mapping of external host to the ports of the host that workloads are allowed to reach.
+// Hosts can start with a wildcard label, i.e. *.example.com.
+type AgentEgress map[string][]AgentExternalServicePort
+
+// NewAgentEgress returns an initialized map from external host to ports.
+func NewAgentEgress() AgentEgress {
+	return make(AgentEgress)
+}
+
+// Add registers the hosts and ports of an ExternalService.
+// Only the workloads of the namespace of the ExternalService and of the namespaces in its exportTo list
+// are allowed to reach the hosts and ports.
+// The secrets map holds the Secrets in the namespace of the ExternalService by name.
+// If more than one ExternalService that a namespace sees sets the same host and port, the workloads of the
+// namespace use the oldest one; if they are the same age, the first one by namespace/name.
+// The result does not depend on the order of Add.
+func (e AgentEgress) Add(svc specs.ExternalService, secrets map[string]*v1.Secret) error {
+	spec := svc.Spec
+	namespaces := exportNamespaces(svc)
+	ports := make([]AgentExternalServicePort, 0, len(spec.Ports))
+	for _, port := range spec.Ports {
+		tls, err := NewEgressTLS(port.TLS, secrets)
+		if err != nil {
+			return fmt.Errorf("error getting TLS origination for port %d: %w", port.Number, err)
+		}
+		ports = append(ports, AgentExternalServicePort{
+			created:         svc.CreationTimestamp,
+			TLS:             tls,
+			ExternalService: svc.Namespace + "/" + svc.Name,
+			Protocol:        string(port.Protocol),
+			Namespaces:      namespaces,
+			Port:            port.Number,
+		})
+	}
+	for _, host := range spec.Hosts {
+		host = strings.ToLower(host)
+		for _, port := range ports {
+			e.add(host, port)
+		}
+	}
+
+	return nil
+}
+
+// exportNamespaces returns the namespaces that an ExternalService is exported to, sorted and without duplicates.
+func exportNamespaces(svc specs.ExternalService) []string {
+	set := map[string]struct{}{svc.Namespace: {}}
+	for _, namespace := range svc.Spec.ExportTo {
+		if namespace == specs.ExportToAll {
+			return []string{specs.ExportToAll}
+		}
+		set[namespace] = struct{}{}
+	}
+	namespaces := make([]string, 0, len(set))
+	for namespace := range set {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// add adds the port to the host. A port with the same number that the port takes precedence over in all
+// of its namespaces is removed, and the port is not added if another port takes precedence over it.
+func (e AgentEgress) add(host string, port AgentExternalServicePort) {
+	ports := make([]AgentExternalServicePort, 0, len(e[host])+1)
+	for _, p := range e[host] {
+		if p.Port == port.Port {
+			if p.shadows(port) {
+				return
+			}
+			if port.shadows(p) {
+				continue
+			}
+		}
+		ports = append(ports, p)
+	}
+	e[host] = append(ports, port)
+}
+
+// Keyvals returns the keyval representation of the external services that the workloads of a namespace
+// are allowed to reach. Each entry is keyed by host:port, i.e. api.example.com:443, with the JSON encoded port
+// as the value. The value only tells the agent which TLS settings are set; the CA bundle is left out.
+func (e AgentEgress) Keyvals(namespace string) (AgentKeyval, error) {
+	keyval := make(AgentKeyval)
+	for host, ports := range e {
+		for _, p := range ports {
+			port, ok := e.port(namespace, host, p.Port)
+			if !ok || port.ExternalService != p.ExternalService {
+				continue
+			}
+			b, err := json.Marshal(port.keyval())
+			if err != nil {
+				return nil, fmt.Errorf("error marshaling external service port for '%s': %w", host, err)
+			}
+			keyval[net.JoinHostPort(host, strconv.Itoa(int(port.Port)))] = string(b)
+		}
+	}
+
+	return keyval, nil
+}
+
+// Lookup returns the external service port of a host that the workloads of a namespace are allowed to reach.
+// An exact host takes precedence over a wildcard host, and longer wildcard hosts take precedence over shorter ones.
+func (e AgentEgress) Lookup(namespace, host string, port int32) (AgentExternalServicePort, bool) {
+	host = strings.ToLower(host)
+	if p, ok := e.port(namespace, host, port); ok {
+		return p, true
+	}
+	// walk up the labels of the host, i.e. *.api.example.com then *.example.com
+	for suffix := host; ; {
+		i := strings.Index(suffix, ".")
+		if i == -1 {
+			break
+		}
+		suffix = suffix[i+1:]
+		if p, ok := e.port(namespace, "*."+suffix, port); ok {
+			return p, true
+		}
+	}
+
+	return AgentExternalServicePort{}, false
+}
+
+// EgressAllowed returns whether or not a workload of a namespace is allowed to send traffic to a host and port
+// outside of the mesh. All traffic is allowed unless the egress mode is strict, in which case only the external
+// services that are exported to the namespace are allowed.
+func (e AgentEgress) EgressAllowed(egressMode, namespace, host string, port int32) bool {
+	if egressMode != mesh.EgressModeStrict {
+		return true
+	}
+	_, ok := e.Lookup(namespace, host, port)
+
+	return ok
+}
+
+// port returns the port of the host that takes precedence in the namespace.
+func (e AgentEgress) port(namespace, host string, port int32) (AgentExternalServicePort, bool) {
+	var (
+		found AgentExternalServicePort
+		ok    bool
+	)
+	for _, p := range e[host] {
+		if p.Port == port && p.exportedTo(namespace) && (!ok || p.precedes(found)) {
+			found, ok = p, true
+		}
+	}
+
+	return found, ok
+}
+
+// exportedTo returns whether or not the workloads of the namespace are allowed to reach the port.
+func (p AgentExternalServicePort) exportedTo(namespace string) bool {
+	for _, ns := range p.Namespaces {
+		if ns == specs.ExportToAll || ns == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shadows returns whether or not the port takes precedence over the other port in all of its namespaces.
+func (p AgentExternalServicePort) shadows(other AgentExternalServicePort) bool {
+	if !p.precedes(other) {
+		return false
+	}
+	for _, ns := range other.Namespaces {
+		if !p.exportedTo(ns) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// precedes returns whether or not the ExternalService of the port is older than the one of the other port,
+// or the same age and first by namespace/name.
+func (p AgentExternalServicePort) precedes(other AgentExternalServicePort) bool {
+	if !p.created.Equal(&other.created) {
+		return p.created.Before(&other.created)
+	}
+
+	return p.ExternalService < other.ExternalService
+}
+
+func (p AgentExternalServicePort) keyval() externalServiceKeyval {
+	kv := externalServiceKeyval{Protocol: p.Protocol, Port: p.Port}
+	if p.TLS != nil {
+		kv.OriginateTLS = true
+		kv.SNI = p.TLS.SNI
+		kv.VerifyServer = !p.TLS.InsecureSkipVerify
+		kv.CustomCA = p.TLS.CABundle != ""
+	}
+
+	return kv
+}
@@ -0,0 +1,234 @@
+package sidecar_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
+	specs "github.com/nginxinc/nginx-service-mesh/pkg/apis/specs/v1alpha1"
+	"github.com/nginxinc/nginx-service-mesh/pkg/sidecar"
+)
+
+var _ = Describe("Egress", func() {
+	var egress sidecar.AgentEgress
+
+	BeforeEach(func() {
+		egress = sidecar.NewAgentEgress()
+		Expect(egress.Add(externalService("api", specs.ExternalServiceSpec{
+			Hosts: []string{"API.example.com"},
+			Ports: []specs.ExternalServicePort{
+				{Number: 443, Protocol: specs.ExternalServiceProtocolHTTPS},
+				{Number: 80, Protocol: specs.ExternalServiceProtocolHTTP, TLS: &specs.TLSOrigination{SNI: "api.example.com"}},
+			},
+		}), nil)).To(Succeed())
+		Expect(egress.Add(externalService("wildcard", specs.ExternalServiceSpec{
+			Hosts: []string{"*.example.com", "api.example.com"},
+			Ports: []specs.ExternalServicePort{
+				{Number: 443, Protocol: specs.ExternalServiceProtocolTLS},
+			},
+		}), nil)).To(Succeed())
+	})
+
+	It("adds the hosts and ports of external services", func() {
+		Expect(egress).To(HaveLen(2))
+		Expect(egress["api.example.com"]).To(HaveLen(2))
+		Expect(egress["*.example.com"]).To(HaveLen(1))
+		Expect(egress["*.example.com"][0].Protocol).To(Equal("TLS"))
+		Expect(egress["*.example.com"][0].Port).To(BeEquivalentTo(443))
+		Expect(egress["*.example.com"][0].ExternalService).To(Equal("default/wildcard"))
+	})
+
+	It("keeps the port of the oldest external service, then the first by name", func() {
+		older := externalService("newer-name", specs.ExternalServiceSpec{
+			Hosts: []string{"www.example.net"},
+			Ports: []specs.ExternalServicePort{{Number: 443, Protocol: specs.ExternalServiceProtocolTLS}},
+		})
+		older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+		newer := externalService("a-newer", specs.ExternalServiceSpec{
+			Hosts: []string{"www.example.net"},
+			Ports: []specs.ExternalServicePort{{Number: 443, Protocol: specs.ExternalServiceProtocolHTTPS}},
+		})
+		newer.CreationTimestamp = metav1.Now()
+		tied := externalService("z-tied", newer.Spec)
+		tied.CreationTimestamp = newer.CreationTimestamp
+
+		for _, order := range [][]specs.ExternalService{{older, newer}, {newer, older}} {
+			e := sidecar.NewAgentEgress()
+			for _, svc := range order {
+				Expect(e.Add(svc, nil)).To(Succeed())
+			}
+			port, ok := e.Lookup("default", "www.example.net", 443)
+			Expect(ok).To(BeTrue())
+			Expect(port.ExternalService).To(Equal("default/newer-name"))
+		}
+
+		for _, order := range [][]specs.ExternalService{{tied, newer}, {newer, tied}} {
+			e := sidecar.NewAgentEgress()
+			for _, svc := range order {
+				Expect(e.Add(svc, nil)).To(Succeed())
+			}
+			port, ok := e.Lookup("default", "www.example.net", 443)
+			Expect(ok).To(BeTrue())
+			Expect(port.ExternalService).To(Equal("default/a-newer"))
+		}
+	})
+
+	It("looks up exact hosts before wildcard hosts", func() {
+		port, ok := egress.Lookup("default", "api.example.com", 443)
+		Expect(ok).To(BeTrue())
+		Expect(port.Protocol).To(Equal("HTTPS"))
+
+		port, ok = egress.Lookup("default", "v1.API.example.com", 443)
+		Expect(ok).To(BeTrue())
+		Expect(port.Protocol).To(Equal("TLS"))
+
+		_, ok = egress.Lookup("default", "example.com", 443)
+		Expect(ok).To(BeFalse())
+
+		_, ok = egress.Lookup("default", "www.example.com", 80)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("only allows registered external services in strict mode", func() {
+		Expect(egress.EgressAllowed(mesh.EgressModeAllowAll, "default", "www.example.org", 443)).To(BeTrue())
+		Expect(egress.EgressAllowed(mesh.EgressModeStrict, "default", "www.example.org", 443)).To(BeFalse())
+		Expect(egress.EgressAllowed(mesh.EgressModeStrict, "default", "www.example.com", 443)).To(BeTrue())
+		Expect(egress.EgressAllowed(mesh.EgressModeStrict, "default", "api.example.com", 8080)).To(BeFalse())
+	})
+
+	It("only allows the workloads of the namespaces the external service is exported to", func() {
+		local := externalService("local", specs.ExternalServiceSpec{
+			Hosts: []string{"db.example.net"},
+			Ports: []specs.ExternalServicePort{{Number: 5432, Protocol: specs.ExternalServiceProtocolTCP}},
+		})
+		local.Namespace = "team-a"
+		local.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Minute))
+		exported := externalService("exported", specs.ExternalServiceSpec{
+			Hosts:    []string{"db.example.net"},
+			Ports:    []specs.ExternalServicePort{{Number: 5432, Protocol: specs.ExternalServiceProtocolTLS}},
+			ExportTo: []string{"team-b"},
+		})
+		exported.Namespace = "team-c"
+		exported.CreationTimestamp = metav1.Now()
+		Expect(egress.Add(local, nil)).To(Succeed())
+		Expect(egress.Add(exported, nil)).To(Succeed())
+
+		Expect(egress.EgressAllowed(mesh.EgressModeStrict, "team-a", "db.example.net", 5432)).To(BeTrue())
+		Expect(egress.EgressAllowed(mesh.EgressModeStrict, "team-b", "db.example.net", 5432)).To(BeTrue())
+		Expect(egress.EgressAllowed(mesh.EgressModeStrict, "team-c", "db.example.net", 5432)).To(BeTrue())
+		Expect(egress.EgressAllowed(mesh.EgressModeStrict, "default", "db.example.net", 5432)).To(BeFalse())
+		Expect(egress.EgressAllowed(mesh.EgressModeStrict, "team-a", "api.example.com", 443)).To(BeFalse())
+
+		// the older ExternalService of team-a does not hide the one of team-c from team-b
+		port, ok := egress.Lookup("team-b", "db.example.net", 5432)
+		Expect(ok).To(BeTrue())
+		Expect(port.ExternalService).To(Equal("team-c/exported"))
+		port, ok = egress.Lookup("team-a", "db.example.net", 5432)
+		Expect(ok).To(BeTrue())
+		Expect(port.ExternalService).To(Equal("team-a/local"))
+
+		keyval, err := egress.Keyvals("team-b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keyval).To(HaveLen(1))
+		Expect(keyval["db.example.net:5432"]).To(MatchJSON(`{"protocol": "TLS", "port": 5432}`))
+
+		all := externalService("all", specs.ExternalServiceSpec{
+			Hosts:    []string{"db.example.net"},
+			Ports:    []specs.ExternalServicePort{{Number: 5432, Protocol: specs.ExternalServiceProtocolTCP}},
+			ExportTo: []string{"team-a", specs.ExportToAll},
+		})
+		all.Namespace = "infra"
+		all.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+		Expect(egress.Add(all, nil)).To(Succeed())
+		Expect(egress["db.example.net"]).To(HaveLen(1))
+		Expect(egress["db.example.net"][0].Namespaces).To(Equal([]string{specs.ExportToAll}))
+		Expect(egress.EgressAllowed(mesh.EgressModeStrict, "default", "db.example.net", 5432)).To(BeTrue())
+	})
+
+	It("builds the keyvals for the agent", func() {
+		keyval, err := egress.Keyvals("default")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keyval).To(HaveKey("api.example.com:80"))
+		Expect(keyval).To(HaveKey("*.example.com:443"))
+
+		Expect(keyval["api.example.com:80"]).To(MatchJSON(
+			`{"protocol": "HTTP", "port": 80, "originateTLS": true, "verifyServer": true, "sni": "api.example.com"}`,
+		))
+	})
+
+	Context("TLS origination", func() {
+		secrets := map[string]*v1.Secret{
+			"ca":    {Data: map[string][]byte{"ca.crt": []byte("ca-pem")}},
+			"empty": {Data: map[string][]byte{"ca.crt": nil}},
+		}
+
+		It("reads the CA bundle", func() {
+			tls, err := sidecar.NewEgressTLS(nil, secrets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tls).To(BeNil())
+
+			tls, err = sidecar.NewEgressTLS(&specs.TLSOrigination{
+				SNI:                 "api.example.com",
+				CACertificateSecret: "ca",
+			}, secrets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*tls).To(Equal(sidecar.EgressTLS{
+				SNI:      "api.example.com",
+				CABundle: "ca-pem",
+			}))
+		})
+
+		It("returns an error if a secret or key is missing", func() {
+			_, err := sidecar.NewEgressTLS(&specs.TLSOrigination{CACertificateSecret: "missing"}, secrets)
+			Expect(err).To(HaveOccurred())
+
+			_, err = sidecar.NewEgressTLS(&specs.TLSOrigination{CACertificateSecret: "empty"}, secrets)
+			Expect(err).To(HaveOccurred())
+
+			err = egress.Add(externalService("org", specs.ExternalServiceSpec{
+				Hosts: []string{"www.example.org"},
+				Ports: []specs.ExternalServicePort{
+					{Number: 80, Protocol: specs.ExternalServiceProtocolHTTP, TLS: &specs.TLSOrigination{CACertificateSecret: "missing"}},
+				},
+			}), secrets)
+			Expect(err).To(HaveOccurred())
+			Expect(egress).ToNot(HaveKey("www.example.org"))
+		})
+
+		It("only tells the agent which TLS settings are set", func() {
+			Expect(egress.Add(externalService("org", specs.ExternalServiceSpec{
+				Hosts: []string{"www.example.org"},
+				Ports: []specs.ExternalServicePort{
+					{
+						Number:   80,
+						Protocol: specs.ExternalServiceProtocolHTTP,
+						TLS:      &specs.TLSOrigination{CACertificateSecret: "ca"},
+					},
+					{
+						Number:   8080,
+						Protocol: specs.ExternalServiceProtocolHTTP,
+						TLS:      &specs.TLSOrigination{InsecureSkipVerify: true},
+					},
+				},
+			}), secrets)).To(Succeed())
+
+			keyval, err := egress.Keyvals("default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keyval["www.example.org:80"]).To(MatchJSON(
+				`{"protocol": "HTTP", "port": 80, "originateTLS": true, "verifyServer": true, "customCA": true}`,
+			))
+			Expect(keyval["www.example.org:8080"]).To(MatchJSON(`{"protocol": "HTTP", "port": 8080, "originateTLS": true}`))
+		})
+	})
+})
+
+func externalService(name string, spec specs.ExternalServiceSpec) specs.ExternalService {
+	return specs.ExternalService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       spec,
+	}
+}
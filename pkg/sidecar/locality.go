@@ -0,0 +1,83 @@
+package sidecar
+
+import (
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+
+	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
+)
+
+const percentBase = 100
+
+// NodeLocality returns the zone and region of a node from its well-known topology labels.
+func NodeLocality(node *v1.Node) (zone, region string) {
+	if node == nil {
+		return "", ""
+	}
+
+	return node.Labels[v1.LabelTopologyZone], node.Labels[v1.LabelTopologyRegion]
+}
+
+// EndpointLocality returns the zone and region of an EndpointSlice endpoint.
+// The zone comes from the endpoint itself, then from its topology hints, and finally
+// from the node that the endpoint runs on. The region always comes from the node.
+func EndpointLocality(endpoint discovery.Endpoint, node *v1.Node) (zone, region string) {
+	zone, region = NodeLocality(node)
+	if endpoint.Hints != nil && len(endpoint.Hints.ForZones) == 1 {
+		zone = endpoint.Hints.ForZones[0].Name
+	}
+	if endpoint.Zone != nil && *endpoint.Zone != "" {
+		zone = *endpoint.Zone
+	}
+
+	return zone, region
+}
+
+// EndpointHealthy returns whether or not an EndpointSlice endpoint is ready to receive traffic.
+// An endpoint with no ready condition is considered ready, as by kube-proxy.
+func EndpointHealthy(endpoint discovery.Endpoint) bool {
+	return endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+}
+
+// LocalUpstreamServers returns the upstream servers that traffic from the given zone and region
+// should be sent to. Servers in the same locality are preferred as long as at least FailoverThreshold
+// percent of them are healthy; otherwise traffic fails over to all servers. The share of the local servers
+// among all servers does not matter, so that evenly spread localities still keep traffic local.
+// Servers are returned as-is if locality is disabled or the client's locality is unknown.
+func LocalUpstreamServers(servers []UpstreamServer, locality mesh.Locality, zone, region string) []UpstreamServer {
+	var local []UpstreamServer
+	switch locality.Mode {
+	case mesh.LocalityModeZone:
+		if zone == "" {
+			return servers
+		}
+		for _, server := range servers {
+			if server.Zone == zone {
+				local = append(local, server)
+			}
+		}
+	case mesh.LocalityModeRegion:
+		if region == "" {
+			return servers
+		}
+		for _, server := range servers {
+			if server.Region == region {
+				local = append(local, server)
+			}
+		}
+	default:
+		return servers
+	}
+
+	healthy := 0
+	for _, server := range local {
+		if !server.Unhealthy {
+			healthy++
+		}
+	}
+	if healthy == 0 || healthy*percentBase < len(local)*locality.FailoverThreshold {
+		return servers
+	}
+
+	return local
+}
@@ -0,0 +1,138 @@
+package sidecar_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nginxinc/nginx-service-mesh/pkg/apis/mesh"
+	"github.com/nginxinc/nginx-service-mesh/pkg/sidecar"
+)
+
+var _ = Describe("Locality", func() {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node",
+			Labels: map[string]string{
+				v1.LabelTopologyZone:   "us-east-1a",
+				v1.LabelTopologyRegion: "us-east-1",
+			},
+		},
+	}
+
+	Context("EndpointLocality", func() {
+		It("uses the node labels", func() {
+			zone, region := sidecar.EndpointLocality(discovery.Endpoint{}, node)
+			Expect(zone).To(Equal("us-east-1a"))
+			Expect(region).To(Equal("us-east-1"))
+		})
+
+		It("prefers the endpoint hints over the node", func() {
+			endpoint := discovery.Endpoint{
+				Hints: &discovery.EndpointHints{ForZones: []discovery.ForZone{{Name: "us-east-1b"}}},
+			}
+			zone, region := sidecar.EndpointLocality(endpoint, node)
+			Expect(zone).To(Equal("us-east-1b"))
+			Expect(region).To(Equal("us-east-1"))
+		})
+
+		It("prefers the endpoint zone over the hints", func() {
+			endpointZone := "us-east-1c"
+			endpoint := discovery.Endpoint{
+				Zone:  &endpointZone,
+				Hints: &discovery.EndpointHints{ForZones: []discovery.ForZone{{Name: "us-east-1b"}}},
+			}
+			zone, _ := sidecar.EndpointLocality(endpoint, node)
+			Expect(zone).To(Equal("us-east-1c"))
+		})
+
+		It("considers endpoints without a ready condition healthy", func() {
+			ready, notReady := true, false
+			Expect(sidecar.EndpointHealthy(discovery.Endpoint{})).To(BeTrue())
+			Expect(sidecar.EndpointHealthy(discovery.Endpoint{Conditions: discovery.EndpointConditions{Ready: &ready}})).To(BeTrue())
+			Expect(sidecar.EndpointHealthy(discovery.Endpoint{Conditions: discovery.EndpointConditions{Ready: &notReady}})).To(BeFalse())
+		})
+
+		It("handles a missing node", func() {
+			zone, region := sidecar.EndpointLocality(discovery.Endpoint{}, nil)
+			Expect(zone).To(BeEmpty())
+			Expect(region).To(BeEmpty())
+		})
+	})
+
+	Context("LocalUpstreamServers", func() {
+		servers := []sidecar.UpstreamServer{
+			{Address: "1.1.1.1", Port: 80, Zone: "us-east-1a", Region: "us-east-1"},
+			{Address: "2.2.2.2", Port: 80, Zone: "us-east-1b", Region: "us-east-1"},
+			{Address: "3.3.3.3", Port: 80, Zone: "us-west-1a", Region: "us-west-1"},
+			{Address: "4.4.4.4", Port: 80, Zone: "us-west-1a", Region: "us-west-1"},
+		}
+
+		It("returns all servers when locality is disabled", func() {
+			locality := mesh.Locality{Mode: mesh.LocalityModeNone, FailoverThreshold: 50}
+			Expect(sidecar.LocalUpstreamServers(servers, locality, "us-east-1a", "us-east-1")).To(Equal(servers))
+		})
+
+		It("returns all servers when the client locality is unknown", func() {
+			locality := mesh.Locality{Mode: mesh.LocalityModeZone, FailoverThreshold: 0}
+			Expect(sidecar.LocalUpstreamServers(servers, locality, "", "")).To(Equal(servers))
+		})
+
+		It("prefers servers in the same zone", func() {
+			locality := mesh.Locality{Mode: mesh.LocalityModeZone, FailoverThreshold: 50}
+			Expect(sidecar.LocalUpstreamServers(servers, locality, "us-west-1a", "us-west-1")).
+				To(Equal(servers[2:]))
+		})
+
+		It("prefers servers in the same region", func() {
+			locality := mesh.Locality{Mode: mesh.LocalityModeRegion, FailoverThreshold: 50}
+			Expect(sidecar.LocalUpstreamServers(servers, locality, "us-east-1a", "us-east-1")).
+				To(Equal(servers[:2]))
+		})
+
+		It("keeps traffic local with evenly spread zones and the default threshold", func() {
+			spread := []sidecar.UpstreamServer{
+				{Address: "1.1.1.1", Port: 80, Zone: "us-east-1a"},
+				{Address: "2.2.2.2", Port: 80, Zone: "us-east-1b"},
+				{Address: "3.3.3.3", Port: 80, Zone: "us-east-1c"},
+			}
+			locality := mesh.Locality{Mode: mesh.LocalityModeZone, FailoverThreshold: mesh.DefaultLocalityFailoverThreshold}
+			for _, server := range spread {
+				Expect(sidecar.LocalUpstreamServers(spread, locality, server.Zone, "us-east-1")).
+					To(Equal([]sidecar.UpstreamServer{server}))
+			}
+		})
+
+		It("fails over when too few local servers are healthy", func() {
+			unhealthy := []sidecar.UpstreamServer{
+				servers[0],
+				servers[1],
+				{Address: "3.3.3.3", Port: 80, Zone: "us-west-1a", Region: "us-west-1", Unhealthy: true},
+				servers[3],
+				{Address: "5.5.5.5", Port: 80, Zone: "us-west-1a", Region: "us-west-1", Unhealthy: true},
+			}
+			locality := mesh.Locality{Mode: mesh.LocalityModeZone, FailoverThreshold: 50}
+			Expect(sidecar.LocalUpstreamServers(unhealthy, locality, "us-west-1a", "us-west-1")).To(Equal(unhealthy))
+
+			locality.FailoverThreshold = 30
+			Expect(sidecar.LocalUpstreamServers(unhealthy, locality, "us-west-1a", "us-west-1")).
+				To(Equal(unhealthy[2:]))
+		})
+
+		It("fails over when no local servers are healthy", func() {
+			unhealthy := []sidecar.UpstreamServer{
+				{Address: "1.1.1.1", Port: 80, Zone: "us-east-1a", Region: "us-east-1", Unhealthy: true},
+				servers[1],
+			}
+			locality := mesh.Locality{Mode: mesh.LocalityModeZone, FailoverThreshold: 0}
+			Expect(sidecar.LocalUpstreamServers(unhealthy, locality, "us-east-1a", "us-east-1")).To(Equal(unhealthy))
+		})
+
+		It("fails over when no local servers exist", func() {
+			locality := mesh.Locality{Mode: mesh.LocalityModeZone, FailoverThreshold: 0}
+			Expect(sidecar.LocalUpstreamServers(servers, locality, "eu-west-1a", "eu-west-1")).To(Equal(servers))
+		})
+	})
+})
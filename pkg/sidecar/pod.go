@@ -16,10 +16,16 @@ type Pod struct {
 	Namespace            string
 	ServiceAccountName   string
 	PodIP                string
+	Zone                 string
+	Region               string
 	IsIngressController  bool
 	IsEgressController   bool
 	Injected             bool
 	DefaultEgressAllowed bool
+	// GlobalRateLimit is true if the pod opted in to global rate limits with the
+	// config.nsm.nginx.com/global-rate-limit annotation. Only the sidecars of these pods
+	// synchronize rate limit state with the other pods of their services.
+	GlobalRateLimit bool
 }
 
 // ToK8s returns the K8s resource associated with the API object.
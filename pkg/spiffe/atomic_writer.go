@@ -0,0 +1,260 @@
+// Package spiffe contains code related to spiffe identity management
+package spiffe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// dataDirName is the name of the symlink to the current version of the files written by an atomicWriter.
+	dataDirName = "..data"
+	// newDataDirName is the name of the symlink that replaces the dataDirName symlink.
+	newDataDirName = "..data_tmp"
+	// versionDirPrefix is the prefix of the directories that hold a version of the files written by an atomicWriter.
+	versionDirPrefix = "..svid_"
+	// versionDirMode is the mode of a version directory. MkdirTemp creates it with 0700, which would make
+	// the files in it unreadable by other users regardless of their mode. The mode of each file restricts access instead.
+	versionDirMode = 0o755
+)
+
+// FileOwner is the owner of the files written to disk.
+type FileOwner struct {
+	UID int
+	GID int
+}
+
+// chown changes the owner of a file, if the owner is set.
+func (o *FileOwner) chown(name string) error {
+	if o == nil {
+		return nil
+	}
+	if err := os.Chown(name, o.UID, o.GID); err != nil {
+		return fmt.Errorf("error changing owner of '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// atomicFile is a file written by an atomicWriter.
+type atomicFile struct {
+	data []byte
+	mode os.FileMode
+}
+
+// atomicWriter writes a set of files to a directory so that readers see either all of the previous files,
+// or all of the new files, even if the writer crashes. Like the kubelet's AtomicWriter, each set of files is
+// written to a new version directory and the ..data symlink is swapped to point to it. The files in the
+// directory are symlinks through ..data to the current version.
+type atomicWriter struct {
+	owner *FileOwner
+	dir   string
+}
+
+// Write writes the files, keyed by filename, to the directory. A filename may be in a subdirectory,
+// i.e. bundles/example.org.pem, which is linked as a whole. Files that were written before but are not in files are removed.
+func (w *atomicWriter) Write(files map[string]atomicFile) error {
+	oldVersion, err := os.Readlink(filepath.Join(w.dir, dataDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading current version of files: %w", err)
+	}
+
+	versionDir, err := os.MkdirTemp(w.dir, versionDirPrefix)
+	if err != nil {
+		return fmt.Errorf("error creating version directory: %w", err)
+	}
+	if err := w.writeVersion(versionDir, files); err != nil {
+		_ = os.RemoveAll(versionDir)
+
+		return err
+	}
+
+	// swap the ..data symlink to the new version
+	newDataDir := filepath.Join(w.dir, newDataDirName)
+	if err := os.Remove(newDataDir); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing stale data link: %w", err)
+	}
+	if err := os.Symlink(filepath.Base(versionDir), newDataDir); err != nil {
+		return fmt.Errorf("error creating data link: %w", err)
+	}
+	if err := os.Rename(newDataDir, filepath.Join(w.dir, dataDirName)); err != nil {
+		return fmt.Errorf("error swapping data link: %w", err)
+	}
+
+	if err := w.linkFiles(files); err != nil {
+		return err
+	}
+	if err := syncDir(w.dir); err != nil {
+		return err
+	}
+
+	if oldVersion != "" && oldVersion != filepath.Base(versionDir) {
+		if err := os.RemoveAll(filepath.Join(w.dir, oldVersion)); err != nil {
+			return fmt.Errorf("error removing previous version of files: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeVersion writes and syncs the files to a version directory.
+func (w *atomicWriter) writeVersion(versionDir string, files map[string]atomicFile) error {
+	if err := os.Chmod(versionDir, versionDirMode); err != nil {
+		return fmt.Errorf("error setting mode of version directory: %w", err)
+	}
+	if err := w.owner.chown(versionDir); err != nil {
+		return err
+	}
+	for name, file := range files {
+		if dir := filepath.Dir(name); dir != "." {
+			if err := w.mkdirVersion(versionDir, dir); err != nil {
+				return err
+			}
+		}
+		if err := writeSyncedFile(filepath.Join(versionDir, name), file.data, file.mode, w.owner); err != nil {
+			return err
+		}
+	}
+
+	return syncDir(versionDir)
+}
+
+// mkdirVersion creates a subdirectory, and its parents, of a version directory.
+func (w *atomicWriter) mkdirVersion(versionDir, dir string) error {
+	name := versionDir
+	for _, elem := range strings.Split(dir, string(filepath.Separator)) {
+		name = filepath.Join(name, elem)
+		if err := os.Mkdir(name, versionDirMode); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+
+			return fmt.Errorf("error creating directory '%s': %w", name, err)
+		}
+		// the mode passed to Mkdir is subject to the umask
+		if err := os.Chmod(name, versionDirMode); err != nil {
+			return fmt.Errorf("error setting mode of '%s': %w", name, err)
+		}
+		if err := w.owner.chown(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// linkFiles links each file in the directory to its file in the current version, and removes the links of
+// files that are no longer written. A file in a subdirectory is linked through its top level directory.
+// A regular file in place of a link, i.e. from a writer that did not write atomically, is replaced with a link.
+func (w *atomicWriter) linkFiles(files map[string]atomicFile) error {
+	links := make(map[string]struct{}, len(files))
+	for name := range files {
+		links[strings.SplitN(name, string(filepath.Separator), 2)[0]] = struct{}{} //nolint:gomnd // top level and the rest
+	}
+	for name := range links {
+		filename := filepath.Join(w.dir, name)
+		target := filepath.Join(dataDirName, name)
+		if current, err := os.Readlink(filename); err == nil && current == target {
+			continue
+		}
+		tmpLink := filename + ".tmp"
+		if err := os.Remove(tmpLink); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing stale link '%s': %w", tmpLink, err)
+		}
+		if err := os.Symlink(target, tmpLink); err != nil {
+			return fmt.Errorf("error linking '%s': %w", filename, err)
+		}
+		if err := os.Rename(tmpLink, filename); err != nil {
+			return fmt.Errorf("error linking '%s': %w", filename, err)
+		}
+	}
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return fmt.Errorf("error reading directory '%s': %w", w.dir, err)
+	}
+	for _, entry := range entries {
+		if _, ok := links[entry.Name()]; ok || entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		filename := filepath.Join(w.dir, entry.Name())
+		target, err := os.Readlink(filename)
+		if err != nil || !strings.HasPrefix(target, dataDirName+string(filepath.Separator)) {
+			continue
+		}
+		if err := os.Remove(filename); err != nil {
+			return fmt.Errorf("error removing stale link '%s': %w", filename, err)
+		}
+	}
+
+	return nil
+}
+
+// writeFileAtomic replaces a single file so that readers see either the previous or the new contents,
+// even if the writer crashes. The data is written to a temporary file in the same directory, synced to disk,
+// and renamed over the file.
+func writeFileAtomic(filename string, data []byte, mode os.FileMode, owner *FileOwner) error {
+	dir := filepath.Dir(filename)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file for '%s': %w", filename, err)
+	}
+	tmpName := tmp.Name()
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing temporary file for '%s': %w", filename, err)
+	}
+	if err := writeSyncedFile(tmpName, data, mode, owner); err != nil {
+		_ = os.Remove(tmpName)
+
+		return err
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		_ = os.Remove(tmpName)
+
+		return fmt.Errorf("error renaming temporary file to '%s': %w", filename, err)
+	}
+
+	return syncDir(dir)
+}
+
+// writeSyncedFile writes a file with the mode and owner, and syncs it to disk.
+func writeSyncedFile(filename string, data []byte, mode os.FileMode, owner *FileOwner) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("error opening '%s': %w", filename, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("error writing '%s': %w", filename, err)
+	}
+	// the mode passed to OpenFile is subject to the umask
+	if err := f.Chmod(mode); err != nil {
+		return fmt.Errorf("error setting mode of '%s': %w", filename, err)
+	}
+	if err := owner.chown(filename); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("error syncing '%s': %w", filename, err)
+	}
+
+	return f.Close()
+}
+
+// syncDir syncs a directory so that the files created, renamed, or removed in it are persisted.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("error opening directory '%s': %w", dir, err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("error syncing directory '%s': %w", dir, err)
+	}
+
+	return d.Close()
+}
@@ -8,11 +8,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/nginxinc/nginx-service-mesh/pkg/taskqueue"
 
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 )
 
@@ -24,43 +26,79 @@ const (
 // no certificates, but is expected to by a parsing function.
 var ErrNoCertificates = errors.New("no certificates in svid response")
 
+// BundleChangeHook is called with the PEM encoded CA bundle each time the CA bundle changes.
+type BundleChangeHook func(caBundle []byte) error
+
 /* CABundleManager manages SPIRE events and CA Bundles. */
 type CABundleManager struct {
-	TaskQueue          *taskqueue.TaskQueue
+	TaskQueue *taskqueue.TaskQueue
+	// Owner is the owner of the persisted files. If nil, the files are owned by the current user.
+	Owner *FileOwner
+	// latestBundleHashes holds the hash of the last written bundle of each trust domain.
+	latestBundleHashes map[spiffeid.TrustDomain][]byte
 	CABundleFilepath   string
+	// BundleDir is the directory that holds the bundle of each trust domain.
+	// If not set, the bundles of the trust domains are not written.
+	BundleDir string
+	// CombinedBundleFilepath is the file that holds the bundles of all trust domains.
+	// If not set, the combined bundle is not written.
+	CombinedBundleFilepath string
+	// CertFilepath is the file that holds the PEM encoded certificate chain of the SVID.
+	// If not set, the certificate is only kept in memory.
+	CertFilepath string
+	// KeyFilepath is the file that holds the PEM encoded private key of the SVID.
+	// If not set, the key is only kept in memory.
+	KeyFilepath string
+	// Keystores are the keystores and truststores written for Java workloads.
+	Keystores          []KeystoreConfig
+	hooks              []BundleChangeHook
 	latestCABundleHash []byte
 	currentCert        []byte
 	currentKey         []byte
 	certLock           sync.RWMutex
+	hookLock           sync.Mutex
 }
 
 /*
 Write Implements svidWriter interface.
 
-	Writes CA Bundle to disk if none have
-	been written yet. Otherwise enqueues
-	a spire event in the taskqueue.
+	Writes CA Bundle to disk each time it
+	changes, and calls the bundle change hooks.
+	Persists the SVID if configured, and writes
+	the bundle of each trust domain that has
+	changed. Enqueues a spire event in the
+	taskqueue.
 */
 func (manager *CABundleManager) Write(svidResponse *workloadapi.X509Context) error {
-	// handle initial bundle download
-	if manager.latestCABundleHash == nil {
-		caBytes, _, err := manager.CABundleBytesFromSVIDResponse(svidResponse)
-		if err != nil {
-			return fmt.Errorf("couldnt marshal CA bundle: %w", err)
+	caBytes, changed, err := manager.CABundleBytesFromSVIDResponse(svidResponse)
+	if err != nil {
+		return fmt.Errorf("couldnt marshal CA bundle: %w", err)
+	}
+	// test the bundle again on the next write if it is not written, so that it is written and the hooks are called again
+	bundleWritten := !changed
+	defer func() {
+		if !bundleWritten {
+			manager.latestCABundleHash = nil
 		}
+	}()
 
-		if err = os.WriteFile(
-			manager.CABundleFilepath,
-			caBytes,
-			CABundleFileMode,
-		); err != nil {
-			return fmt.Errorf("couldnt write CA bundle: %w", err)
-		}
+	// we need copies of cert and key for NATS' sake
+	cert, key, err := manager.CertKeyBytesFromSVIDResponse(svidResponse)
+	if err != nil {
+		return err
+	}
+	if err := manager.persistSVID(svidResponse, cert, key); err != nil {
+		return err
+	}
 
-		// we need copies of cert and key for NATS' sake
-		if _, _, err = manager.CertKeyBytesFromSVIDResponse(svidResponse); err != nil {
+	if changed {
+		if err := manager.bundleChanged(svidResponse, caBytes); err != nil {
 			return err
 		}
+		bundleWritten = true
+	}
+	if err := manager.writeTrustDomainBundles(svidResponse); err != nil {
+		return err
 	}
 	// queue this anyways because certs and keys
 	// still need to go into the correct KV stores
@@ -69,6 +107,83 @@ func (manager *CABundleManager) Write(svidResponse *workloadapi.X509Context) err
 	return nil
 }
 
+// AddBundleChangeHook adds a hook that is called each time the CA bundle changes.
+// The hooks are called in the order they were added, after the CA bundle is written.
+func (manager *CABundleManager) AddBundleChangeHook(hook BundleChangeHook) {
+	manager.hookLock.Lock()
+	defer manager.hookLock.Unlock()
+	manager.hooks = append(manager.hooks, hook)
+}
+
+// persistSVID writes the certificate, key, and keystores of the SVID, if configured.
+func (manager *CABundleManager) persistSVID(svidResponse *workloadapi.X509Context, cert, key []byte) error {
+	if manager.CertFilepath != "" {
+		if err := writeFileAtomic(manager.CertFilepath, cert, certsFileMode, manager.Owner); err != nil {
+			return fmt.Errorf("couldnt write certificate: %w", err)
+		}
+	}
+	if manager.KeyFilepath != "" {
+		if err := writeFileAtomic(manager.KeyFilepath, key, keyFileMode, manager.Owner); err != nil {
+			return fmt.Errorf("couldnt write key: %w", err)
+		}
+	}
+	for _, ks := range manager.Keystores {
+		if ks.KeystoreFilepath == "" {
+			continue
+		}
+		data, err := marshalKeystore(ks.Format, svidResponse.DefaultSVID(), ks.Password)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(ks.KeystoreFilepath, data, keyFileMode, manager.Owner); err != nil {
+			return fmt.Errorf("couldnt write keystore: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// bundleChanged writes the CA bundle and truststores, and calls the bundle change hooks.
+func (manager *CABundleManager) bundleChanged(svidResponse *workloadapi.X509Context, caBytes []byte) error {
+	if err := writeFileAtomic(manager.CABundleFilepath, caBytes, CABundleFileMode, manager.Owner); err != nil {
+		return fmt.Errorf("couldnt write CA bundle: %w", err)
+	}
+
+	if len(manager.Keystores) > 0 {
+		bundle, err := ParseCABundle(svidResponse)
+		if err != nil {
+			return err
+		}
+		for _, ks := range manager.Keystores {
+			if ks.TruststoreFilepath == "" {
+				continue
+			}
+			data, err := marshalTruststore(ks.Format, bundle.X509Authorities(), ks.Password)
+			if err != nil {
+				return err
+			}
+			if err := writeFileAtomic(ks.TruststoreFilepath, data, CABundleFileMode, manager.Owner); err != nil {
+				return fmt.Errorf("couldnt write truststore: %w", err)
+			}
+		}
+	}
+
+	manager.hookLock.Lock()
+	hooks := manager.hooks
+	manager.hookLock.Unlock()
+	var errs []error
+	for _, hook := range hooks {
+		if err := hook(caBytes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("bundle change hook failed: %w", err)
+	}
+
+	return nil
+}
+
 /*
 TestAndUpdateCABundle Takes CA Bundle bytes
 
@@ -77,9 +192,7 @@ TestAndUpdateCABundle Takes CA Bundle bytes
 	Returns if the bundle has changed.
 */
 func (manager *CABundleManager) TestAndUpdateCABundle(caBundle []byte) bool {
-	caSha := sha256.New()
-	caSha.Write(caBundle)
-	caBundleHash := caSha.Sum(nil)
+	caBundleHash := bundleHash(caBundle)
 	isNew := !bytes.Equal(caBundleHash, manager.latestCABundleHash)
 	if isNew {
 		manager.latestCABundleHash = caBundleHash
@@ -88,6 +201,89 @@ func (manager *CABundleManager) TestAndUpdateCABundle(caBundle []byte) bool {
 	return isNew
 }
 
+/*
+TestAndUpdateBundles Takes the bundle bytes
+
+	of each trust domain and tests if they are
+	equal to the previous bundles. Updates the
+	internal hashes. Returns the trust domains
+	whose bundle was added, changed, or removed.
+*/
+func (manager *CABundleManager) TestAndUpdateBundles(bundles map[spiffeid.TrustDomain][]byte) []spiffeid.TrustDomain {
+	if manager.latestBundleHashes == nil {
+		manager.latestBundleHashes = make(map[spiffeid.TrustDomain][]byte)
+	}
+
+	var changed []spiffeid.TrustDomain
+	for td, bundle := range bundles {
+		hash := bundleHash(bundle)
+		if !bytes.Equal(hash, manager.latestBundleHashes[td]) {
+			manager.latestBundleHashes[td] = hash
+			changed = append(changed, td)
+		}
+	}
+	for td := range manager.latestBundleHashes {
+		if _, ok := bundles[td]; !ok {
+			delete(manager.latestBundleHashes, td)
+			changed = append(changed, td)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].String() < changed[j].String()
+	})
+
+	return changed
+}
+
+// writeTrustDomainBundles writes the bundles of the trust domains that have changed
+// and the combined bundle, and removes the bundles of trust domains that are gone.
+// If a write fails, the trust domains that changed are written again on the next call.
+func (manager *CABundleManager) writeTrustDomainBundles(svidResponse *workloadapi.X509Context) (err error) {
+	bundles, combined, err := MarshalBundles(svidResponse)
+	if err != nil {
+		return err
+	}
+	changed := manager.TestAndUpdateBundles(bundles)
+	if len(changed) == 0 {
+		return nil
+	}
+	defer func() {
+		if err != nil {
+			// an empty hash never matches, so each trust domain is tested as changed, or as removed, again
+			for _, td := range changed {
+				manager.latestBundleHashes[td] = nil
+			}
+		}
+	}()
+
+	if manager.BundleDir != "" {
+		for _, td := range changed {
+			if pemBundle, ok := bundles[td]; ok {
+				err = writeTrustDomainBundle(manager.BundleDir, td, pemBundle, manager.Owner)
+			} else {
+				err = removeTrustDomainBundle(manager.BundleDir, td)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if manager.CombinedBundleFilepath != "" {
+		if err := writeFileAtomic(manager.CombinedBundleFilepath, combined, CABundleFileMode, manager.Owner); err != nil {
+			return fmt.Errorf("couldnt write combined bundle: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func bundleHash(bundle []byte) []byte {
+	sha := sha256.New()
+	sha.Write(bundle)
+
+	return sha.Sum(nil)
+}
+
 // WaitForCABundle Waits given seconds for CABundle to be written.
 func (manager *CABundleManager) WaitForCABundle(maxSeconds int) error {
 	for i := 1; i < maxSeconds; i++ {
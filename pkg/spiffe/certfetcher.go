@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"log"
+	"sync"
 
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"google.golang.org/grpc/codes"
@@ -13,6 +14,10 @@ import (
 
 var errNoClient = errors.New("failed to start cert fetcher: nil client")
 
+// ErrNotRestartable occurs when a fetcher whose SPIFFE Workload API Client was passed in is started again.
+// The client is closed when the first fetch ends, and the fetcher cannot create a new one.
+var ErrNotRestartable = errors.New("fetcher cannot be restarted with a closed client that was passed in")
+
 //go:generate counterfeiter -generate
 
 // Client wraps the workloadapi.Client
@@ -25,18 +30,18 @@ type Client interface {
 
 // implements workloadapi.x509ContextWatcher.
 type watcher struct {
-	certFetcher X509CertFetcher
+	certCh chan<- *workloadapi.X509Context
 }
 
-func newWatcher(fetcher X509CertFetcher) *watcher {
-	return &watcher{certFetcher: fetcher}
+func newWatcher(certCh chan<- *workloadapi.X509Context) *watcher {
+	return &watcher{certCh: certCh}
 }
 
 // OnX509ContextUpdate is called when a new X.509 Context is fetched from the SPIFFE Workload API.
 // The X.509 context is placed on the cert fetcher's cert channel.
 func (w *watcher) OnX509ContextUpdate(svidResp *workloadapi.X509Context) {
 	log.Printf("SVID updated for spiffeID: %q\n", svidResp.DefaultSVID().ID)
-	w.certFetcher.CertCh <- svidResp
+	w.certCh <- svidResp
 }
 
 // OnX509WatchError is called when there is an error watching the X.509 Context's from the SPIFFE Workload API.
@@ -69,18 +74,30 @@ type CertFetcher interface {
 
 // X509CertFetcher fetches certs from the X509 SPIFFE Workload API.
 type X509CertFetcher struct {
-	client     Client
+	client Client
+	// newClient creates the SPIFFE Workload API Client when the fetcher is restarted.
+	// It is nil if the client was passed to NewX509CertFetcher.
+	newClient  func() (Client, error)
 	WatchErrCh chan error
 	CertCh     chan *workloadapi.X509Context
 	spireAddr  string
+	// mu guards client and started, which Start sets while Stop reads them.
+	mu      sync.Mutex
+	started bool
 }
 
 // NewX509CertFetcher creates a new instance of CertFetcher.
+// If client is nil, a SPIFFE Workload API Client is created for the spireAddr,
+// and a new one is created each time the fetcher is restarted.
+// Otherwise, the fetcher can only be started once, and restarting it returns ErrNotRestartable.
 func NewX509CertFetcher(spireAddr string, client Client) (*X509CertFetcher, error) {
+	var newClient func() (Client, error)
 	if client == nil {
+		newClient = func() (Client, error) {
+			return workloadapi.New(context.Background(), workloadapi.WithAddr("unix://"+spireAddr))
+		}
 		var err error
-		ctx := context.Background()
-		client, err = workloadapi.New(ctx, workloadapi.WithAddr("unix://"+spireAddr))
+		client, err = newClient()
 		if err != nil {
 			return nil, err
 		}
@@ -91,27 +108,41 @@ func NewX509CertFetcher(spireAddr string, client Client) (*X509CertFetcher, erro
 		CertCh:     make(chan *workloadapi.X509Context),
 		spireAddr:  spireAddr,
 		client:     client,
+		newClient:  newClient,
 	}, nil
 }
 
 // Start creates a SPIFFE Workload API Client. If the client cannot be created an error is returned.
 // Otherwise, a goroutine is kicked off that watches for new X.509 Contexts over the Workload API.
 // If a fatal error occurs while watching for X.509 Contexts it is written to the WatchErrCh channel.
-//
-//nolint:gocritic
+// The client is closed when the watch ends, so a restart creates a new client.
 func (c *X509CertFetcher) Start(ctx context.Context) (<-chan *workloadapi.X509Context, <-chan error, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.client == nil {
 		return nil, nil, errNoClient
 	}
+	if c.started {
+		if c.newClient == nil {
+			return nil, nil, ErrNotRestartable
+		}
+		client, err := c.newClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		c.client = client
+	}
+	c.started = true
 
-	watcher := newWatcher(*c)
+	watcher := newWatcher(c.CertCh)
+	client := c.client
 	go func() {
 		defer func() {
-			if err := c.client.Close(); err != nil && status.Code(err) != codes.Canceled {
+			if err := client.Close(); err != nil && status.Code(err) != codes.Canceled {
 				log.Println("error closing SPIFFE Workload API Client: ", err)
 			}
 		}()
-		if err := c.client.WatchX509Context(ctx, watcher); err != nil && status.Code(err) != codes.Canceled {
+		if err := client.WatchX509Context(ctx, watcher); err != nil && status.Code(err) != codes.Canceled {
 			c.WatchErrCh <- err
 		}
 	}()
@@ -121,5 +152,9 @@ func (c *X509CertFetcher) Start(ctx context.Context) (<-chan *workloadapi.X509Co
 
 // Stop closes the connection with the SPIFFE Workload API Client.
 func (c *X509CertFetcher) Stop() error {
-	return c.client.Close()
+	c.mu.Lock()
+	client := c.client
+	c.mu.Unlock()
+
+	return client.Close()
 }
@@ -2,16 +2,48 @@
 package spiffe
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
+
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
 )
 
 // ErrCertTimeout occurs when CertManager does not receive
 // the initial trust bundle before the configured timeout.
 var ErrCertTimeout = errors.New("timed out waiting for trust bundle")
 
+// ErrSVIDExpiring occurs when the current SVID is within the expiry window
+// of the CertManager and has not been renewed.
+var ErrSVIDExpiring = errors.New("SVID is about to expire and has not been renewed")
+
+// RetryConfig configures how the CertManager and JWTManager recover from errors after the initial SVID.
+type RetryConfig struct {
+	// InitialBackoff is how long to wait before restarting the fetcher after its first error.
+	// The wait doubles after each consecutive error.
+	InitialBackoff time.Duration
+	// MaxBackoff is the longest wait before restarting the fetcher.
+	MaxBackoff time.Duration
+	// WriteRetryInterval is how long to wait between attempts to write an SVID.
+	WriteRetryInterval time.Duration
+	// MaxRestarts is the number of consecutive restarts of the fetcher without receiving an SVID
+	// after which the manager gives up. Zero means the manager never gives up.
+	MaxRestarts int
+	// WriteAttempts is the number of attempts to write an SVID before the manager gives up.
+	WriteAttempts int
+}
+
+// DefaultRetryConfig is the RetryConfig of a new CertManager or JWTManager.
+var DefaultRetryConfig = RetryConfig{
+	InitialBackoff:     time.Second,
+	MaxBackoff:         time.Minute,
+	WriteRetryInterval: 100 * time.Millisecond, //nolint:gomnd // default
+	WriteAttempts:      3,                      //nolint:gomnd // default
+}
+
 //go:generate counterfeiter -generate
 
 // Reloader knows how to reload a process
@@ -24,20 +56,28 @@ type Reloader interface {
 
 // CertManager writes SVID certificates and keys to disk.
 type CertManager struct {
+	notAfter    time.Time
 	svidWriter  SVIDWriter
 	certFetcher CertFetcher
 	reloader    Reloader
-	ErrCh       chan error
-	timeout     time.Duration
+	metrics     *CertMetrics
+	// ErrCh receives the errors of the CertManager after the initial trust bundle.
+	// It holds one error, so that the CertManager does not block on an error that is not read after it stops.
+	ErrCh        chan error
+	bundleHash   []byte
+	retry        RetryConfig
+	timeout      time.Duration
+	expiryWindow time.Duration
 }
 
 // NewCertManager returns a new instance of the CertManager.
 func NewCertManager(svidWriter SVIDWriter, fetcher CertFetcher, timeout time.Duration) *CertManager {
 	return &CertManager{
-		ErrCh:       make(chan error),
+		ErrCh:       make(chan error, 1),
 		svidWriter:  svidWriter,
 		certFetcher: fetcher,
 		timeout:     timeout,
+		retry:       DefaultRetryConfig,
 	}
 }
 
@@ -54,17 +94,76 @@ func NewCertManagerWithReloader(
 	return rel
 }
 
+// SetMetrics sets the metrics that the CertManager records its rotations in.
+func (c *CertManager) SetMetrics(metrics *CertMetrics) {
+	c.metrics = metrics
+}
+
+// SetExpiryWindow enables the expiry watchdog. If the current SVID has not been renewed
+// when it is within the window of expiring, ErrSVIDExpiring is written to ErrCh.
+// A window of zero disables the watchdog.
+func (c *CertManager) SetExpiryWindow(window time.Duration) {
+	c.expiryWindow = window
+}
+
+// SetRetryConfig sets how the CertManager recovers from errors.
+// Durations and WriteAttempts that are not positive are replaced with those of DefaultRetryConfig,
+// and a MaxBackoff below InitialBackoff is raised to it.
+func (c *CertManager) SetRetryConfig(config RetryConfig) {
+	c.retry = config.withDefaults()
+}
+
 // reloads IFF reloader not nil.
-func (c *CertManager) reload() {
+func (c *CertManager) reload(ctx context.Context) {
 	if c.reloader != nil {
 		if err := c.reloader.Reload(); err != nil {
-			c.ErrCh <- err
+			c.metrics.recordReloadFailure()
+			sendErr(ctx, c.ErrCh, err)
 		}
 	}
 }
 
+// rotate writes the certificates, records the rotation, and reloads.
+// The write is attempted up to RetryConfig.WriteAttempts times.
+func (c *CertManager) rotate(ctx context.Context, certs *workloadapi.X509Context) error {
+	if err := writeWithRetry(ctx, c.retry, func() error { return c.svidWriter.Write(certs) }); err != nil {
+		c.metrics.recordRotationFailure()
+
+		return err
+	}
+
+	c.notAfter = time.Time{}
+	if len(certs.SVIDs) > 0 && len(certs.DefaultSVID().Certificates) > 0 {
+		c.notAfter = certs.DefaultSVID().Certificates[0].NotAfter
+	}
+	if certs.Bundles != nil {
+		if _, combined, err := MarshalBundles(certs); err == nil {
+			hash := bundleHash(combined)
+			if c.bundleHash != nil && !bytes.Equal(hash, c.bundleHash) {
+				c.metrics.recordBundleChange()
+			}
+			c.bundleHash = hash
+		}
+	}
+	c.metrics.recordRotation(time.Now(), c.notAfter)
+	c.reload(ctx)
+
+	return nil
+}
+
+// newExpiryTimer returns a timer that fires when the current SVID enters the expiry window,
+// or nil if the watchdog is disabled.
+func (c *CertManager) newExpiryTimer() *time.Timer {
+	if c.expiryWindow <= 0 || c.notAfter.IsZero() {
+		return nil
+	}
+
+	return time.NewTimer(time.Until(c.notAfter.Add(-c.expiryWindow)))
+}
+
 // Run is the run loop for the certmanager.
 // Starts the certFetcher and waits for certs or an unrecoverable error.
+// If no certs are written, the certFetcher is stopped before Run returns.
 func (c *CertManager) Run(ctx context.Context) error {
 	certStream, errStream, err := c.certFetcher.Start(ctx)
 	if err != nil {
@@ -74,42 +173,56 @@ func (c *CertManager) Run(ctx context.Context) error {
 	// Wait for initial trust bundle
 	select {
 	case certs := <-certStream:
-		if err = c.svidWriter.Write(certs); err != nil {
-			return fmt.Errorf("error writing certificates: %w", err)
+		err = c.rotate(ctx, certs)
+		if err != nil {
+			err = fmt.Errorf("error writing certificates: %w", err)
 		}
-		c.reload()
 	case err = <-errStream:
-		return fmt.Errorf("error waiting for initial trust bundle: %w", err)
+		err = fmt.Errorf("error waiting for initial trust bundle: %w", err)
 	case <-time.After(c.timeout):
-		return ErrCertTimeout
+		err = ErrCertTimeout
 	case <-ctx.Done():
-		return ctx.Err()
+		err = ctx.Err()
 	}
-
-	// Now start goroutine to wait for updates
-	go func() {
-		for {
-			select {
-			case err = <-errStream:
-				c.ErrCh <- err
-
-				return
-			case certs := <-certStream:
-				if err := c.svidWriter.Write(certs); err != nil {
-					c.ErrCh <- err
-
-					return
-				}
-				c.reload()
-			case <-ctx.Done():
-				c.ErrCh <- ctx.Err()
-			}
+	if err != nil {
+		// do not leave the certFetcher running if the CertManager does not supervise it
+		if stopErr := c.certFetcher.Stop(); stopErr != nil {
+			log.Printf("error stopping cert fetcher: %v", stopErr)
 		}
-	}()
+
+		return err
+	}
+
+	// Now supervise the updates
+	go c.supervise(ctx, certStream, errStream)
 
 	return nil
 }
 
+// supervise writes the certificates from the certFetcher until the context is canceled.
+// See fetcherSupervisor.supervise for how errors of the certFetcher are handled.
+func (c *CertManager) supervise(ctx context.Context, certStream <-chan *workloadapi.X509Context, errStream <-chan error) {
+	supervisor := &fetcherSupervisor[*workloadapi.X509Context]{
+		start: c.certFetcher.Start,
+		rotate: func(ctx context.Context, certs *workloadapi.X509Context) error {
+			if err := c.rotate(ctx, certs); err != nil {
+				return fmt.Errorf("error writing certificates: %w", err)
+			}
+
+			return nil
+		},
+		failed:      c.metrics.recordRotationFailure,
+		expiryTimer: c.newExpiryTimer,
+		expired: func() error {
+			return fmt.Errorf("%w: SVID expires at %s", ErrSVIDExpiring, c.notAfter.UTC().Format(time.RFC3339))
+		},
+		errCh: c.ErrCh,
+		name:  "cert fetcher",
+		retry: c.retry,
+	}
+	supervisor.supervise(ctx, certStream, errStream)
+}
+
 // Stop stops the internal certFetcher.
 func (c *CertManager) Stop() error {
 	return c.certFetcher.Stop()
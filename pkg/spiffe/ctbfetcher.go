@@ -0,0 +1,131 @@
+// Package spiffe contains code related to spiffe identity management
+package spiffe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ClusterTrustBundleResource is the ClusterTrustBundle resource of the Kubernetes certificates API.
+var ClusterTrustBundleResource = schema.GroupVersionResource{
+	Group:    "certificates.k8s.io",
+	Version:  "v1alpha1",
+	Resource: "clustertrustbundles",
+}
+
+var errNoClusterTrustBundles = errors.New("no ClusterTrustBundles found")
+
+// ClusterTrustBundleCertFetcher fetches certs using the Kubernetes pod certificate APIs. The certificate and key
+// are read from files, i.e. a pod certificate projected volume, and the trust bundle is the union of the
+// ClusterTrustBundles of a signer. The certificates are reloaded when the files or the ClusterTrustBundles change.
+type ClusterTrustBundleCertFetcher struct {
+	dynamicClient dynamic.Interface
+	files         *FileCertFetcher
+	// cancel stops the informer of the last Start. It is guarded by mu, since Start and Stop can run concurrently.
+	cancel     context.CancelFunc
+	signerName string
+	mu         sync.Mutex
+}
+
+// NewClusterTrustBundleCertFetcher creates a new instance of CertFetcher that uses the ClusterTrustBundles of the signerName.
+func NewClusterTrustBundleCertFetcher(
+	dynamicClient dynamic.Interface,
+	signerName, certFile, keyFile string,
+) *ClusterTrustBundleCertFetcher {
+	return &ClusterTrustBundleCertFetcher{
+		dynamicClient: dynamicClient,
+		files:         NewFileCertFetcher(certFile, keyFile, ""),
+		signerName:    signerName,
+	}
+}
+
+// Start kicks off an informer that watches the ClusterTrustBundles, and starts watching the certificate and key files.
+// Each time either changes, the certificates are written to the X509Context channel.
+func (c *ClusterTrustBundleCertFetcher) Start(ctx context.Context) (<-chan *workloadapi.X509Context, <-chan error, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.cancel = cancel
+	c.mu.Unlock()
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(c.dynamicClient, 0)
+	informer := factory.ForResource(ClusterTrustBundleResource).Informer()
+	reload := func(interface{}) { c.files.triggerReload() }
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    reload,
+		UpdateFunc: func(_, obj interface{}) { reload(obj) },
+		DeleteFunc: reload,
+	}); err != nil {
+		cancel()
+
+		return nil, nil, err
+	}
+	c.files.readBundle = func() ([]byte, error) {
+		return c.trustBundle(informer.GetStore().List())
+	}
+	factory.Start(ctx.Done())
+
+	certCh, errCh, err := c.files.Start(ctx)
+	if err != nil {
+		cancel()
+
+		return nil, nil, err
+	}
+
+	return certCh, errCh, nil
+}
+
+// Stop stops watching the ClusterTrustBundles and the files.
+func (c *ClusterTrustBundleCertFetcher) Stop() error {
+	c.mu.Lock()
+	cancel := c.cancel
+	c.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	return c.files.Stop()
+}
+
+// trustBundle returns the trust bundles of the ClusterTrustBundles of the signer, ordered by name.
+func (c *ClusterTrustBundleCertFetcher) trustBundle(objs []interface{}) ([]byte, error) {
+	bundles := map[string]string{}
+	for _, obj := range objs {
+		ctb, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		signerName, _, _ := unstructured.NestedString(ctb.Object, "spec", "signerName")
+		if signerName != c.signerName {
+			continue
+		}
+		trustBundle, _, _ := unstructured.NestedString(ctb.Object, "spec", "trustBundle")
+		bundles[ctb.GetName()] = trustBundle
+	}
+	if len(bundles) == 0 {
+		return nil, fmt.Errorf("%w for signer '%s'", errNoClusterTrustBundles, c.signerName)
+	}
+
+	names := make([]string, 0, len(bundles))
+	for name := range bundles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(strings.TrimSpace(bundles[name]))
+		sb.WriteString("\n")
+	}
+
+	return []byte(sb.String()), nil
+}
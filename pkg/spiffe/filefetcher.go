@@ -0,0 +1,169 @@
+// Package spiffe contains code related to spiffe identity management
+package spiffe
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+// FileCertFetcher fetches certs from static files, i.e. mounted from a Secret or written by another agent.
+// The files are reloaded each time they change.
+type FileCertFetcher struct {
+	// cancel stops the watch of the last Start. It is guarded by mu, since Start and Stop can run concurrently.
+	cancel context.CancelFunc
+	// readBundle reads the trust bundle. Defaults to reading the bundleFile.
+	readBundle func() ([]byte, error)
+	// reloadCh triggers a reload of the files, i.e. when the trust bundle changes.
+	reloadCh   chan struct{}
+	WatchErrCh chan error
+	CertCh     chan *workloadapi.X509Context
+	certFile   string
+	keyFile    string
+	bundleFile string
+	latestHash []byte
+	mu         sync.Mutex
+}
+
+// NewFileCertFetcher creates a new instance of CertFetcher that reads the certificate, key, and trust bundle from files.
+func NewFileCertFetcher(certFile, keyFile, bundleFile string) *FileCertFetcher {
+	f := &FileCertFetcher{
+		reloadCh:   make(chan struct{}, 1),
+		WatchErrCh: make(chan error),
+		CertCh:     make(chan *workloadapi.X509Context),
+		certFile:   certFile,
+		keyFile:    keyFile,
+		bundleFile: bundleFile,
+	}
+	f.readBundle = func() ([]byte, error) {
+		return os.ReadFile(f.bundleFile)
+	}
+
+	return f
+}
+
+// Start watches the directories of the files, so that files replaced by a rename or a symlink swap
+// are reloaded as well, and kicks off a goroutine that reads the files. Each time the files change,
+// their certificates are written to the CertCh channel. Files that are missing or not a complete SVID,
+// i.e. while they are being written, are logged and skipped. If the watch fails, the error is
+// written to the WatchErrCh channel.
+func (f *FileCertFetcher) Start(ctx context.Context) (<-chan *workloadapi.X509Context, <-chan error, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating file watcher: %w", err)
+	}
+	dirs := map[string]struct{}{}
+	for _, name := range []string{f.certFile, f.keyFile, f.bundleFile} {
+		if name != "" {
+			dirs[filepath.Dir(name)] = struct{}{}
+		}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+
+			return nil, nil, fmt.Errorf("error watching directory '%s': %w", dir, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	f.mu.Lock()
+	f.cancel = cancel
+	f.mu.Unlock()
+	f.latestHash = nil
+	go func() {
+		defer watcher.Close()
+		f.reload(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-watcher.Events:
+				f.reload(ctx)
+			case <-f.reloadCh:
+				f.reload(ctx)
+			case err := <-watcher.Errors:
+				select {
+				case f.WatchErrCh <- fmt.Errorf("error watching SVID files: %w", err):
+				case <-ctx.Done():
+				}
+
+				return
+			}
+		}
+	}()
+
+	return f.CertCh, f.WatchErrCh, nil
+}
+
+// Stop stops watching the files.
+func (f *FileCertFetcher) Stop() error {
+	f.mu.Lock()
+	cancel := f.cancel
+	f.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	return nil
+}
+
+// triggerReload reloads the files, unless a reload is already pending.
+func (f *FileCertFetcher) triggerReload() {
+	select {
+	case f.reloadCh <- struct{}{}:
+	default:
+	}
+}
+
+// reload reads the files and writes their certificates to the CertCh channel if they changed.
+func (f *FileCertFetcher) reload(ctx context.Context) {
+	certPEM, err := os.ReadFile(f.certFile)
+	if err != nil {
+		log.Printf("skipping SVID files: %v", err)
+
+		return
+	}
+	keyPEM, err := os.ReadFile(f.keyFile)
+	if err != nil {
+		log.Printf("skipping SVID files: %v", err)
+
+		return
+	}
+	bundlePEM, err := f.readBundle()
+	if err != nil {
+		log.Printf("skipping SVID files: %v", err)
+
+		return
+	}
+
+	hash := sha256.New()
+	for _, data := range [][]byte{certPEM, keyPEM, bundlePEM} {
+		hash.Write(data)
+	}
+	sum := hash.Sum(nil)
+	if bytes.Equal(sum, f.latestHash) {
+		return
+	}
+
+	certs, err := newX509Context(certPEM, keyPEM, bundlePEM)
+	if err != nil {
+		log.Printf("skipping SVID files: %v", err)
+
+		return
+	}
+	f.latestHash = sum
+	log.Printf("SVID updated for spiffeID: %q\n", certs.DefaultSVID().ID)
+	select {
+	case f.CertCh <- certs:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,184 @@
+// Package spiffe contains code related to spiffe identity management
+package spiffe
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultJWTRetryInterval is how long the JWTSVIDFetcher waits before fetching again after an error.
+	DefaultJWTRetryInterval = 5 * time.Second
+	// minJWTRefreshInterval is the shortest time the JWTSVIDFetcher waits before refreshing a JWT-SVID.
+	minJWTRefreshInterval = time.Second
+)
+
+var errNoAudience = errors.New("failed to create JWT-SVID fetcher: no audience")
+
+//go:generate counterfeiter -generate
+
+// JWTClient wraps the JWT-SVID methods of the workloadapi.Client
+//
+//counterfeiter:generate ./. JWTClient
+type JWTClient interface {
+	FetchJWTSVID(context.Context, jwtsvid.Params) (*jwtsvid.SVID, error)
+	Close() error
+}
+
+// JWTFetcher fetches JWT-SVIDs
+//
+//counterfeiter:generate ./. JWTFetcher
+type JWTFetcher interface {
+	// Start starts fetching JWT-SVIDs.
+	// It returns an error if it fails to start.
+	// Otherwise, JWT-SVIDs are written to the JWT-SVID channel
+	// and if there is an unrecoverable error it is written to the error channel.
+	Start(context.Context) (<-chan *jwtsvid.SVID, <-chan error, error)
+	// Stop closes the connection with the SPIFFE Workload API Client.
+	Stop() error
+}
+
+// JWTSVIDFetcher fetches JWT-SVIDs for a set of audiences from the SPIFFE Workload API.
+// A new JWT-SVID is fetched when half of the lifetime of the current one has passed.
+type JWTSVIDFetcher struct {
+	client JWTClient
+	// newClient creates the SPIFFE Workload API Client when the fetcher is restarted.
+	// It is nil if the client was passed to NewJWTSVIDFetcher.
+	newClient  func() (JWTClient, error)
+	FetchErrCh chan error
+	SVIDCh     chan *jwtsvid.SVID
+	spireAddr  string
+	params     jwtsvid.Params
+	// RetryInterval is how long to wait before fetching again after an error.
+	RetryInterval time.Duration
+	// mu guards client and started, which Start sets while Stop reads them.
+	mu      sync.Mutex
+	started bool
+}
+
+// NewJWTSVIDFetcher creates a new instance of JWTFetcher.
+// The JWT-SVIDs are issued for the audience and any extra audiences.
+// If client is nil, a SPIFFE Workload API Client is created for the spireAddr,
+// and a new one is created each time the fetcher is restarted.
+// Otherwise, the fetcher can only be started once, and restarting it returns ErrNotRestartable.
+func NewJWTSVIDFetcher(spireAddr string, client JWTClient, audience string, extraAudiences ...string) (*JWTSVIDFetcher, error) {
+	if audience == "" {
+		return nil, errNoAudience
+	}
+	var newClient func() (JWTClient, error)
+	if client == nil {
+		newClient = func() (JWTClient, error) {
+			return workloadapi.New(context.Background(), workloadapi.WithAddr("unix://"+spireAddr))
+		}
+		var err error
+		client, err = newClient()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &JWTSVIDFetcher{
+		FetchErrCh: make(chan error),
+		SVIDCh:     make(chan *jwtsvid.SVID),
+		spireAddr:  spireAddr,
+		client:     client,
+		newClient:  newClient,
+		params: jwtsvid.Params{
+			Audience:       audience,
+			ExtraAudiences: extraAudiences,
+		},
+		RetryInterval: DefaultJWTRetryInterval,
+	}, nil
+}
+
+// Start kicks off a goroutine that fetches JWT-SVIDs over the Workload API until the context is canceled.
+// Errors that the SPIRE agent may recover from are logged and the fetch is retried.
+// If a fatal error occurs while fetching JWT-SVIDs it is written to the FetchErrCh channel.
+// The client is closed when the fetch ends, so a restart creates a new client.
+func (c *JWTSVIDFetcher) Start(ctx context.Context) (<-chan *jwtsvid.SVID, <-chan error, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == nil {
+		return nil, nil, errNoClient
+	}
+	if c.started {
+		if c.newClient == nil {
+			return nil, nil, ErrNotRestartable
+		}
+		client, err := c.newClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		c.client = client
+	}
+	c.started = true
+
+	client := c.client
+	go func() {
+		defer func() {
+			if err := client.Close(); err != nil && status.Code(err) != codes.Canceled {
+				log.Println("error closing SPIFFE Workload API Client: ", err)
+			}
+		}()
+		for {
+			wait := c.RetryInterval
+			svid, err := client.FetchJWTSVID(ctx, c.params)
+			switch {
+			case err == nil:
+				log.Printf("JWT-SVID updated for spiffeID: %q\n", svid.ID)
+				select {
+				case c.SVIDCh <- svid:
+				case <-ctx.Done():
+					return
+				}
+				wait = jwtRefreshInterval(svid.Expiry, time.Now())
+			case ctx.Err() != nil || status.Code(err) == codes.Canceled:
+				return
+			case status.Code(err) == codes.InvalidArgument:
+				select {
+				case c.FetchErrCh <- err:
+				case <-ctx.Done():
+				}
+
+				return
+			default:
+				log.Printf("JWTSVIDClient error: %v. For more information check the logs of the Spire agents and server.", err)
+			}
+
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return c.SVIDCh, c.FetchErrCh, nil
+}
+
+// Stop closes the connection with the SPIFFE Workload API Client.
+func (c *JWTSVIDFetcher) Stop() error {
+	c.mu.Lock()
+	client := c.client
+	c.mu.Unlock()
+
+	return client.Close()
+}
+
+// jwtRefreshInterval returns how long to wait before refreshing a JWT-SVID that expires at expiry.
+func jwtRefreshInterval(expiry, now time.Time) time.Duration {
+	interval := expiry.Sub(now) / 2 //nolint:gomnd // refresh at half of the remaining lifetime
+	if interval < minJWTRefreshInterval {
+		return minJWTRefreshInterval
+	}
+
+	return interval
+}
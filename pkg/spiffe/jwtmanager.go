@@ -0,0 +1,127 @@
+// Package spiffe contains code related to spiffe identity management
+package spiffe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
+)
+
+// ErrJWTTimeout occurs when JWTManager does not receive
+// the initial JWT-SVID before the configured timeout.
+var ErrJWTTimeout = errors.New("timed out waiting for JWT-SVID")
+
+// JWTManager writes JWT-SVIDs to disk.
+type JWTManager struct {
+	svidWriter JWTSVIDWriter
+	fetcher    JWTFetcher
+	reloader   Reloader
+	// ErrCh receives the errors of the JWTManager after the initial JWT-SVID.
+	// It holds one error, so that the JWTManager does not block on an error that is not read after it stops.
+	ErrCh   chan error
+	retry   RetryConfig
+	timeout time.Duration
+}
+
+// NewJWTManager returns a new instance of the JWTManager.
+func NewJWTManager(svidWriter JWTSVIDWriter, fetcher JWTFetcher, timeout time.Duration) *JWTManager {
+	return &JWTManager{
+		ErrCh:      make(chan error, 1),
+		svidWriter: svidWriter,
+		fetcher:    fetcher,
+		timeout:    timeout,
+		retry:      DefaultRetryConfig,
+	}
+}
+
+// NewJWTManagerWithReloader returns a new instance of the JWTManager.
+func NewJWTManagerWithReloader(
+	reloader Reloader,
+	svidWriter JWTSVIDWriter,
+	fetcher JWTFetcher,
+	timeout time.Duration,
+) *JWTManager {
+	rel := NewJWTManager(svidWriter, fetcher, timeout)
+	rel.reloader = reloader
+
+	return rel
+}
+
+// SetRetryConfig sets how the JWTManager recovers from errors.
+// Durations and WriteAttempts that are not positive are replaced with those of DefaultRetryConfig,
+// and a MaxBackoff below InitialBackoff is raised to it.
+func (j *JWTManager) SetRetryConfig(config RetryConfig) {
+	j.retry = config.withDefaults()
+}
+
+// reloads IFF reloader not nil.
+func (j *JWTManager) reload(ctx context.Context) {
+	if j.reloader != nil {
+		if err := j.reloader.Reload(); err != nil {
+			sendErr(ctx, j.ErrCh, err)
+		}
+	}
+}
+
+// rotate writes the JWT-SVID and reloads.
+// The write is attempted up to RetryConfig.WriteAttempts times.
+func (j *JWTManager) rotate(ctx context.Context, svid *jwtsvid.SVID) error {
+	if err := writeWithRetry(ctx, j.retry, func() error { return j.svidWriter.Write(svid) }); err != nil {
+		return fmt.Errorf("error writing JWT-SVID: %w", err)
+	}
+	j.reload(ctx)
+
+	return nil
+}
+
+// Run is the run loop for the JWTManager.
+// Starts the fetcher and waits for the initial JWT-SVID or an unrecoverable error.
+// If no JWT-SVID is written, the fetcher is stopped before Run returns.
+// Afterwards the JWT-SVIDs are supervised like the certificates of the CertManager.
+func (j *JWTManager) Run(ctx context.Context) error {
+	svidStream, errStream, err := j.fetcher.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting JWT-SVID fetcher: %w", err)
+	}
+
+	// Wait for initial JWT-SVID
+	select {
+	case svid := <-svidStream:
+		err = j.rotate(ctx, svid)
+	case err = <-errStream:
+		err = fmt.Errorf("error waiting for initial JWT-SVID: %w", err)
+	case <-time.After(j.timeout):
+		err = ErrJWTTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		// do not leave the fetcher running if the JWTManager does not supervise it
+		if stopErr := j.fetcher.Stop(); stopErr != nil {
+			log.Printf("error stopping JWT-SVID fetcher: %v", stopErr)
+		}
+
+		return err
+	}
+
+	// Now supervise the updates
+	supervisor := &fetcherSupervisor[*jwtsvid.SVID]{
+		start:  j.fetcher.Start,
+		rotate: j.rotate,
+		errCh:  j.ErrCh,
+		name:   "JWT-SVID fetcher",
+		retry:  j.retry,
+	}
+	go supervisor.supervise(ctx, svidStream, errStream)
+
+	return nil
+}
+
+// Stop stops the internal fetcher.
+func (j *JWTManager) Stop() error {
+	return j.fetcher.Stop()
+}
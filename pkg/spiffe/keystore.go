@@ -0,0 +1,119 @@
+// Package spiffe contains code related to spiffe identity management
+package spiffe
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// KeystoreFormat is the format of a keystore for Java workloads.
+type KeystoreFormat string
+
+// Keystore formats.
+const (
+	KeystoreFormatPKCS12 KeystoreFormat = "pkcs12"
+	KeystoreFormatJKS    KeystoreFormat = "jks"
+)
+
+const (
+	// keystoreAlias is the alias of the SVID in a JKS keystore.
+	keystoreAlias = "svid"
+	// x509CertificateType is the type of the certificates in a JKS keystore.
+	x509CertificateType = "X509"
+)
+
+var errUnknownKeystoreFormat = errors.New("unknown keystore format")
+
+// KeystoreConfig configures a keystore and truststore written by a CABundleManager.
+type KeystoreConfig struct {
+	// Format is the format of the keystore and truststore.
+	Format KeystoreFormat
+	// KeystoreFilepath is the file that holds the private key and certificate chain of the SVID.
+	// If not set, the keystore is not written.
+	KeystoreFilepath string
+	// TruststoreFilepath is the file that holds the CA bundle.
+	// If not set, the truststore is not written.
+	TruststoreFilepath string
+	// Password protects the keystore and truststore.
+	Password string
+}
+
+// marshalKeystore encodes the private key and certificate chain of the SVID as a keystore.
+func marshalKeystore(format KeystoreFormat, svid *x509svid.SVID, password string) ([]byte, error) {
+	if len(svid.Certificates) < 1 {
+		return nil, ErrNoCertificates
+	}
+
+	switch format {
+	case KeystoreFormatPKCS12:
+		data, err := pkcs12.Modern.Encode(svid.PrivateKey, svid.Certificates[0], svid.Certificates[1:], password)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding PKCS#12 keystore: %w", err)
+		}
+
+		return data, nil
+	case KeystoreFormatJKS:
+		key, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling private key: %w", err)
+		}
+		chain := make([]keystore.Certificate, 0, len(svid.Certificates))
+		for _, cert := range svid.Certificates {
+			chain = append(chain, keystore.Certificate{Type: x509CertificateType, Content: cert.Raw})
+		}
+		ks := keystore.New()
+		if err := ks.SetPrivateKeyEntry(keystoreAlias, keystore.PrivateKeyEntry{
+			CreationTime:     svid.Certificates[0].NotBefore,
+			PrivateKey:       key,
+			CertificateChain: chain,
+		}, []byte(password)); err != nil {
+			return nil, fmt.Errorf("error adding SVID to JKS keystore: %w", err)
+		}
+
+		return storeJKS(ks, password)
+	default:
+		return nil, fmt.Errorf("%w: '%s'", errUnknownKeystoreFormat, format)
+	}
+}
+
+// marshalTruststore encodes the authorities of a CA bundle as a truststore.
+func marshalTruststore(format KeystoreFormat, authorities []*x509.Certificate, password string) ([]byte, error) {
+	switch format {
+	case KeystoreFormatPKCS12:
+		data, err := pkcs12.Modern.EncodeTrustStore(authorities, password)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding PKCS#12 truststore: %w", err)
+		}
+
+		return data, nil
+	case KeystoreFormatJKS:
+		ks := keystore.New()
+		for i, cert := range authorities {
+			if err := ks.SetTrustedCertificateEntry(fmt.Sprintf("ca-%d", i), keystore.TrustedCertificateEntry{
+				CreationTime: cert.NotBefore,
+				Certificate:  keystore.Certificate{Type: x509CertificateType, Content: cert.Raw},
+			}); err != nil {
+				return nil, fmt.Errorf("error adding CA to JKS truststore: %w", err)
+			}
+		}
+
+		return storeJKS(ks, password)
+	default:
+		return nil, fmt.Errorf("%w: '%s'", errUnknownKeystoreFormat, format)
+	}
+}
+
+func storeJKS(ks keystore.KeyStore, password string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ks.Store(&buf, []byte(password)); err != nil {
+		return nil, fmt.Errorf("error encoding JKS keystore: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
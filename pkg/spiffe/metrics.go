@@ -0,0 +1,107 @@
+// Package spiffe contains code related to spiffe identity management
+package spiffe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "nsm"
+	metricsSubsystem = "svid"
+)
+
+// CertMetrics holds the Prometheus metrics of the certificate rotations of a CertManager.
+type CertMetrics struct {
+	// LastRotation is the time of the last successful rotation, in seconds since the epoch.
+	LastRotation prometheus.Gauge
+	// NotAfter is the time the current SVID expires, in seconds since the epoch.
+	NotAfter prometheus.Gauge
+	// RotationFailures is the number of SVIDs that could not be fetched or written.
+	RotationFailures prometheus.Counter
+	// ReloadFailures is the number of failed reloads after a rotation.
+	ReloadFailures prometheus.Counter
+	// BundleChanges is the number of rotations that changed the trust bundles.
+	BundleChanges prometheus.Counter
+}
+
+// NewCertMetrics returns the metrics of a CertManager. The constLabels are added to each metric,
+// i.e. to tell the metrics of different components apart.
+func NewCertMetrics(constLabels prometheus.Labels) *CertMetrics {
+	opts := func(name, help string) prometheus.Opts {
+		return prometheus.Opts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        name,
+			Help:        help,
+			ConstLabels: constLabels,
+		}
+	}
+
+	return &CertMetrics{
+		LastRotation: prometheus.NewGauge(prometheus.GaugeOpts(
+			opts("last_rotation_timestamp_seconds", "Time of the last successful SVID rotation in seconds since the epoch."),
+		)),
+		NotAfter: prometheus.NewGauge(prometheus.GaugeOpts(
+			opts("not_after_timestamp_seconds", "Time the current SVID expires in seconds since the epoch."),
+		)),
+		RotationFailures: prometheus.NewCounter(prometheus.CounterOpts(
+			opts("rotation_failures_total", "Number of SVIDs that could not be fetched or written."),
+		)),
+		ReloadFailures: prometheus.NewCounter(prometheus.CounterOpts(
+			opts("reload_failures_total", "Number of failed reloads after an SVID rotation."),
+		)),
+		BundleChanges: prometheus.NewCounter(prometheus.CounterOpts(
+			opts("bundle_changes_total", "Number of SVID rotations that changed the trust bundles."),
+		)),
+	}
+}
+
+// Register registers the metrics with a Prometheus registerer.
+func (m *CertMetrics) Register(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{
+		m.LastRotation,
+		m.NotAfter,
+		m.RotationFailures,
+		m.ReloadFailures,
+		m.BundleChanges,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return fmt.Errorf("error registering SVID metrics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// The record functions are no-ops if the metrics are not set.
+
+func (m *CertMetrics) recordRotation(rotated, notAfter time.Time) {
+	if m == nil {
+		return
+	}
+	m.LastRotation.Set(float64(rotated.Unix()))
+	if !notAfter.IsZero() {
+		m.NotAfter.Set(float64(notAfter.Unix()))
+	}
+}
+
+func (m *CertMetrics) recordRotationFailure() {
+	if m != nil {
+		m.RotationFailures.Inc()
+	}
+}
+
+func (m *CertMetrics) recordReloadFailure() {
+	if m != nil {
+		m.ReloadFailures.Inc()
+	}
+}
+
+func (m *CertMetrics) recordBundleChange() {
+	if m != nil {
+		m.BundleChanges.Inc()
+	}
+}
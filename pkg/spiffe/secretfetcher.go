@@ -0,0 +1,102 @@
+// Package spiffe contains code related to spiffe identity management
+package spiffe
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+// caBundleKey is the key of the trust bundle in a kubernetes.io/tls Secret issued by cert-manager.
+const caBundleKey = "ca.crt"
+
+// SecretCertFetcher fetches certs from a kubernetes.io/tls Secret, i.e. one managed by cert-manager.
+// The Secret holds the certificate in tls.crt, the key in tls.key, and the trust bundle in ca.crt.
+type SecretCertFetcher struct {
+	clientset kubernetes.Interface
+	// cancel stops the informer of the last Start. It is guarded by mu, since Start and Stop can run concurrently.
+	cancel     context.CancelFunc
+	WatchErrCh chan error
+	CertCh     chan *workloadapi.X509Context
+	namespace  string
+	name       string
+	mu         sync.Mutex
+}
+
+// NewSecretCertFetcher creates a new instance of CertFetcher that watches a Secret.
+func NewSecretCertFetcher(clientset kubernetes.Interface, namespace, name string) *SecretCertFetcher {
+	return &SecretCertFetcher{
+		clientset:  clientset,
+		WatchErrCh: make(chan error),
+		CertCh:     make(chan *workloadapi.X509Context),
+		namespace:  namespace,
+		name:       name,
+	}
+}
+
+// Start kicks off an informer that watches the Secret. Each time the Secret is added or updated,
+// its certificates are written to the CertCh channel. A Secret that is not a complete SVID,
+// i.e. while it is being issued, is logged and skipped.
+func (s *SecretCertFetcher) Start(ctx context.Context) (<-chan *workloadapi.X509Context, <-chan error, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+	factory := informers.NewSharedInformerFactoryWithOptions(s.clientset, 0,
+		informers.WithNamespace(s.namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", s.name).String()
+		}),
+	)
+	informer := factory.Core().V1().Secrets().Informer()
+
+	send := func(obj interface{}) {
+		secret, ok := obj.(*v1.Secret)
+		if !ok {
+			return
+		}
+		certs, err := newX509Context(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey], secret.Data[caBundleKey])
+		if err != nil {
+			log.Printf("skipping Secret %s/%s: %v", s.namespace, s.name, err)
+
+			return
+		}
+		log.Printf("SVID updated for spiffeID: %q\n", certs.DefaultSVID().ID)
+		select {
+		case s.CertCh <- certs:
+		case <-ctx.Done():
+		}
+	}
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    send,
+		UpdateFunc: func(_, obj interface{}) { send(obj) },
+	}); err != nil {
+		cancel()
+
+		return nil, nil, err
+	}
+	factory.Start(ctx.Done())
+
+	return s.CertCh, s.WatchErrCh, nil
+}
+
+// Stop stops watching the Secret.
+func (s *SecretCertFetcher) Stop() error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	return nil
+}
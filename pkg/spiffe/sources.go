@@ -0,0 +1,105 @@
+// Package spiffe contains code related to spiffe identity management
+package spiffe
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SVIDSource is where a CertFetcher gets its SVIDs from.
+type SVIDSource string
+
+// SVID sources.
+const (
+	// SVIDSourceSPIRE fetches SVIDs from the SPIRE agent over the SPIFFE Workload API.
+	SVIDSourceSPIRE SVIDSource = "spire"
+	// SVIDSourceSecret reads SVIDs from a kubernetes.io/tls Secret, i.e. one managed by cert-manager.
+	SVIDSourceSecret SVIDSource = "secret"
+	// SVIDSourceFiles reads SVIDs from files, and reloads them when they change.
+	SVIDSourceFiles SVIDSource = "files"
+	// SVIDSourceClusterTrustBundle reads the certificate and key from files, i.e. a pod certificate
+	// projected volume, and the trust bundle from the ClusterTrustBundles of a signer.
+	SVIDSourceClusterTrustBundle SVIDSource = "cluster-trust-bundle"
+)
+
+var (
+	errUnknownSVIDSource = errors.New("unknown SVID source")
+	errMissingSVIDConfig = errors.New("missing SVID source configuration")
+)
+
+// SVIDSourceConfig configures the source of the SVIDs of a CertFetcher.
+// The certificates of every source other than SPIRE must have a SPIFFE ID URI SAN.
+type SVIDSourceConfig struct {
+	// Type is the source of the SVIDs. Defaults to spire.
+	Type SVIDSource
+	// SpireAddr is the path of the SPIRE agent socket. Used by the spire source.
+	SpireAddr string
+	// SecretNamespace is the namespace of the Secret. Used by the secret source.
+	SecretNamespace string
+	// SecretName is the name of the Secret, with the tls.crt, tls.key, and ca.crt keys. Used by the secret source.
+	SecretName string
+	// CertFile is the path of the certificate. Used by the files and cluster-trust-bundle sources.
+	CertFile string
+	// KeyFile is the path of the private key. Used by the files and cluster-trust-bundle sources.
+	KeyFile string
+	// CABundleFile is the path of the trust bundle. Used by the files source.
+	CABundleFile string
+	// SignerName is the signer of the ClusterTrustBundles. Used by the cluster-trust-bundle source.
+	SignerName string
+}
+
+// NewCertFetcher creates the CertFetcher of the configured SVID source.
+// The clientset is required by the secret source, and the dynamicClient by the cluster-trust-bundle source.
+func NewCertFetcher(config SVIDSourceConfig, clientset kubernetes.Interface, dynamicClient dynamic.Interface) (CertFetcher, error) {
+	switch config.Type {
+	case SVIDSourceSPIRE, "":
+		return NewX509CertFetcher(config.SpireAddr, nil)
+	case SVIDSourceSecret:
+		if config.SecretNamespace == "" || config.SecretName == "" || clientset == nil {
+			return nil, fmt.Errorf("%w: the secret source requires a Secret namespace, name, and Kubernetes client", errMissingSVIDConfig)
+		}
+
+		return NewSecretCertFetcher(clientset, config.SecretNamespace, config.SecretName), nil
+	case SVIDSourceFiles:
+		if config.CertFile == "" || config.KeyFile == "" || config.CABundleFile == "" {
+			return nil, fmt.Errorf("%w: the files source requires a certificate, key, and CA bundle file", errMissingSVIDConfig)
+		}
+
+		return NewFileCertFetcher(config.CertFile, config.KeyFile, config.CABundleFile), nil
+	case SVIDSourceClusterTrustBundle:
+		if config.CertFile == "" || config.KeyFile == "" || config.SignerName == "" || dynamicClient == nil {
+			return nil, fmt.Errorf(
+				"%w: the cluster-trust-bundle source requires a certificate and key file, a signer name, and Kubernetes client",
+				errMissingSVIDConfig,
+			)
+		}
+
+		return NewClusterTrustBundleCertFetcher(dynamicClient, config.SignerName, config.CertFile, config.KeyFile), nil
+	default:
+		return nil, fmt.Errorf("%w: '%s'", errUnknownSVIDSource, config.Type)
+	}
+}
+
+// newX509Context builds an X509Context from a PEM encoded certificate chain, private key, and trust bundle.
+// The trust bundle is for the trust domain of the SPIFFE ID of the certificate.
+func newX509Context(certPEM, keyPEM, bundlePEM []byte) (*workloadapi.X509Context, error) {
+	svid, err := x509svid.Parse(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing SVID: %w", err)
+	}
+	bundle, err := x509bundle.Parse(svid.ID.TrustDomain(), bundlePEM)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing trust bundle: %w", err)
+	}
+
+	return &workloadapi.X509Context{
+		SVIDs:   []*x509svid.SVID{svid},
+		Bundles: x509bundle.NewSet(bundle),
+	}, nil
+}
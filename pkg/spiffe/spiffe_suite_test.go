@@ -9,6 +9,8 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"math/big"
@@ -20,6 +22,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"google.golang.org/grpc/codes"
@@ -77,6 +80,25 @@ func makeSVIDResponse(cert, key, caBytes []byte) *workloadapi.X509Context {
 	}
 }
 
+// makeJWTSVID returns an unsigned JWT-SVID for the audience that expires at expiry.
+func makeJWTSVID(audience string, expiry time.Time) *jwtsvid.SVID {
+	header, err := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT"})
+	Expect(err).ToNot(HaveOccurred())
+	claims, err := json.Marshal(map[string]interface{}{
+		"sub": "spiffe://" + host + "/workload",
+		"aud": []string{audience},
+		"exp": expiry.Unix(),
+	})
+	Expect(err).ToNot(HaveOccurred())
+	enc := base64.RawURLEncoding
+	token := enc.EncodeToString(header) + "." + enc.EncodeToString(claims) + "." + enc.EncodeToString(make([]byte, 64))
+
+	svid, err := jwtsvid.ParseInsecure(token, []string{audience})
+	Expect(err).ToNot(HaveOccurred())
+
+	return svid
+}
+
 func stubContextUpdateCall(ctx context.Context, watcher workloadapi.X509ContextWatcher) error {
 	// call watcher one time then block on ctx.Done()
 	watcher.OnX509ContextUpdate(&workloadapi.X509Context{
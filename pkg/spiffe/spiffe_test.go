@@ -7,15 +7,34 @@ import (
 	"encoding/pem"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"software.sslmate.com/src/go-pkcs12"
 
 	sc "github.com/nginxinc/nginx-service-mesh/pkg/spiffe"
 	"github.com/nginxinc/nginx-service-mesh/pkg/spiffe/spiffefakes"
@@ -101,6 +120,11 @@ var _ = Describe("SpiffeCert", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("fake fetch error"))
 		})
+		It("stops the cert fetcher when the initial trust bundle times out", func() {
+			certManager = sc.NewCertManager(writer, certFetcher, 10*time.Millisecond)
+			Expect(certManager.Run(context.Background())).To(MatchError(sc.ErrCertTimeout))
+			Expect(certFetcher.StopCallCount()).To(Equal(1))
+		})
 		It("quits when context is canceled", func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			var err error
@@ -112,6 +136,153 @@ var _ = Describe("SpiffeCert", func() {
 			// check that certManager exits after context is canceled
 			Expect(err).Should(MatchError(context.Canceled))
 		})
+		Context("metrics", func() {
+			var metrics *sc.CertMetrics
+			JustBeforeEach(func() {
+				metrics = sc.NewCertMetrics(prometheus.Labels{"component": "test"})
+				certManager.SetMetrics(metrics)
+			})
+			It("registers the metrics", func() {
+				registry := prometheus.NewRegistry()
+				Expect(metrics.Register(registry)).To(Succeed())
+				Expect(metrics.Register(registry)).ToNot(Succeed())
+			})
+			It("records rotations and bundle changes", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				cert, key, ca := getTestCACertKey()
+				resp := makeSVIDResponse(cert, key, ca)
+				certCh <- resp
+				Expect(certManager.Run(ctx)).To(Succeed())
+				Expect(testutil.ToFloat64(metrics.LastRotation)).To(BeNumerically(">", 0))
+				notAfter := resp.DefaultSVID().Certificates[0].NotAfter
+				Expect(testutil.ToFloat64(metrics.NotAfter)).To(Equal(float64(notAfter.Unix())))
+				Expect(testutil.ToFloat64(metrics.BundleChanges)).To(BeZero())
+
+				certCh <- resp
+				Eventually(writer.WriteCallCount).Should(Equal(2))
+				cert, key, ca = getTestCACertKey()
+				certCh <- makeSVIDResponse(cert, key, ca)
+				Eventually(func() float64 { return testutil.ToFloat64(metrics.BundleChanges) }).Should(Equal(1.0))
+			})
+			It("records rotation and reload failures", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				reloader.ReloadReturns(errFakeWriteFail)
+				certCh <- &workloadapi.X509Context{}
+				go func() {
+					defer GinkgoRecover()
+					Expect(certManager.Run(ctx)).To(Succeed())
+				}()
+				Eventually(certManager.ErrCh).Should(Receive())
+				Expect(testutil.ToFloat64(metrics.ReloadFailures)).To(Equal(1.0))
+
+				writer.WriteReturns(errFakeWriteFail)
+				certCh <- &workloadapi.X509Context{}
+				Eventually(certManager.ErrCh).Should(Receive(MatchError(errFakeWriteFail)))
+				Expect(testutil.ToFloat64(metrics.RotationFailures)).To(Equal(1.0))
+			})
+		})
+		Context("supervision", func() {
+			var (
+				ctx    context.Context
+				cancel context.CancelFunc
+			)
+			BeforeEach(func() {
+				ctx, cancel = context.WithCancel(context.Background())
+				certCh <- &workloadapi.X509Context{} // initial trust bundle
+			})
+			JustBeforeEach(func() {
+				certManager.SetRetryConfig(sc.RetryConfig{
+					InitialBackoff:     10 * time.Millisecond,
+					MaxBackoff:         20 * time.Millisecond,
+					WriteRetryInterval: time.Millisecond,
+					WriteAttempts:      3,
+					MaxRestarts:        1,
+				})
+			})
+			AfterEach(func() {
+				cancel()
+			})
+			It("restarts the cert fetcher after a watch error", func() {
+				Expect(certManager.Run(ctx)).To(Succeed())
+				errCh <- errFakeWatchFail
+				Eventually(certFetcher.StartCallCount).Should(Equal(2))
+
+				certCh <- &workloadapi.X509Context{}
+				Eventually(writer.WriteCallCount).Should(Equal(2))
+				Consistently(certManager.ErrCh).ShouldNot(Receive())
+			})
+			It("retries when the cert fetcher fails to restart", func() {
+				certFetcher.StartReturnsOnCall(1, nil, nil, errFakeFetchFail)
+				certManager.SetRetryConfig(sc.RetryConfig{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+				Expect(certManager.Run(ctx)).To(Succeed())
+				errCh <- errFakeWatchFail
+				Eventually(certFetcher.StartCallCount).Should(Equal(3))
+				Consistently(certManager.ErrCh).ShouldNot(Receive())
+			})
+			It("resets the restarts after receiving certificates", func() {
+				Expect(certManager.Run(ctx)).To(Succeed())
+				errCh <- errFakeWatchFail
+				Eventually(certFetcher.StartCallCount).Should(Equal(2))
+				certCh <- &workloadapi.X509Context{}
+				Eventually(writer.WriteCallCount).Should(Equal(2))
+				errCh <- errFakeWatchFail
+				Eventually(certFetcher.StartCallCount).Should(Equal(3))
+				Consistently(certManager.ErrCh).ShouldNot(Receive())
+			})
+			It("gives up after the max consecutive restarts", func() {
+				Expect(certManager.Run(ctx)).To(Succeed())
+				errCh <- errFakeWatchFail
+				Eventually(certFetcher.StartCallCount).Should(Equal(2))
+				errCh <- errFakeWatchFail
+				Eventually(certManager.ErrCh).Should(Receive(MatchError(errFakeWatchFail)))
+				Consistently(certFetcher.StartCallCount).Should(Equal(2))
+			})
+			It("retries failed writes", func() {
+				writer.WriteReturnsOnCall(0, errFakeWriteFail)
+				Expect(certManager.Run(ctx)).To(Succeed())
+				Expect(writer.WriteCallCount()).To(Equal(2))
+
+				writer.WriteReturns(errFakeWriteFail)
+				certCh <- &workloadapi.X509Context{}
+				Eventually(certManager.ErrCh).Should(Receive(MatchError(errFakeWriteFail)))
+				Expect(writer.WriteCallCount()).To(Equal(5))
+			})
+			It("stops once the context is canceled", func() {
+				Expect(certManager.Run(ctx)).To(Succeed())
+				cancel()
+				Eventually(certManager.ErrCh).Should(Receive(MatchError(context.Canceled)))
+				Consistently(certManager.ErrCh).ShouldNot(Receive())
+			})
+			It("does not block on an unread ErrCh", func() {
+				reloader.ReloadReturns(errFakeWriteFail)
+				Expect(certManager.Run(ctx)).To(Succeed())
+				cancel()
+				// the cert fetcher's errors are no longer read once the CertManager stops
+				Eventually(errCh).ShouldNot(BeSent(errFakeWatchFail))
+				Consistently(errCh).ShouldNot(BeSent(errFakeWatchFail))
+				Expect(certManager.ErrCh).To(Receive(MatchError(errFakeWriteFail)))
+			})
+			It("does not restart the cert fetcher in a busy loop when MaxBackoff is zero", func() {
+				certManager.SetRetryConfig(sc.RetryConfig{InitialBackoff: 100 * time.Millisecond})
+				Expect(certManager.Run(ctx)).To(Succeed())
+				certFetcher.StartReturns(nil, nil, errFakeFetchFail)
+				errCh <- errFakeWatchFail
+				Consistently(certFetcher.StartCallCount, 250*time.Millisecond).Should(BeNumerically("<", 5))
+			})
+		})
+		It("alerts when the SVID is about to expire without renewal", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			// the test certificates are valid for a year
+			certManager.SetExpiryWindow(validFor + time.Hour)
+			cert, key, ca := getTestCACertKey()
+			certCh <- makeSVIDResponse(cert, key, ca)
+			Expect(certManager.Run(ctx)).To(Succeed())
+			Eventually(certManager.ErrCh).Should(Receive(MatchError(sc.ErrSVIDExpiring)))
+			Consistently(certManager.ErrCh, 100*time.Millisecond).ShouldNot(Receive())
+		})
 	})
 
 	Describe("Disk SVID Writer", func() {
@@ -208,6 +379,123 @@ var _ = Describe("SpiffeCert", func() {
 				Expect(privKey).To(Equal(testKey))
 			})
 		})
+		Context("atomically", func() {
+			var (
+				dir    string
+				writer *sc.DiskSVIDWriter
+			)
+			BeforeEach(func() {
+				var err error
+				dir, err = os.MkdirTemp("", "ssl")
+				Expect(err).ToNot(HaveOccurred())
+				writer, err = sc.NewDiskSVIDWriter(sc.DiskSVIDConfig{
+					CertDir:          dir,
+					KeyFilename:      "tls.key",
+					CertFilename:     "tls.crt",
+					CABundleFilename: "ca.crt",
+					Owner:            &sc.FileOwner{UID: os.Getuid(), GID: os.Getgid()},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			})
+
+			It("links the files to the current version", func() {
+				for i := 0; i < 2; i++ {
+					cert, key, ca := getTestCACertKey()
+					Expect(writer.Write(makeSVIDResponse(cert, key, ca))).To(Succeed())
+				}
+				target, err := os.Readlink(writer.CertFile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(target).To(Equal("..data/tls.crt"))
+
+				versions, err := filepath.Glob(path.Join(dir, "..svid_*"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(versions).To(HaveLen(1))
+
+				info, err := os.Stat(writer.KeyFile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+			})
+
+			It("lets other users traverse the version directory", func() {
+				cert, key, ca := getTestCACertKey()
+				Expect(writer.Write(makeSVIDResponse(cert, key, ca))).To(Succeed())
+
+				versions, err := filepath.Glob(path.Join(dir, "..svid_*"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(versions).To(HaveLen(1))
+				info, err := os.Stat(versions[0])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o755)))
+
+				info, err = os.Stat(writer.CertFile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o644)))
+			})
+
+			It("recovers from the files of an interrupted or non-atomic write", func() {
+				Expect(os.Symlink("..svid_missing", path.Join(dir, "..data_tmp"))).To(Succeed())
+				Expect(os.WriteFile(writer.CertFile, []byte("old cert"), 0o600)).To(Succeed())
+
+				cert, key, ca := getTestCACertKey()
+				Expect(writer.Write(makeSVIDResponse(cert, key, ca))).To(Succeed())
+				written, err := os.ReadFile(writer.CertFile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(written).To(Equal(cert))
+				Expect(path.Join(dir, "..data_tmp")).ToNot(BeAnExistingFile())
+			})
+
+			It("never exposes a certificate with the key of another version to readers", func() {
+				responses := make([]*workloadapi.X509Context, 2)
+				for i := range responses {
+					cert, key, ca := getTestCACertKey()
+					responses[i] = makeSVIDResponse(cert, key, ca)
+				}
+				Expect(writer.Write(responses[0])).To(Succeed())
+
+				done := make(chan struct{})
+				var wg sync.WaitGroup
+				var reads int64
+				for i := 0; i < 4; i++ {
+					wg.Add(1)
+					go func() {
+						defer GinkgoRecover()
+						defer wg.Done()
+						for {
+							select {
+							case <-done:
+								return
+							default:
+							}
+							// read the certificate and key through the same version, like a reload would
+							version, err := filepath.EvalSymlinks(path.Join(dir, "..data"))
+							if err != nil {
+								continue
+							}
+							cert, certErr := os.ReadFile(path.Join(version, "tls.crt"))
+							key, keyErr := os.ReadFile(path.Join(version, "tls.key"))
+							if os.IsNotExist(certErr) || os.IsNotExist(keyErr) {
+								// the version was replaced while reading
+								continue
+							}
+							Expect(certErr).ToNot(HaveOccurred())
+							Expect(keyErr).ToNot(HaveOccurred())
+							_, err = tls.X509KeyPair(cert, key)
+							Expect(err).ToNot(HaveOccurred())
+							atomic.AddInt64(&reads, 1)
+						}
+					}()
+				}
+				for i := 0; i < 50; i++ {
+					Expect(writer.Write(responses[i%2])).To(Succeed())
+				}
+				close(done)
+				wg.Wait()
+				Expect(atomic.LoadInt64(&reads)).To(BeNumerically(">", 0))
+			})
+		})
 	})
 
 	Describe("CertFetcher", func() {
@@ -235,6 +523,257 @@ var _ = Describe("SpiffeCert", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Eventually(errCh).Should(Receive())
 		})
+		It("cannot be restarted with a client that was passed in", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			client := &spiffefakes.FakeClient{}
+			client.WatchX509ContextReturns(errFakeWatchFail)
+			cf, err := sc.NewX509CertFetcher("spire-addr", client)
+			Expect(err).ToNot(HaveOccurred())
+			_, errCh, err := cf.Start(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(errCh).Should(Receive())
+			Eventually(client.CloseCallCount).Should(Equal(1))
+
+			_, _, err = cf.Start(ctx)
+			Expect(err).To(MatchError(sc.ErrNotRestartable))
+			Expect(client.WatchX509ContextCallCount()).To(Equal(1))
+		})
+		It("gives up without retrying when the fetcher cannot be restarted", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cert, key, caBytes := getTestCACertKey()
+			watchErr := make(chan error)
+			client := &spiffefakes.FakeClient{}
+			client.WatchX509ContextStub = func(ctx context.Context, watcher workloadapi.X509ContextWatcher) error {
+				watcher.OnX509ContextUpdate(makeSVIDResponse(cert, key, caBytes))
+
+				return <-watchErr
+			}
+			cf, err := sc.NewX509CertFetcher("spire-addr", client)
+			Expect(err).ToNot(HaveOccurred())
+			manager := sc.NewCertManager(&spiffefakes.FakeSVIDWriter{}, cf, time.Second)
+			manager.SetRetryConfig(sc.RetryConfig{InitialBackoff: time.Millisecond})
+			Expect(manager.Run(ctx)).To(Succeed())
+
+			watchErr <- errFakeWatchFail
+			Eventually(manager.ErrCh).Should(Receive(MatchError(sc.ErrNotRestartable)))
+			Expect(client.WatchX509ContextCallCount()).To(Equal(1))
+		})
+		It("can be stopped while it is started", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cf, err := sc.NewX509CertFetcher("spire-addr", &spiffefakes.FakeClient{})
+			Expect(err).ToNot(HaveOccurred())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				_, _, _ = cf.Start(ctx)
+			}()
+			Expect(cf.Stop()).To(Succeed())
+			Eventually(done).Should(BeClosed())
+		})
+	})
+
+	Describe("SVID sources", func() {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+		})
+		AfterEach(func() {
+			cancel()
+		})
+
+		expectSVID := func(certCh <-chan *workloadapi.X509Context, cert []byte) {
+			var certs *workloadapi.X509Context
+			Eventually(certCh).Should(Receive(&certs))
+			block, _ := pem.Decode(cert)
+			Expect(certs.DefaultSVID().Certificates[0].Raw).To(Equal(block.Bytes))
+			td := spiffeid.RequireTrustDomainFromString(host)
+			Expect(certs.Bundles.Has(td)).To(BeTrue())
+		}
+
+		It("selects the fetcher from the configuration", func() {
+			fetcher, err := sc.NewCertFetcher(sc.SVIDSourceConfig{SpireAddr: "/run/spire/sockets/agent.sock"}, nil, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetcher).To(BeAssignableToTypeOf(&sc.X509CertFetcher{}))
+
+			fetcher, err = sc.NewCertFetcher(sc.SVIDSourceConfig{
+				Type:            sc.SVIDSourceSecret,
+				SecretNamespace: "nginx-mesh",
+				SecretName:      "svid",
+			}, k8sfake.NewSimpleClientset(), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetcher).To(BeAssignableToTypeOf(&sc.SecretCertFetcher{}))
+
+			fetcher, err = sc.NewCertFetcher(sc.SVIDSourceConfig{
+				Type:         sc.SVIDSourceFiles,
+				CertFile:     "svid.pem",
+				KeyFile:      "svid_key.pem",
+				CABundleFile: "svid_bundle.pem",
+			}, nil, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetcher).To(BeAssignableToTypeOf(&sc.FileCertFetcher{}))
+
+			fetcher, err = sc.NewCertFetcher(sc.SVIDSourceConfig{
+				Type:       sc.SVIDSourceClusterTrustBundle,
+				CertFile:   "svid.pem",
+				KeyFile:    "svid_key.pem",
+				SignerName: "example.com/signer",
+			}, nil, dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetcher).To(BeAssignableToTypeOf(&sc.ClusterTrustBundleCertFetcher{}))
+
+			_, err = sc.NewCertFetcher(sc.SVIDSourceConfig{Type: sc.SVIDSourceSecret}, nil, nil)
+			Expect(err).To(HaveOccurred())
+			_, err = sc.NewCertFetcher(sc.SVIDSourceConfig{Type: "vault"}, nil, nil)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("fetches SVIDs from a Secret", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "nginx-mesh", Name: "svid"},
+				Type:       corev1.SecretTypeTLS,
+				Data: map[string][]byte{
+					corev1.TLSCertKey:       []byte(certPEM),
+					corev1.TLSPrivateKeyKey: []byte(privateKey),
+				},
+			}
+			clientset := k8sfake.NewSimpleClientset(secret)
+			fetcher := sc.NewSecretCertFetcher(clientset, "nginx-mesh", "svid")
+			certCh, errCh, err := fetcher.Start(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() { Expect(fetcher.Stop()).To(Succeed()) }()
+
+			// the Secret is skipped until it has a trust bundle
+			Consistently(certCh).ShouldNot(Receive())
+
+			secret.Data["ca.crt"] = []byte(rootPEM)
+			_, err = clientset.CoreV1().Secrets("nginx-mesh").Update(ctx, secret, metav1.UpdateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			expectSVID(certCh, []byte(certPEM))
+
+			newCert, newKey, newCA := getTestCACertKey()
+			secret.Data = map[string][]byte{
+				corev1.TLSCertKey:       newCert,
+				corev1.TLSPrivateKeyKey: newKey,
+				"ca.crt":                newCA,
+			}
+			_, err = clientset.CoreV1().Secrets("nginx-mesh").Update(ctx, secret, metav1.UpdateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			expectSVID(certCh, newCert)
+			Expect(errCh).ToNot(Receive())
+		})
+
+		It("fetches SVIDs from files and reloads them when they change", func() {
+			dir := GinkgoT().TempDir()
+			certFile := filepath.Join(dir, "svid.pem")
+			keyFile := filepath.Join(dir, "svid_key.pem")
+			bundleFile := filepath.Join(dir, "svid_bundle.pem")
+			Expect(os.WriteFile(certFile, []byte(certPEM), 0o600)).To(Succeed())
+			Expect(os.WriteFile(keyFile, []byte(privateKey), 0o600)).To(Succeed())
+
+			fetcher := sc.NewFileCertFetcher(certFile, keyFile, bundleFile)
+			certCh, errCh, err := fetcher.Start(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() { Expect(fetcher.Stop()).To(Succeed()) }()
+
+			// the files are skipped until the bundle is written
+			Consistently(certCh).ShouldNot(Receive())
+			Expect(os.WriteFile(bundleFile, []byte(rootPEM), 0o600)).To(Succeed())
+			expectSVID(certCh, []byte(certPEM))
+
+			// files replaced by a rename are reloaded
+			newCert, newKey, newCA := getTestCACertKey()
+			for name, data := range map[string][]byte{certFile: newCert, keyFile: newKey, bundleFile: newCA} {
+				Expect(os.WriteFile(name+".tmp", data, 0o600)).To(Succeed())
+				Expect(os.Rename(name+".tmp", name)).To(Succeed())
+			}
+			expectSVID(certCh, newCert)
+
+			// unchanged files are not sent again
+			Expect(os.WriteFile(filepath.Join(dir, "other"), []byte("data"), 0o600)).To(Succeed())
+			Consistently(certCh).ShouldNot(Receive())
+			Expect(errCh).ToNot(Receive())
+		})
+
+		It("fetches SVIDs using ClusterTrustBundles", func() {
+			dir := GinkgoT().TempDir()
+			certFile := filepath.Join(dir, "tls.crt")
+			keyFile := filepath.Join(dir, "tls.key")
+			Expect(os.WriteFile(certFile, []byte(certPEM), 0o600)).To(Succeed())
+			Expect(os.WriteFile(keyFile, []byte(privateKey), 0o600)).To(Succeed())
+
+			newCTB := func(name, signerName, trustBundle string) *unstructured.Unstructured {
+				ctb := &unstructured.Unstructured{Object: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"signerName":  signerName,
+						"trustBundle": trustBundle,
+					},
+				}}
+				ctb.SetAPIVersion("certificates.k8s.io/v1alpha1")
+				ctb.SetKind("ClusterTrustBundle")
+				ctb.SetName(name)
+
+				return ctb
+			}
+			_, _, otherCA := getTestCACertKey()
+			dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{sc.ClusterTrustBundleResource: "ClusterTrustBundleList"},
+				newCTB("other", "example.com/other", string(otherCA)),
+			)
+
+			fetcher := sc.NewClusterTrustBundleCertFetcher(dynamicClient, "example.com/signer", certFile, keyFile)
+			certCh, errCh, err := fetcher.Start(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() { Expect(fetcher.Stop()).To(Succeed()) }()
+
+			// the certificates are skipped until there is a ClusterTrustBundle for the signer
+			Consistently(certCh).ShouldNot(Receive())
+
+			_, err = dynamicClient.Resource(sc.ClusterTrustBundleResource).Create(ctx,
+				newCTB("signer", "example.com/signer", rootPEM), metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			var certs *workloadapi.X509Context
+			Eventually(certCh).Should(Receive(&certs))
+			bundle, err := certs.Bundles.GetX509BundleForTrustDomain(spiffeid.RequireTrustDomainFromString(host))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bundle.X509Authorities()).To(Equal(testCACert))
+
+			// a new ClusterTrustBundle for the signer is added to the bundle
+			_, err = dynamicClient.Resource(sc.ClusterTrustBundleResource).Create(ctx,
+				newCTB("signer-next", "example.com/signer", string(otherCA)), metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(certCh).Should(Receive(&certs))
+			bundle, err = certs.Bundles.GetX509BundleForTrustDomain(spiffeid.RequireTrustDomainFromString(host))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bundle.X509Authorities()).To(HaveLen(2))
+			Expect(errCh).ToNot(Receive())
+		})
+
+		It("stops the SVID sources while they are starting", func() {
+			dir := GinkgoT().TempDir()
+			fetchers := []sc.CertFetcher{
+				sc.NewSecretCertFetcher(k8sfake.NewSimpleClientset(), "nginx-mesh", "svid"),
+				sc.NewFileCertFetcher(filepath.Join(dir, "svid.pem"), filepath.Join(dir, "svid_key.pem"),
+					filepath.Join(dir, "svid_bundle.pem")),
+			}
+			for _, fetcher := range fetchers {
+				done := make(chan struct{})
+				go func(fetcher sc.CertFetcher) {
+					defer GinkgoRecover()
+					defer close(done)
+					_, _, err := fetcher.Start(ctx)
+					Expect(err).ToNot(HaveOccurred())
+				}(fetcher)
+				Expect(fetcher.Stop()).To(Succeed())
+				Eventually(done).Should(BeClosed())
+				Expect(fetcher.Stop()).To(Succeed())
+			}
+		})
 	})
 
 	Describe("SVID", func() {
@@ -267,6 +806,367 @@ var _ = Describe("SpiffeCert", func() {
 				Expect(handler.Write(resp)).To(Succeed())
 				Expect(handler.TestAndUpdateCABundle(caBytes)).To(Equal(false))
 			})
+
+			It("persists the SVID and keystores", func() {
+				dir := GinkgoT().TempDir()
+				handler := sc.CABundleManager{
+					CABundleFilepath: filepath.Join(dir, "ca.pem"),
+					CertFilepath:     filepath.Join(dir, "cert.pem"),
+					KeyFilepath:      filepath.Join(dir, "key.pem"),
+					Keystores: []sc.KeystoreConfig{
+						{
+							Format:             sc.KeystoreFormatPKCS12,
+							KeystoreFilepath:   filepath.Join(dir, "keystore.p12"),
+							TruststoreFilepath: filepath.Join(dir, "truststore.p12"),
+							Password:           "changeit",
+						},
+						{
+							Format:             sc.KeystoreFormatJKS,
+							KeystoreFilepath:   filepath.Join(dir, "keystore.jks"),
+							TruststoreFilepath: filepath.Join(dir, "truststore.jks"),
+							Password:           "changeit",
+						},
+					},
+					TaskQueue: taskqueue.NewTaskQueue(func(_ string, _ interface{}) error {
+						return nil
+					}),
+				}
+				Expect(handler.Write(resp)).To(Succeed())
+
+				Expect(os.ReadFile(filepath.Join(dir, "cert.pem"))).To(Equal(cert))
+				Expect(os.ReadFile(filepath.Join(dir, "key.pem"))).To(Equal(key))
+				info, err := os.Stat(filepath.Join(dir, "key.pem"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+
+				p12, err := os.ReadFile(filepath.Join(dir, "keystore.p12"))
+				Expect(err).ToNot(HaveOccurred())
+				_, p12Cert, err := pkcs12.Decode(p12, "changeit")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p12Cert.Raw).To(Equal(resp.DefaultSVID().Certificates[0].Raw))
+				p12Trust, err := os.ReadFile(filepath.Join(dir, "truststore.p12"))
+				Expect(err).ToNot(HaveOccurred())
+				trusted, err := pkcs12.DecodeTrustStore(p12Trust, "changeit")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(trusted).To(HaveLen(1))
+				caBlock, _ := pem.Decode(caBytes)
+				Expect(trusted[0].Raw).To(Equal(caBlock.Bytes))
+
+				jks, err := os.Open(filepath.Join(dir, "keystore.jks"))
+				Expect(err).ToNot(HaveOccurred())
+				defer jks.Close()
+				ks := keystore.New()
+				Expect(ks.Load(jks, []byte("changeit"))).To(Succeed())
+				chain, err := ks.GetPrivateKeyEntryCertificateChain("svid")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(chain[0].Content).To(Equal(resp.DefaultSVID().Certificates[0].Raw))
+				jksTrust, err := os.Open(filepath.Join(dir, "truststore.jks"))
+				Expect(err).ToNot(HaveOccurred())
+				defer jksTrust.Close()
+				ts := keystore.New()
+				Expect(ts.Load(jksTrust, []byte("changeit"))).To(Succeed())
+				Expect(ts.Aliases()).To(HaveLen(1))
+			})
+
+			It("calls the hooks when the CA bundle changes", func() {
+				dir := GinkgoT().TempDir()
+				handler := sc.CABundleManager{
+					CABundleFilepath: filepath.Join(dir, "ca.pem"),
+					TaskQueue: taskqueue.NewTaskQueue(func(_ string, _ interface{}) error {
+						return nil
+					}),
+				}
+				var bundles [][]byte
+				hookErr := errFakeWriteFail
+				handler.AddBundleChangeHook(func(caBundle []byte) error {
+					bundles = append(bundles, caBundle)
+
+					return nil
+				})
+				handler.AddBundleChangeHook(func([]byte) error {
+					return hookErr
+				})
+
+				// a failed hook is retried on the next write
+				Expect(handler.Write(resp)).To(MatchError(errFakeWriteFail))
+				hookErr = nil
+				Expect(handler.Write(resp)).To(Succeed())
+				Expect(bundles).To(Equal([][]byte{caBytes, caBytes}))
+
+				Expect(handler.Write(resp)).To(Succeed())
+				Expect(bundles).To(HaveLen(2))
+
+				newCert, newKey, newCA := getTestCACertKey()
+				Expect(handler.Write(makeSVIDResponse(newCert, newKey, newCA))).To(Succeed())
+				Expect(bundles).To(HaveLen(3))
+				Expect(bundles[2]).To(Equal(newCA))
+				Expect(os.ReadFile(filepath.Join(dir, "ca.pem"))).To(Equal(newCA))
+			})
+
+			It("writes the CA bundle on the next write if persisting the SVID fails", func() {
+				dir := GinkgoT().TempDir()
+				certDir := filepath.Join(dir, "certs")
+				handler := sc.CABundleManager{
+					CABundleFilepath: filepath.Join(dir, "ca.pem"),
+					CertFilepath:     filepath.Join(certDir, "cert.pem"),
+					TaskQueue: taskqueue.NewTaskQueue(func(_ string, _ interface{}) error {
+						return nil
+					}),
+				}
+				var bundles [][]byte
+				handler.AddBundleChangeHook(func(caBundle []byte) error {
+					bundles = append(bundles, caBundle)
+
+					return nil
+				})
+
+				// the certificate directory does not exist yet
+				Expect(handler.Write(resp)).ToNot(Succeed())
+				Expect(filepath.Join(dir, "ca.pem")).ToNot(BeAnExistingFile())
+				Expect(bundles).To(BeEmpty())
+
+				Expect(os.Mkdir(certDir, 0o755)).To(Succeed())
+				Expect(handler.Write(resp)).To(Succeed())
+				Expect(os.ReadFile(filepath.Join(dir, "ca.pem"))).To(Equal(caBytes))
+				Expect(bundles).To(Equal([][]byte{caBytes}))
+			})
+		})
+
+		Context("FederatedBundles", func() {
+			cert, key, caBytes := getTestCACertKey()
+			_, _, federatedCABytes := getTestCACertKey()
+
+			var resp *workloadapi.X509Context
+			BeforeEach(func() {
+				resp = makeSVIDResponse(cert, key, caBytes)
+				for _, name := range []string{"west.example.com", "east.example.com"} {
+					td, err := spiffeid.TrustDomainFromString(name)
+					Expect(err).ToNot(HaveOccurred())
+					bundle, err := x509bundle.Parse(td, federatedCABytes)
+					Expect(err).ToNot(HaveOccurred())
+					resp.Bundles.Add(bundle)
+				}
+			})
+
+			It("returns the bundles of the other trust domains", func() {
+				bundles := sc.FederatedBundles(resp)
+				Expect(bundles).To(HaveLen(2))
+				Expect(bundles[0].TrustDomain().String()).To(Equal("east.example.com"))
+				Expect(bundles[1].TrustDomain().String()).To(Equal("west.example.com"))
+			})
+
+			It("marshals the bundle of each trust domain and the combined bundle", func() {
+				bundles, combined, err := sc.MarshalBundles(resp)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(bundles).To(HaveLen(3))
+				Expect(bundles[spiffeid.RequireTrustDomainFromString("east.example.com")]).To(Equal(federatedCABytes))
+				Expect(bundles[spiffeid.RequireTrustDomainFromString(host)]).To(Equal(caBytes))
+
+				// sorted by trust domain: east.example.com, test.host.com, west.example.com
+				expected := append(append(append([]byte{}, federatedCABytes...), caBytes...), federatedCABytes...)
+				Expect(combined).To(Equal(expected))
+			})
+
+			It("writes a file for each trust domain with the DiskSVIDWriter", func() {
+				dir, err := os.MkdirTemp("", "bundles")
+				Expect(err).ToNot(HaveOccurred())
+				defer func() {
+					Expect(os.RemoveAll(dir)).To(Succeed())
+				}()
+				writer, err := sc.NewDiskSVIDWriter(sc.DiskSVIDConfig{
+					CertDir:                dir,
+					KeyFilename:            "tls.key",
+					CertFilename:           "tls.crt",
+					CABundleFilename:       "ca.crt",
+					CombinedBundleFilename: "bundle.pem",
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(writer.Write(resp)).To(Succeed())
+
+				Expect(writer.BundleDir).To(Equal(path.Join(dir, "bundles")))
+				for _, name := range []string{"east.example.com", "west.example.com", host} {
+					Expect(path.Join(writer.BundleDir, sc.BundleFilename(spiffeid.RequireTrustDomainFromString(name)))).To(BeAnExistingFile())
+				}
+				caBundle, err := os.ReadFile(writer.CaBundleFile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(caBundle).To(Equal(caBytes))
+				combined, err := os.ReadFile(writer.CombinedBundleFile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(combined).To(HaveLen(2*len(federatedCABytes) + len(caBytes)))
+
+				// a trust domain that is no longer federated is removed
+				resp.Bundles.Remove(spiffeid.RequireTrustDomainFromString("west.example.com"))
+				Expect(writer.Write(resp)).To(Succeed())
+				Expect(path.Join(writer.BundleDir, "west.example.com.pem")).ToNot(BeAnExistingFile())
+				Expect(path.Join(writer.BundleDir, "east.example.com.pem")).To(BeAnExistingFile())
+			})
+
+			It("does not overwrite the CA with the bundle of a trust domain of the same name", func() {
+				dir := GinkgoT().TempDir()
+				writer, err := sc.NewDiskSVIDWriter(sc.DiskSVIDConfig{
+					CertDir:          dir,
+					KeyFilename:      "key.pem",
+					CertFilename:     "cert.pem",
+					CABundleFilename: "ca.pem",
+				})
+				Expect(err).ToNot(HaveOccurred())
+				// the bundle of the trust domain "ca" is named ca.pem
+				bundle, err := x509bundle.Parse(spiffeid.RequireTrustDomainFromString("ca"), federatedCABytes)
+				Expect(err).ToNot(HaveOccurred())
+				resp.Bundles.Add(bundle)
+				Expect(writer.Write(resp)).To(Succeed())
+
+				Expect(os.ReadFile(writer.CaBundleFile)).To(Equal(caBytes))
+				Expect(os.ReadFile(path.Join(writer.BundleDir, "ca.pem"))).To(Equal(federatedCABytes))
+			})
+
+			It("replaces the bundles written next to the certificates with the bundles directory", func() {
+				dir := GinkgoT().TempDir()
+				config := sc.DiskSVIDConfig{
+					CertDir:          dir,
+					KeyFilename:      "tls.key",
+					CertFilename:     "tls.crt",
+					CABundleFilename: "ca.crt",
+				}
+				writer, err := sc.NewDiskSVIDWriter(config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(writer.Write(resp)).To(Succeed())
+				// a bundle written by a previous version, next to the certificates
+				Expect(os.Symlink("..data/east.example.com.pem", path.Join(dir, "east.example.com.pem"))).To(Succeed())
+
+				Expect(writer.Write(resp)).To(Succeed())
+				Expect(path.Join(dir, "east.example.com.pem")).ToNot(BeAnExistingFile())
+				target, err := os.Readlink(path.Join(dir, "bundles"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(target).To(Equal("..data/bundles"))
+				Expect(os.ReadFile(path.Join(writer.BundleDir, "east.example.com.pem"))).To(Equal(federatedCABytes))
+			})
+
+			It("writes the bundles that changed with the CABundleManager", func() {
+				dir, err := os.MkdirTemp("", "bundles")
+				Expect(err).ToNot(HaveOccurred())
+				defer func() {
+					Expect(os.RemoveAll(dir)).To(Succeed())
+				}()
+				handler := sc.CABundleManager{
+					CABundleFilepath:       path.Join(dir, "ca.pem"),
+					BundleDir:              dir,
+					CombinedBundleFilepath: path.Join(dir, "bundle.pem"),
+					TaskQueue: taskqueue.NewTaskQueue(func(_ string, _ interface{}) error {
+						return nil
+					}),
+				}
+				Expect(handler.Write(resp)).To(Succeed())
+				Expect(path.Join(dir, "east.example.com.pem")).To(BeAnExistingFile())
+				Expect(path.Join(dir, "west.example.com.pem")).To(BeAnExistingFile())
+				Expect(path.Join(dir, "bundle.pem")).To(BeAnExistingFile())
+
+				bundles, _, err := sc.MarshalBundles(resp)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(handler.TestAndUpdateBundles(bundles)).To(BeEmpty())
+
+				east := spiffeid.RequireTrustDomainFromString("east.example.com")
+				west := spiffeid.RequireTrustDomainFromString("west.example.com")
+				_, _, newCABytes := getTestCACertKey()
+				bundles[east] = newCABytes
+				delete(bundles, west)
+				Expect(handler.TestAndUpdateBundles(bundles)).To(Equal([]spiffeid.TrustDomain{east, west}))
+				Expect(handler.TestAndUpdateBundles(bundles)).To(BeEmpty())
+			})
+
+			It("writes the bundles with the owner of the CABundleManager", func() {
+				if os.Getuid() != 0 {
+					Skip("changing the owner of a file requires root")
+				}
+				dir := GinkgoT().TempDir()
+				handler := sc.CABundleManager{
+					Owner:            &sc.FileOwner{UID: 1234, GID: 5678},
+					CABundleFilepath: path.Join(dir, "ca.pem"),
+					BundleDir:        dir,
+					TaskQueue: taskqueue.NewTaskQueue(func(_ string, _ interface{}) error {
+						return nil
+					}),
+				}
+				Expect(handler.Write(resp)).To(Succeed())
+				for _, name := range []string{"ca.pem", "east.example.com.pem", "west.example.com.pem"} {
+					info, err := os.Stat(path.Join(dir, name))
+					Expect(err).ToNot(HaveOccurred())
+					stat, ok := info.Sys().(*syscall.Stat_t)
+					Expect(ok).To(BeTrue())
+					Expect(stat.Uid).To(BeEquivalentTo(1234), name)
+					Expect(stat.Gid).To(BeEquivalentTo(5678), name)
+				}
+			})
+
+			It("writes the bundles again after a failed write with the CABundleManager", func() {
+				dir, err := os.MkdirTemp("", "bundles")
+				Expect(err).ToNot(HaveOccurred())
+				defer func() {
+					Expect(os.RemoveAll(dir)).To(Succeed())
+				}()
+				bundleDir := path.Join(dir, "bundles")
+				handler := sc.CABundleManager{
+					CABundleFilepath: path.Join(dir, "ca.pem"),
+					BundleDir:        bundleDir,
+					TaskQueue: taskqueue.NewTaskQueue(func(_ string, _ interface{}) error {
+						return nil
+					}),
+				}
+				// the bundle directory does not exist yet
+				Expect(handler.Write(resp)).ToNot(Succeed())
+
+				Expect(os.Mkdir(bundleDir, 0o755)).To(Succeed())
+				Expect(handler.Write(resp)).To(Succeed())
+				Expect(path.Join(bundleDir, "east.example.com.pem")).To(BeAnExistingFile())
+				Expect(path.Join(bundleDir, "west.example.com.pem")).To(BeAnExistingFile())
+
+				// a trust domain whose bundle could not be removed is removed on the next write
+				Expect(os.Remove(path.Join(bundleDir, "west.example.com.pem"))).To(Succeed())
+				Expect(os.Mkdir(path.Join(bundleDir, "west.example.com.pem"), 0o755)).To(Succeed())
+				Expect(os.WriteFile(path.Join(bundleDir, "west.example.com.pem", "file"), nil, 0o600)).To(Succeed())
+				west := spiffeid.RequireTrustDomainFromString("west.example.com")
+				resp.Bundles.Remove(west)
+				Expect(handler.Write(resp)).ToNot(Succeed())
+
+				Expect(os.RemoveAll(path.Join(bundleDir, "west.example.com.pem"))).To(Succeed())
+				Expect(os.WriteFile(path.Join(bundleDir, "west.example.com.pem"), nil, 0o600)).To(Succeed())
+				Expect(handler.Write(resp)).To(Succeed())
+				Expect(path.Join(bundleDir, "west.example.com.pem")).ToNot(BeAnExistingFile())
+			})
+
+			It("writes the bundle of a federated trust domain from the X509Context of the workload", func() {
+				dir, err := os.MkdirTemp("", "bundles")
+				Expect(err).ToNot(HaveOccurred())
+				defer func() {
+					Expect(os.RemoveAll(dir)).To(Succeed())
+				}()
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				// SPIRE adds the bundles of the trust domains that the registration entry federates with
+				client := &spiffefakes.FakeClient{}
+				client.WatchX509ContextStub = func(ctx context.Context, watcher workloadapi.X509ContextWatcher) error {
+					watcher.OnX509ContextUpdate(resp)
+					<-ctx.Done()
+
+					return ctx.Err()
+				}
+				fetcher, err := sc.NewX509CertFetcher("spire-addr", client)
+				Expect(err).ToNot(HaveOccurred())
+				writer, err := sc.NewDiskSVIDWriter(sc.DiskSVIDConfig{
+					CertDir:          dir,
+					KeyFilename:      "tls.key",
+					CertFilename:     "tls.crt",
+					CABundleFilename: "ca.crt",
+				})
+				Expect(err).ToNot(HaveOccurred())
+				manager := sc.NewCertManager(writer, fetcher, time.Second)
+				Expect(manager.Run(ctx)).To(Succeed())
+
+				east := sc.BundleFilename(spiffeid.RequireTrustDomainFromString("east.example.com"))
+				Expect(os.ReadFile(path.Join(writer.BundleDir, east))).To(Equal(federatedCABytes))
+				Expect(os.ReadFile(writer.CaBundleFile)).To(Equal(caBytes))
+			})
 		})
 
 		Context("TestAndUpdateCABundle", func() {
@@ -354,4 +1254,218 @@ var _ = Describe("SpiffeCert", func() {
 			})
 		})
 	})
+
+	Describe("JWT-SVID", func() {
+		Describe("JWTSVIDFetcher", func() {
+			It("requires an audience", func() {
+				_, err := sc.NewJWTSVIDFetcher("spire-addr", &spiffefakes.FakeJWTClient{}, "")
+				Expect(err).To(HaveOccurred())
+			})
+			It("fetches a new JWT-SVID before the current one expires", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				client := &spiffefakes.FakeJWTClient{}
+				client.FetchJWTSVIDStub = func(_ context.Context, params jwtsvid.Params) (*jwtsvid.SVID, error) {
+					return makeJWTSVID(params.Audience, time.Now().Add(2*time.Second)), nil
+				}
+				fetcher, err := sc.NewJWTSVIDFetcher("spire-addr", client, "api", "extra")
+				Expect(err).ToNot(HaveOccurred())
+				svidCh, _, err := fetcher.Start(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				var svid *jwtsvid.SVID
+				Eventually(svidCh).Should(Receive(&svid))
+				Expect(svid.Audience).To(Equal([]string{"api"}))
+				Eventually(svidCh, 3*time.Second).Should(Receive())
+				Expect(client.FetchJWTSVIDCallCount()).To(Equal(2))
+				_, params := client.FetchJWTSVIDArgsForCall(0)
+				Expect(params).To(Equal(jwtsvid.Params{Audience: "api", ExtraAudiences: []string{"extra"}}))
+			})
+			It("retries on recoverable errors", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				client := &spiffefakes.FakeJWTClient{}
+				client.FetchJWTSVIDReturnsOnCall(0, nil, status.Error(codes.Unavailable, "agent unavailable"))
+				client.FetchJWTSVIDReturnsOnCall(1, makeJWTSVID("api", time.Now().Add(time.Hour)), nil)
+				fetcher, err := sc.NewJWTSVIDFetcher("spire-addr", client, "api")
+				Expect(err).ToNot(HaveOccurred())
+				fetcher.RetryInterval = 10 * time.Millisecond
+				svidCh, errCh, err := fetcher.Start(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(svidCh).Should(Receive())
+				Consistently(errCh).ShouldNot(Receive())
+			})
+			It("writes to error channel on fatal error", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				client := &spiffefakes.FakeJWTClient{}
+				client.FetchJWTSVIDReturns(nil, status.Error(codes.InvalidArgument, "invalid audience"))
+				fetcher, err := sc.NewJWTSVIDFetcher("spire-addr", client, "api")
+				Expect(err).ToNot(HaveOccurred())
+				_, errCh, err := fetcher.Start(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(errCh).Should(Receive())
+				Eventually(client.CloseCallCount).Should(Equal(1))
+			})
+			It("cannot be restarted with a client that was passed in", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				client := &spiffefakes.FakeJWTClient{}
+				client.FetchJWTSVIDReturns(nil, status.Error(codes.InvalidArgument, "invalid audience"))
+				fetcher, err := sc.NewJWTSVIDFetcher("spire-addr", client, "api")
+				Expect(err).ToNot(HaveOccurred())
+				_, errCh, err := fetcher.Start(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(errCh).Should(Receive())
+
+				_, _, err = fetcher.Start(ctx)
+				Expect(err).To(MatchError(sc.ErrNotRestartable))
+				Expect(client.FetchJWTSVIDCallCount()).To(Equal(1))
+			})
+			It("gives up without retrying when the fetcher cannot be restarted", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				client := &spiffefakes.FakeJWTClient{}
+				// the JWT-SVID is refreshed after a second
+				client.FetchJWTSVIDReturnsOnCall(0, makeJWTSVID("api", time.Now()), nil)
+				client.FetchJWTSVIDReturnsOnCall(1, nil, status.Error(codes.InvalidArgument, "invalid audience"))
+				fetcher, err := sc.NewJWTSVIDFetcher("spire-addr", client, "api")
+				Expect(err).ToNot(HaveOccurred())
+				manager := sc.NewJWTManager(&spiffefakes.FakeJWTSVIDWriter{}, fetcher, time.Second)
+				manager.SetRetryConfig(sc.RetryConfig{InitialBackoff: time.Millisecond})
+				Expect(manager.Run(ctx)).To(Succeed())
+
+				Eventually(manager.ErrCh, 3*time.Second).Should(Receive(MatchError(sc.ErrNotRestartable)))
+				Expect(client.FetchJWTSVIDCallCount()).To(Equal(2))
+			})
+			It("can be stopped while it is started", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				client := &spiffefakes.FakeJWTClient{}
+				client.FetchJWTSVIDStub = func(ctx context.Context, _ jwtsvid.Params) (*jwtsvid.SVID, error) {
+					<-ctx.Done()
+
+					return nil, ctx.Err()
+				}
+				fetcher, err := sc.NewJWTSVIDFetcher("spire-addr", client, "api")
+				Expect(err).ToNot(HaveOccurred())
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					_, _, _ = fetcher.Start(ctx)
+				}()
+				Expect(fetcher.Stop()).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+		})
+
+		It("writes the token to disk", func() {
+			dir, err := os.MkdirTemp("", "jwt")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			}()
+			_, err = sc.NewDiskJWTSVIDWriter(sc.DiskJWTSVIDConfig{TokenDir: "/tmp/jwt-does-not-exist"})
+			Expect(err).To(HaveOccurred())
+
+			writer, err := sc.NewDiskJWTSVIDWriter(sc.DiskJWTSVIDConfig{TokenDir: dir, TokenFilename: "token"})
+			Expect(err).ToNot(HaveOccurred())
+			svid := makeJWTSVID("api", time.Now().Add(time.Hour))
+			Expect(writer.Write(svid)).To(Succeed())
+			token, err := os.ReadFile(path.Join(dir, "token"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(token)).To(Equal(svid.Marshal()))
+		})
+
+		Describe("JWTManager", func() {
+			var (
+				reloader *spiffefakes.FakeReloader
+				writer   *spiffefakes.FakeJWTSVIDWriter
+				fetcher  *spiffefakes.FakeJWTFetcher
+				svidCh   chan *jwtsvid.SVID
+				errCh    chan error
+			)
+			BeforeEach(func() {
+				reloader = &spiffefakes.FakeReloader{}
+				writer = &spiffefakes.FakeJWTSVIDWriter{}
+				fetcher = &spiffefakes.FakeJWTFetcher{}
+				svidCh = make(chan *jwtsvid.SVID, 10)
+				errCh = make(chan error)
+				fetcher.StartReturns(svidCh, errCh, nil)
+			})
+			It("writes and reloads until stop signal is received", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				manager := sc.NewJWTManagerWithReloader(reloader, writer, fetcher, time.Minute)
+				svidCh <- makeJWTSVID("api", time.Now().Add(time.Hour))
+				Expect(manager.Run(ctx)).To(Succeed())
+				svidCh <- makeJWTSVID("api", time.Now().Add(time.Hour))
+				Eventually(writer.WriteCallCount).Should(Equal(2))
+				Eventually(reloader.ReloadCallCount).Should(Equal(2))
+				cancel()
+				Expect(<-manager.ErrCh).To(MatchError(context.Canceled))
+			})
+			It("times out waiting for the initial JWT-SVID and stops the fetcher", func() {
+				manager := sc.NewJWTManager(writer, fetcher, 10*time.Millisecond)
+				Expect(manager.Run(context.Background())).To(MatchError(sc.ErrJWTTimeout))
+				Expect(fetcher.StopCallCount()).To(Equal(1))
+			})
+			It("exits on write error", func() {
+				writer.WriteReturns(errFakeWriteFail)
+				manager := sc.NewJWTManager(writer, fetcher, time.Minute)
+				svidCh <- makeJWTSVID("api", time.Now().Add(time.Hour))
+				Expect(manager.Run(context.Background())).To(MatchError(ContainSubstring("fake write error")))
+				Expect(writer.WriteCallCount()).To(Equal(sc.DefaultRetryConfig.WriteAttempts))
+				Expect(fetcher.StopCallCount()).To(Equal(1))
+			})
+			Context("supervision", func() {
+				var (
+					ctx     context.Context
+					cancel  context.CancelFunc
+					manager *sc.JWTManager
+				)
+				BeforeEach(func() {
+					ctx, cancel = context.WithCancel(context.Background())
+					svidCh <- makeJWTSVID("api", time.Now().Add(time.Hour)) // initial JWT-SVID
+					manager = sc.NewJWTManager(writer, fetcher, time.Minute)
+					manager.SetRetryConfig(sc.RetryConfig{
+						InitialBackoff:     10 * time.Millisecond,
+						MaxBackoff:         20 * time.Millisecond,
+						WriteRetryInterval: time.Millisecond,
+						WriteAttempts:      3,
+						MaxRestarts:        1,
+					})
+				})
+				AfterEach(func() {
+					cancel()
+				})
+				It("restarts the fetcher after a fetch error", func() {
+					Expect(manager.Run(ctx)).To(Succeed())
+					errCh <- errFakeFetchFail
+					Eventually(fetcher.StartCallCount).Should(Equal(2))
+
+					svidCh <- makeJWTSVID("api", time.Now().Add(time.Hour))
+					Eventually(writer.WriteCallCount).Should(Equal(2))
+					Consistently(manager.ErrCh).ShouldNot(Receive())
+				})
+				It("gives up after the max consecutive restarts", func() {
+					Expect(manager.Run(ctx)).To(Succeed())
+					errCh <- errFakeFetchFail
+					Eventually(fetcher.StartCallCount).Should(Equal(2))
+					errCh <- errFakeFetchFail
+					Eventually(manager.ErrCh).Should(Receive(MatchError(errFakeFetchFail)))
+					Consistently(fetcher.StartCallCount).Should(Equal(2))
+				})
+				It("retries failed writes", func() {
+					writer.WriteReturnsOnCall(0, errFakeWriteFail)
+					Expect(manager.Run(ctx)).To(Succeed())
+					Expect(writer.WriteCallCount()).To(Equal(2))
+
+					writer.WriteReturns(errFakeWriteFail)
+					svidCh <- makeJWTSVID("api", time.Now().Add(time.Hour))
+					Eventually(manager.ErrCh).Should(Receive(MatchError(errFakeWriteFail)))
+					Expect(writer.WriteCallCount()).To(Equal(5))
+				})
+			})
+		})
+	})
 })
@@ -0,0 +1,185 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package spiffefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nginxinc/nginx-service-mesh/pkg/spiffe"
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
+)
+
+type FakeJWTClient struct {
+	CloseStub        func() error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+	}
+	closeReturns struct {
+		result1 error
+	}
+	closeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	FetchJWTSVIDStub        func(context.Context, jwtsvid.Params) (*jwtsvid.SVID, error)
+	fetchJWTSVIDMutex       sync.RWMutex
+	fetchJWTSVIDArgsForCall []struct {
+		arg1 context.Context
+		arg2 jwtsvid.Params
+	}
+	fetchJWTSVIDReturns struct {
+		result1 *jwtsvid.SVID
+		result2 error
+	}
+	fetchJWTSVIDReturnsOnCall map[int]struct {
+		result1 *jwtsvid.SVID
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeJWTClient) Close() error {
+	fake.closeMutex.Lock()
+	ret, specificReturn := fake.closeReturnsOnCall[len(fake.closeArgsForCall)]
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+	}{})
+	stub := fake.CloseStub
+	fakeReturns := fake.closeReturns
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeJWTClient) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeJWTClient) CloseCalls(stub func() error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = stub
+}
+
+func (fake *FakeJWTClient) CloseReturns(result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeJWTClient) CloseReturnsOnCall(i int, result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	if fake.closeReturnsOnCall == nil {
+		fake.closeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.closeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeJWTClient) FetchJWTSVID(arg1 context.Context, arg2 jwtsvid.Params) (*jwtsvid.SVID, error) {
+	fake.fetchJWTSVIDMutex.Lock()
+	ret, specificReturn := fake.fetchJWTSVIDReturnsOnCall[len(fake.fetchJWTSVIDArgsForCall)]
+	fake.fetchJWTSVIDArgsForCall = append(fake.fetchJWTSVIDArgsForCall, struct {
+		arg1 context.Context
+		arg2 jwtsvid.Params
+	}{arg1, arg2})
+	stub := fake.FetchJWTSVIDStub
+	fakeReturns := fake.fetchJWTSVIDReturns
+	fake.recordInvocation("FetchJWTSVID", []interface{}{arg1, arg2})
+	fake.fetchJWTSVIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeJWTClient) FetchJWTSVIDCallCount() int {
+	fake.fetchJWTSVIDMutex.RLock()
+	defer fake.fetchJWTSVIDMutex.RUnlock()
+	return len(fake.fetchJWTSVIDArgsForCall)
+}
+
+func (fake *FakeJWTClient) FetchJWTSVIDCalls(stub func(context.Context, jwtsvid.Params) (*jwtsvid.SVID, error)) {
+	fake.fetchJWTSVIDMutex.Lock()
+	defer fake.fetchJWTSVIDMutex.Unlock()
+	fake.FetchJWTSVIDStub = stub
+}
+
+func (fake *FakeJWTClient) FetchJWTSVIDArgsForCall(i int) (context.Context, jwtsvid.Params) {
+	fake.fetchJWTSVIDMutex.RLock()
+	defer fake.fetchJWTSVIDMutex.RUnlock()
+	argsForCall := fake.fetchJWTSVIDArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeJWTClient) FetchJWTSVIDReturns(result1 *jwtsvid.SVID, result2 error) {
+	fake.fetchJWTSVIDMutex.Lock()
+	defer fake.fetchJWTSVIDMutex.Unlock()
+	fake.FetchJWTSVIDStub = nil
+	fake.fetchJWTSVIDReturns = struct {
+		result1 *jwtsvid.SVID
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeJWTClient) FetchJWTSVIDReturnsOnCall(i int, result1 *jwtsvid.SVID, result2 error) {
+	fake.fetchJWTSVIDMutex.Lock()
+	defer fake.fetchJWTSVIDMutex.Unlock()
+	fake.FetchJWTSVIDStub = nil
+	if fake.fetchJWTSVIDReturnsOnCall == nil {
+		fake.fetchJWTSVIDReturnsOnCall = make(map[int]struct {
+			result1 *jwtsvid.SVID
+			result2 error
+		})
+	}
+	fake.fetchJWTSVIDReturnsOnCall[i] = struct {
+		result1 *jwtsvid.SVID
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeJWTClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	fake.fetchJWTSVIDMutex.RLock()
+	defer fake.fetchJWTSVIDMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeJWTClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ spiffe.JWTClient = new(FakeJWTClient)
@@ -0,0 +1,112 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package spiffefakes
+
+import (
+	"sync"
+
+	"github.com/nginxinc/nginx-service-mesh/pkg/spiffe"
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
+)
+
+type FakeJWTSVIDWriter struct {
+	WriteStub        func(*jwtsvid.SVID) error
+	writeMutex       sync.RWMutex
+	writeArgsForCall []struct {
+		arg1 *jwtsvid.SVID
+	}
+	writeReturns struct {
+		result1 error
+	}
+	writeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeJWTSVIDWriter) Write(arg1 *jwtsvid.SVID) error {
+	fake.writeMutex.Lock()
+	ret, specificReturn := fake.writeReturnsOnCall[len(fake.writeArgsForCall)]
+	fake.writeArgsForCall = append(fake.writeArgsForCall, struct {
+		arg1 *jwtsvid.SVID
+	}{arg1})
+	stub := fake.WriteStub
+	fakeReturns := fake.writeReturns
+	fake.recordInvocation("Write", []interface{}{arg1})
+	fake.writeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeJWTSVIDWriter) WriteCallCount() int {
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	return len(fake.writeArgsForCall)
+}
+
+func (fake *FakeJWTSVIDWriter) WriteCalls(stub func(*jwtsvid.SVID) error) {
+	fake.writeMutex.Lock()
+	defer fake.writeMutex.Unlock()
+	fake.WriteStub = stub
+}
+
+func (fake *FakeJWTSVIDWriter) WriteArgsForCall(i int) *jwtsvid.SVID {
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	argsForCall := fake.writeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeJWTSVIDWriter) WriteReturns(result1 error) {
+	fake.writeMutex.Lock()
+	defer fake.writeMutex.Unlock()
+	fake.WriteStub = nil
+	fake.writeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeJWTSVIDWriter) WriteReturnsOnCall(i int, result1 error) {
+	fake.writeMutex.Lock()
+	defer fake.writeMutex.Unlock()
+	fake.WriteStub = nil
+	if fake.writeReturnsOnCall == nil {
+		fake.writeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.writeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeJWTSVIDWriter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeJWTSVIDWriter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ spiffe.JWTSVIDWriter = new(FakeJWTSVIDWriter)
@@ -0,0 +1,184 @@
+// Package spiffe contains code related to spiffe identity management
+package spiffe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// withDefaults returns the config with the durations and WriteAttempts that are not positive replaced with those of
+// DefaultRetryConfig, and MaxBackoff raised to InitialBackoff, so that a fetcher is never restarted in a busy loop.
+func (r RetryConfig) withDefaults() RetryConfig {
+	if r.InitialBackoff <= 0 {
+		r.InitialBackoff = DefaultRetryConfig.InitialBackoff
+	}
+	if r.MaxBackoff < r.InitialBackoff {
+		r.MaxBackoff = r.InitialBackoff
+	}
+	if r.WriteRetryInterval <= 0 {
+		r.WriteRetryInterval = DefaultRetryConfig.WriteRetryInterval
+	}
+	if r.WriteAttempts <= 0 {
+		r.WriteAttempts = DefaultRetryConfig.WriteAttempts
+	}
+	if r.MaxRestarts < 0 {
+		r.MaxRestarts = 0
+	}
+
+	return r
+}
+
+// writeWithRetry calls write up to RetryConfig.WriteAttempts times, until it succeeds or the context is canceled.
+func writeWithRetry(ctx context.Context, retry RetryConfig, write func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = write(); err == nil {
+			return nil
+		}
+		if attempt >= retry.WriteAttempts {
+			return err
+		}
+		log.Printf("error writing SVID, attempt %d of %d: %v", attempt, retry.WriteAttempts, err)
+		select {
+		case <-time.After(retry.WriteRetryInterval):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// sendErr writes the error to the channel, unless the context is canceled first.
+func sendErr(ctx context.Context, errCh chan<- error, err error) {
+	select {
+	case errCh <- err:
+	case <-ctx.Done():
+	}
+}
+
+// fetcherSupervisor rotates the SVIDs of type T from a fetcher, and restarts the fetcher when it fails.
+type fetcherSupervisor[T any] struct {
+	// start starts the fetcher.
+	start func(context.Context) (<-chan T, <-chan error, error)
+	// rotate writes an SVID.
+	rotate func(context.Context, T) error
+	// failed is called for each error of the fetcher, if set.
+	failed func()
+	// expiryTimer returns a timer that fires when the current SVID is about to expire, or nil. Optional.
+	expiryTimer func() *time.Timer
+	// expired returns the error that is written to errCh when the expiry timer fires.
+	expired func() error
+	errCh   chan error
+	// name of the fetcher in logs and errors.
+	name  string
+	retry RetryConfig
+}
+
+// supervise rotates the SVIDs from the fetcher until the context is canceled.
+//
+// If the fetcher fails, it is restarted with exponential backoff. While waiting to restart,
+// the fetcher's streams are not read. The supervisor gives up and writes the error to errCh if the
+// fetcher fails more than RetryConfig.MaxRestarts consecutive times, if the fetcher cannot be restarted,
+// or if rotating an SVID fails.
+// When the context is canceled, its error is written to errCh if the channel has room, and the supervisor returns.
+func (s *fetcherSupervisor[T]) supervise(ctx context.Context, svidStream <-chan T, errStream <-chan error) {
+	var (
+		expiryTimer  = s.newExpiryTimer()
+		restartTimer *time.Timer
+		restarts     int
+		backoff      = s.retry.InitialBackoff
+	)
+	defer func() {
+		for _, timer := range []*time.Timer{expiryTimer, restartTimer} {
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+	}()
+
+	// fail handles an error of the fetcher. Returns false if the supervisor gives up.
+	fail := func(err error) bool {
+		if s.failed != nil {
+			s.failed()
+		}
+		restarts++
+		if s.retry.MaxRestarts > 0 && restarts > s.retry.MaxRestarts {
+			sendErr(ctx, s.errCh, fmt.Errorf("%s failed %d consecutive times: %w", s.name, restarts, err))
+
+			return false
+		}
+		log.Printf("%s failed, restarting in %s: %v", s.name, backoff, err)
+		svidStream, errStream = nil, nil
+		restartTimer = time.NewTimer(backoff)
+		backoff *= 2
+		if backoff > s.retry.MaxBackoff {
+			backoff = s.retry.MaxBackoff
+		}
+
+		return true
+	}
+
+	for {
+		var expiry, restart <-chan time.Time
+		if expiryTimer != nil {
+			expiry = expiryTimer.C
+		}
+		if restartTimer != nil {
+			restart = restartTimer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			// do not block if nobody reads the error
+			select {
+			case s.errCh <- ctx.Err():
+			default:
+			}
+
+			return
+		case err := <-errStream:
+			if !fail(err) {
+				return
+			}
+		case <-restart:
+			restartTimer = nil
+			var err error
+			if svidStream, errStream, err = s.start(ctx); err != nil {
+				if errors.Is(err, ErrNotRestartable) {
+					sendErr(ctx, s.errCh, fmt.Errorf("error restarting %s: %w", s.name, err))
+
+					return
+				}
+				if !fail(err) {
+					return
+				}
+			}
+		case svid := <-svidStream:
+			restarts = 0
+			backoff = s.retry.InitialBackoff
+			if expiryTimer != nil {
+				expiryTimer.Stop()
+			}
+			if err := s.rotate(ctx, svid); err != nil {
+				sendErr(ctx, s.errCh, err)
+
+				return
+			}
+			expiryTimer = s.newExpiryTimer()
+		case <-expiry:
+			// alert once per SVID
+			expiryTimer = nil
+			sendErr(ctx, s.errCh, s.expired())
+		}
+	}
+}
+
+func (s *fetcherSupervisor[T]) newExpiryTimer() *time.Timer {
+	if s.expiryTimer == nil {
+		return nil
+	}
+
+	return s.expiryTimer()
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sort"
 
 	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 )
 
@@ -14,12 +17,17 @@ const (
 	bundleFileMode = os.FileMode(0o644)
 	certsFileMode  = os.FileMode(0o644)
 	keyFileMode    = os.FileMode(0o600)
+	// bundlesDirName is the subdirectory of the cert directory that holds the bundle of each trust domain,
+	// so the name of a trust domain never collides with the name of the certificate, key, or CA file.
+	bundlesDirName = "bundles"
 )
 
 //go:generate counterfeiter -generate
 
 // DiskSVIDConfig contains the configuration for a Writer.
 type DiskSVIDConfig struct {
+	// Owner is the owner of the written files. If not set, the files are owned by the current user.
+	Owner *FileOwner
 	// CertDir is the directory that holds the certificates and key.
 	CertDir,
 	// KeyFilename is the name of the private key file.
@@ -27,7 +35,10 @@ type DiskSVIDConfig struct {
 	// CertFilename is the name of the certificate file
 	CertFilename,
 	// CABundleFilename is the name of the CA certificate file.
-	CABundleFilename string
+	CABundleFilename,
+	// CombinedBundleFilename is the name of the file that holds the bundles of all trust domains.
+	// If not set, the combined bundle is not written.
+	CombinedBundleFilename string
 }
 
 // SVIDWriter knows how extract and write certificates and keys from a SPIFFE X509-SVID.
@@ -39,10 +50,15 @@ type SVIDWriter interface {
 }
 
 // DiskSVIDWriter implements SVIDWriter interface.
+// The files are written atomically: readers see either all of the previous files or all of the new files.
 type DiskSVIDWriter struct {
+	writer *atomicWriter
 	KeyFile,
 	CertFile,
-	CaBundleFile string
+	CaBundleFile,
+	CombinedBundleFile,
+	// BundleDir is the directory that holds the bundle of each trust domain, the bundles subdirectory of the cert directory.
+	BundleDir string
 }
 
 // NewDiskSVIDWriter creates a new instance of Writer.
@@ -52,16 +68,23 @@ func NewDiskSVIDWriter(config DiskSVIDConfig) (*DiskSVIDWriter, error) {
 		return nil, err
 	}
 	writer := &DiskSVIDWriter{
+		writer:       &atomicWriter{dir: config.CertDir, owner: config.Owner},
 		KeyFile:      path.Join(config.CertDir, config.KeyFilename),
 		CertFile:     path.Join(config.CertDir, config.CertFilename),
 		CaBundleFile: path.Join(config.CertDir, config.CABundleFilename),
+		BundleDir:    path.Join(config.CertDir, bundlesDirName),
+	}
+	if config.CombinedBundleFilename != "" {
+		writer.CombinedBundleFile = path.Join(config.CertDir, config.CombinedBundleFilename)
 	}
 
 	return writer, nil
 }
 
 // Write parses the svidResponse into a private key, certificate, and CA.
-// The key, cert, and CA cert are written to disk.
+// The key, cert, and CA cert are written to disk, along with the bundle of each trust domain in the BundleDir
+// and, if configured, the combined bundle of all trust domains. The bundles of trust domains
+// that are no longer in the svidResponse are removed.
 func (d *DiskSVIDWriter) Write(svidResponse *workloadapi.X509Context) error {
 	svid := svidResponse.DefaultSVID()
 	caBundle, err := ParseCABundle(svidResponse)
@@ -78,16 +101,72 @@ func (d *DiskSVIDWriter) Write(svidResponse *workloadapi.X509Context) error {
 	if err != nil {
 		return fmt.Errorf("unable to marshal X.509 SVID: %w", err)
 	}
+	bundles, combined, err := MarshalBundles(svidResponse)
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string]atomicFile, len(bundles)+4) //nolint:gomnd // cert, key, CA, and combined bundle
+	for td, pemBundle := range bundles {
+		files[path.Join(bundlesDirName, BundleFilename(td))] = atomicFile{data: pemBundle, mode: bundleFileMode}
+	}
+	if d.CombinedBundleFile != "" {
+		files[path.Base(d.CombinedBundleFile)] = atomicFile{data: combined, mode: bundleFileMode}
+	}
+	files[path.Base(d.CertFile)] = atomicFile{data: pemCerts, mode: certsFileMode}
+	files[path.Base(d.CaBundleFile)] = atomicFile{data: pemBundle, mode: bundleFileMode}
+	files[path.Base(d.KeyFile)] = atomicFile{data: pemKey, mode: keyFileMode}
 
 	// Write out the files
-	if err := os.WriteFile(d.CertFile, pemCerts, certsFileMode); err != nil {
-		return fmt.Errorf("error writing certificate: %w", err)
+	if err := d.writer.Write(files); err != nil {
+		return fmt.Errorf("error writing certificates and key: %w", err)
 	}
-	if err := os.WriteFile(d.CaBundleFile, pemBundle, bundleFileMode); err != nil {
-		return fmt.Errorf("error writing CA certificate: %w", err)
+
+	return nil
+}
+
+// DiskJWTSVIDConfig contains the configuration for a DiskJWTSVIDWriter.
+type DiskJWTSVIDConfig struct {
+	// Owner is the owner of the token file. If not set, the file is owned by the current user.
+	Owner *FileOwner
+	// TokenDir is the directory that holds the token.
+	TokenDir,
+	// TokenFilename is the name of the token file.
+	TokenFilename string
+}
+
+// JWTSVIDWriter knows how to write a SPIFFE JWT-SVID.
+//
+//counterfeiter:generate ./. JWTSVIDWriter
+type JWTSVIDWriter interface {
+	// Write writes the token of a SPIFFE JWT-SVID
+	Write(svid *jwtsvid.SVID) error
+}
+
+// DiskJWTSVIDWriter implements JWTSVIDWriter interface.
+// The token file is replaced atomically.
+type DiskJWTSVIDWriter struct {
+	owner     *FileOwner
+	TokenFile string
+}
+
+// NewDiskJWTSVIDWriter creates a new instance of DiskJWTSVIDWriter.
+// Returns an error if the token directory does not exist.
+func NewDiskJWTSVIDWriter(config DiskJWTSVIDConfig) (*DiskJWTSVIDWriter, error) {
+	if _, err := os.Stat(config.TokenDir); err != nil && os.IsNotExist(err) {
+		return nil, err
 	}
-	if err := os.WriteFile(d.KeyFile, pemKey, keyFileMode); err != nil {
-		return fmt.Errorf("error writing private key: %w", err)
+
+	return &DiskJWTSVIDWriter{
+		owner:     config.Owner,
+		TokenFile: path.Join(config.TokenDir, config.TokenFilename),
+	}, nil
+}
+
+// Write writes the token of the JWT-SVID to disk.
+func (d *DiskJWTSVIDWriter) Write(svid *jwtsvid.SVID) error {
+	if err := writeFileAtomic(d.TokenFile, []byte(svid.Marshal()), keyFileMode, d.owner); err != nil {
+		return fmt.Errorf("error writing JWT-SVID: %w", err)
 	}
 
 	return nil
@@ -103,3 +182,67 @@ func ParseCABundle(svidResponse *workloadapi.X509Context) (*x509bundle.Bundle, e
 
 	return bundle, nil
 }
+
+// FederatedBundles returns the bundles of the trust domains that the trust domain
+// of the default SVID federates with, sorted by trust domain.
+func FederatedBundles(svidResponse *workloadapi.X509Context) []*x509bundle.Bundle {
+	trustDomain := svidResponse.DefaultSVID().ID.TrustDomain()
+	var bundles []*x509bundle.Bundle
+	for _, bundle := range sortedBundles(svidResponse) {
+		if bundle.TrustDomain() != trustDomain {
+			bundles = append(bundles, bundle)
+		}
+	}
+
+	return bundles
+}
+
+// MarshalBundles returns the PEM encoded bundle of each trust domain in the svidResponse,
+// and all of the bundles combined into one PEM, sorted by trust domain.
+func MarshalBundles(svidResponse *workloadapi.X509Context) (map[spiffeid.TrustDomain][]byte, []byte, error) {
+	bundles := make(map[spiffeid.TrustDomain][]byte)
+	var combined []byte
+	for _, bundle := range sortedBundles(svidResponse) {
+		pemBundle, err := bundle.Marshal()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to marshal X.509 bundle for trust domain '%s': %w", bundle.TrustDomain(), err)
+		}
+		bundles[bundle.TrustDomain()] = pemBundle
+		combined = append(combined, pemBundle...)
+	}
+
+	return bundles, combined, nil
+}
+
+// BundleFilename returns the name of the file that holds the bundle of a trust domain, i.e. example.org.pem.
+func BundleFilename(trustDomain spiffeid.TrustDomain) string {
+	return trustDomain.String() + ".pem"
+}
+
+func sortedBundles(svidResponse *workloadapi.X509Context) []*x509bundle.Bundle {
+	if svidResponse.Bundles == nil {
+		return nil
+	}
+	bundles := svidResponse.Bundles.Bundles()
+	sort.Slice(bundles, func(i, j int) bool {
+		return bundles[i].TrustDomain().String() < bundles[j].TrustDomain().String()
+	})
+
+	return bundles
+}
+
+func writeTrustDomainBundle(dir string, trustDomain spiffeid.TrustDomain, pemBundle []byte, owner *FileOwner) error {
+	if err := writeFileAtomic(path.Join(dir, BundleFilename(trustDomain)), pemBundle, bundleFileMode, owner); err != nil {
+		return fmt.Errorf("error writing bundle for trust domain '%s': %w", trustDomain, err)
+	}
+
+	return nil
+}
+
+func removeTrustDomainBundle(dir string, trustDomain spiffeid.TrustDomain) error {
+	if err := os.Remove(path.Join(dir, BundleFilename(trustDomain))); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing bundle for trust domain '%s': %w", trustDomain, err)
+	}
+
+	return nil
+}